@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"strconv"
 
+	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/ememorystore"
@@ -108,3 +109,11 @@ func (b *BlockSummary) GetMagicBlockMap() *MagicBlockMap {
 	}
 	return nil
 }
+
+/*VerifyConfirmation - verify the transaction confirmation proves the transaction and its receipt are included into this block */
+func (b *BlockSummary) VerifyConfirmation(c *transaction.Confirmation) error {
+	if c.Round != b.Round {
+		return common.NewError("invalid_confirmation", "confirmation is for other round")
+	}
+	return c.VerifyInclusion(b.Hash, b.MerkleTreeRoot, b.ReceiptMerkleTreeRoot)
+}
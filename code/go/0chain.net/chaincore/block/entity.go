@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -41,6 +40,9 @@ var (
 
 	//StateChangeSizeMetric - a metric that tracks how many state nodes are changing with each block
 	StateChangeSizeMetric metrics.Histogram
+
+	//stateHashRound - the round starting from which the state hash is a part of the block hash, set up once
+	stateHashRound int64
 )
 
 var (
@@ -306,6 +308,7 @@ func SetupEntity(store datastore.Store) {
 	blockEntityMetadata.IDColumnName = "hash"
 	datastore.RegisterEntityMetadata("block", blockEntityMetadata)
 	SetupBVTEntity()
+	stateHashRound = config.GetStateHashRound()
 }
 
 /*SetPreviousBlock - set the previous block of this block */
@@ -431,19 +434,37 @@ func (b *Block) GetMerkleTree() *util.MerkleTree {
 	return &mt
 }
 
-func (b *Block) getHashData() string {
-	mt := b.GetMerkleTree()
-	merkleRoot := mt.GetRoot()
-	rmt := b.GetReceiptsMerkleTree()
-	rMerkleRoot := rmt.GetRoot()
-	hashData := b.MinerID + ":" + b.PrevHash + ":" + common.TimeToString(b.CreationDate) + ":" + strconv.FormatInt(b.Round, 10) + ":" + strconv.FormatInt(b.GetRoundRandomSeed(), 10) + ":" + merkleRoot + ":" + rMerkleRoot
+/*GetHeader - get the header of the block, it's enough to compute the block hash */
+func (b *Block) GetHeader() *util.BlockHeader {
+	bh := &util.BlockHeader{
+		Hash:                  b.Hash,
+		MinerID:               b.MinerID,
+		PrevHash:              b.PrevHash,
+		CreationDate:          b.CreationDate,
+		Round:                 b.Round,
+		RoundRandomSeed:       b.GetRoundRandomSeed(),
+		MerkleTreeRoot:        b.GetMerkleTree().GetRoot(),
+		ReceiptMerkleTreeRoot: b.GetReceiptsMerkleTree().GetRoot(),
+		ClientStateHash:       b.ClientStateHash,
+		SignedStateHash:       b.isStateHashSigned(),
+	}
 	if b.MagicBlock != nil {
 		if b.MagicBlock.Hash == "" {
 			b.MagicBlock.Hash = b.MagicBlock.GetHash()
 		}
-		hashData += ":" + b.MagicBlock.Hash
+		bh.MagicBlockHash = b.MagicBlock.Hash
 	}
-	return hashData
+	return bh
+}
+
+/*isStateHashSigned - the state hash is a part of the block hash starting from the configured round,
+* so that verification tickets anchor the state of the block */
+func (b *Block) isStateHashSigned() bool {
+	return stateHashRound > 0 && b.Round >= stateHashRound
+}
+
+func (b *Block) getHashData() string {
+	return b.GetHeader().HashData()
 }
 
 /*ComputeHash - compute the hash of the block */
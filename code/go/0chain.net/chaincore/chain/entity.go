@@ -151,6 +151,7 @@ type Chain struct {
 	viewChanger                  ViewChanger
 	afterFetcher                 AfterFetcher
 	magicBlockSaver              MagicBlockSaver
+	finalizedBlockLoader         FinalizedBlockLoader
 
 	pruneStats *util.PruneStats
 
@@ -171,6 +172,8 @@ type Chain struct {
 	syncLFBStateC         chan *block.BlockSummary // sync MPT state for latest finalized round
 	// precise DKG phases tracking
 	phaseEvents chan PhaseEvent
+	// finalized blocks, transactions and SC events subscriptions
+	stream *blockStream
 }
 
 // SetBCStuckTimeThreshold sets the BC stuck time threshold
@@ -455,6 +458,7 @@ func Provider() datastore.Entity {
 	c.syncLFBStateC = make(chan *block.BlockSummary)
 
 	c.phaseEvents = make(chan PhaseEvent, 1) // at least 1 for buffer required
+	c.stream = newBlockStream()
 
 	return c
 }
@@ -283,6 +283,7 @@ func (c *Chain) finalizeBlock(ctx context.Context, fb *block.Block, bsh BlockSta
 	}
 	c.rebaseState(fb)
 	c.updateFeeStats(fb)
+	c.PublishFinalizedBlock(fb)
 
 	if fb.MagicBlock != nil {
 		c.UpdateMagicBlock(fb.MagicBlock)
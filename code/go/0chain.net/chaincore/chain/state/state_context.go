@@ -5,11 +5,16 @@ import (
 	"0chain.net/chaincore/config"
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/encryption"
 	"0chain.net/core/util"
 )
 
+//ErrInvalidNestedContext - the nested context is not created by the context
+var ErrInvalidNestedContext = common.NewError("invalid_nested_context",
+	"nested state context is not created by the context")
+
 var (
 	approvedMinters = []string{
 		"6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d9", // miner SC
@@ -51,6 +56,13 @@ type StateContextI interface {
 	GetSignatureScheme() encryption.SignatureScheme
 }
 
+//NestedStateContextI - a state context allowing a smart contract to call other
+//smart contracts on behalf of a client (a multi-sig wallet for example)
+type NestedStateContextI interface {
+	NestedStateContext(t *transaction.Transaction) StateContextI
+	CommitNested(nested StateContextI) error
+}
+
 //StateContext - a context object used to manipulate global state
 type StateContext struct {
 	block                         *block.Block
@@ -59,6 +71,7 @@ type StateContext struct {
 	transfers                     []*state.Transfer
 	signedTransfers               []*state.SignedTransfer
 	mints                         []*state.Mint
+	nested                        []*StateContext
 	clientStateDeserializer       state.DeserializerI
 	getSharders                   func(*block.Block) []string
 	getLastestFinalizedMagicBlock func() *block.Block
@@ -142,19 +155,77 @@ func (sc *StateContext) isApprovedMinter(m *state.Mint) bool {
 	return false
 }
 
+//NestedStateContext - create a state context of a smart contract call made
+//within the transaction on behalf of the client of the given transaction. The
+//nested context changes its own copy of the state; the changes, transfers and
+//mints are applied along with the ones of this context once the nested context
+//is committed, and are discarded otherwise. This context should not be changed
+//until the nested one is committed or discarded.
+func (sc *StateContext) NestedStateContext(t *transaction.Transaction) StateContextI {
+	ndb := util.NewLevelNodeDB(util.NewMemoryNodeDB(), sc.state.GetNodeDB(), false)
+	nstate := util.NewMerklePatriciaTrie(ndb, sc.state.GetVersion())
+	nstate.SetRoot(sc.state.GetRoot())
+	return NewStateContext(sc.block, nstate, sc.clientStateDeserializer, t,
+		sc.getSharders, sc.getLastestFinalizedMagicBlock,
+		sc.getChainCurrentMagicBlock, sc.getSignature)
+}
+
+//CommitNested - apply the state changes of the nested context to the state of
+//this one; the transfers and mints of the nested context are validated against
+//its transaction and applied along with the ones of this context
+func (sc *StateContext) CommitNested(nested StateContextI) error {
+	n, ok := nested.(*StateContext)
+	if !ok {
+		return ErrInvalidNestedContext
+	}
+	ndb, ok := n.state.GetNodeDB().(*util.LevelNodeDB)
+	if !ok || ndb.GetPrev() != sc.state.GetNodeDB() {
+		return ErrInvalidNestedContext
+	}
+	if _, ok = sc.state.GetNodeDB().(*util.LevelNodeDB); !ok {
+		return ErrInvalidNestedContext // can't merge the changes
+	}
+	if err := sc.state.MergeMPTChanges(n.state); err != nil {
+		return err
+	}
+	sc.nested = append(sc.nested, n)
+	return nil
+}
+
 //GetTransfers - get all the transfers
 func (sc *StateContext) GetTransfers() []*state.Transfer {
-	return sc.transfers
+	if len(sc.nested) == 0 {
+		return sc.transfers
+	}
+	transfers := append([]*state.Transfer{}, sc.transfers...)
+	for _, nested := range sc.nested {
+		transfers = append(transfers, nested.GetTransfers()...)
+	}
+	return transfers
 }
 
 //GetTransfers - get all the transfers
 func (sc *StateContext) GetSignedTransfers() []*state.SignedTransfer {
-	return sc.signedTransfers
+	if len(sc.nested) == 0 {
+		return sc.signedTransfers
+	}
+	signedTransfers := append([]*state.SignedTransfer{}, sc.signedTransfers...)
+	for _, nested := range sc.nested {
+		signedTransfers = append(signedTransfers, nested.GetSignedTransfers()...)
+	}
+	return signedTransfers
 }
 
 //GetMints - get all the mints and fight bad breath
 func (sc *StateContext) GetMints() []*state.Mint {
-	return sc.mints
+	if len(sc.nested) == 0 {
+		return sc.mints
+	}
+	mints := append([]*state.Mint{}, sc.mints...)
+	for _, nested := range sc.nested {
+		mints = append(mints, nested.GetMints()...)
+	}
+	return mints
 }
 
 //Validate - implement interface
@@ -190,6 +261,12 @@ func (sc *StateContext) Validate() error {
 		}
 	}
 
+	for _, nested := range sc.nested {
+		if err := nested.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
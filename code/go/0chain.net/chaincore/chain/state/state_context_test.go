@@ -0,0 +1,152 @@
+package state
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const storageSCAddress = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d7"
+
+func init() {
+	logging.InitLogging("testing")
+}
+
+func newTestStateContext(t *transaction.Transaction) *StateContext {
+	// the state of a transaction, see chain.CreateTxnMPT
+	db := util.NewLevelNodeDB(util.NewMemoryNodeDB(), util.NewMemoryNodeDB(), false)
+	mpt := util.NewMerklePatriciaTrie(db, 1)
+	return NewStateContext(&block.Block{}, mpt, &state.Deserializer{},
+		t, nil, nil, nil, nil)
+}
+
+func testValue(data string) util.Serializable {
+	return &util.SecureSerializableValue{Buffer: []byte(data)}
+}
+
+func requireTrieNode(t *testing.T, sc StateContextI, key, data string) {
+	val, err := sc.GetTrieNode(key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(data), val.Encode())
+}
+
+func TestStateContext_CommitNested(t *testing.T) {
+	var (
+		txn    = &transaction.Transaction{ClientID: "client", ToClientID: "msc", Value: 10}
+		ntxn   = &transaction.Transaction{ClientID: "wallet", ToClientID: storageSCAddress, Value: 5}
+		sc     = newTestStateContext(txn)
+		_, err = sc.InsertTrieNode("parent", testValue("parent"))
+	)
+	require.NoError(t, err)
+
+	nested := sc.NestedStateContext(ntxn)
+	requireTrieNode(t, nested, "parent", "parent")
+	_, err = nested.InsertTrieNode("nested", testValue("nested"))
+	require.NoError(t, err)
+	_, err = nested.DeleteTrieNode("parent")
+	require.NoError(t, err)
+	require.NoError(t, nested.AddTransfer(state.NewTransfer("wallet", "sc", 5)))
+	require.NoError(t, nested.AddMint(state.NewMint(storageSCAddress, "wallet", 1)))
+
+	// not visible until committed
+	_, err = sc.GetTrieNode("nested")
+	assert.Equal(t, util.ErrValueNotPresent, err)
+	requireTrieNode(t, sc, "parent", "parent")
+	assert.Len(t, sc.GetTransfers(), 0)
+	assert.Len(t, sc.GetMints(), 0)
+
+	require.NoError(t, sc.CommitNested(nested))
+	requireTrieNode(t, sc, "nested", "nested")
+	_, err = sc.GetTrieNode("parent")
+	assert.Equal(t, util.ErrValueNotPresent, err)
+	require.Len(t, sc.GetTransfers(), 1)
+	assert.Equal(t, "wallet", sc.GetTransfers()[0].ClientID)
+	require.Len(t, sc.GetMints(), 1)
+	assert.NoError(t, sc.Validate())
+
+	// nested context of another context
+	other := newTestStateContext(txn)
+	assert.Equal(t, ErrInvalidNestedContext,
+		sc.CommitNested(other.NestedStateContext(ntxn)))
+}
+
+func TestStateContext_RollbackNested(t *testing.T) {
+	var (
+		txn  = &transaction.Transaction{ClientID: "client", ToClientID: "msc", Value: 10}
+		ntxn = &transaction.Transaction{ClientID: "wallet", ToClientID: "sc", Value: 5}
+		sc   = newTestStateContext(txn)
+	)
+
+	// discarded nested context
+	nested := sc.NestedStateContext(ntxn)
+	_, err := nested.InsertTrieNode("discarded", testValue("discarded"))
+	require.NoError(t, err)
+	require.NoError(t, nested.AddTransfer(state.NewTransfer("wallet", "sc", 5)))
+
+	// the next one is committed
+	nested = sc.NestedStateContext(ntxn)
+	_, err = nested.InsertTrieNode("committed", testValue("committed"))
+	require.NoError(t, err)
+	require.NoError(t, sc.CommitNested(nested))
+
+	_, err = sc.GetTrieNode("discarded")
+	assert.Equal(t, util.ErrValueNotPresent, err)
+	requireTrieNode(t, sc, "committed", "committed")
+	assert.Len(t, sc.GetTransfers(), 0)
+	assert.NoError(t, sc.Validate())
+}
+
+func TestStateContext_ValidateNested(t *testing.T) {
+	var (
+		txn  = &transaction.Transaction{ClientID: "client", ToClientID: "msc", Value: 10}
+		ntxn = &transaction.Transaction{ClientID: "wallet", ToClientID: "sc", Value: 5}
+	)
+
+	// a nested transfer is validated against the nested transaction
+	sc := newTestStateContext(txn)
+	require.NoError(t, sc.AddTransfer(state.NewTransfer("client", "msc", 10)))
+	nested := sc.NestedStateContext(ntxn)
+	require.NoError(t, nested.AddTransfer(state.NewTransfer("wallet", "sc", 6)))
+	require.NoError(t, sc.CommitNested(nested))
+	assert.Equal(t, state.ErrInvalidTransfer, sc.Validate())
+
+	sc = newTestStateContext(txn)
+	nested = sc.NestedStateContext(ntxn)
+	require.NoError(t, nested.AddTransfer(state.NewTransfer("wallet", "sc", 3)))
+	require.NoError(t, nested.AddTransfer(state.NewTransfer("sc", "other", 100)))
+	require.NoError(t, sc.CommitNested(nested))
+	assert.NoError(t, sc.Validate())
+	assert.Len(t, sc.GetTransfers(), 2)
+
+	// the parent's client can't transfer on behalf of the nested one
+	assert.Equal(t, state.ErrInvalidTransfer,
+		nested.AddTransfer(state.NewTransfer("client", "sc", 1)))
+
+	// mints of a nested context are approved by its transaction
+	sc = newTestStateContext(txn)
+	nested = sc.NestedStateContext(ntxn)
+	assert.Equal(t, state.ErrInvalidMint,
+		nested.AddMint(state.NewMint(storageSCAddress, "wallet", 1)))
+	nested = sc.NestedStateContext(&transaction.Transaction{
+		ClientID: "wallet", ToClientID: storageSCAddress})
+	require.NoError(t, nested.AddMint(state.NewMint(storageSCAddress, "wallet", 1)))
+	require.NoError(t, sc.CommitNested(nested))
+	require.Len(t, sc.GetMints(), 1)
+	assert.NoError(t, sc.Validate())
+
+	// invalid signed transfer of a nested context
+	sc = newTestStateContext(txn)
+	nested = sc.NestedStateContext(ntxn)
+	nested.AddSignedTransfer(&state.SignedTransfer{
+		Transfer: *state.NewTransfer("wallet", "sc", 1)})
+	require.NoError(t, sc.CommitNested(nested))
+	assert.Len(t, sc.GetSignedTransfers(), 1)
+	assert.Error(t, sc.Validate())
+}
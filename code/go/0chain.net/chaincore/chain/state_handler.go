@@ -55,19 +55,19 @@ func (c *Chain) GetSCRestOutput(ctx context.Context, r *http.Request) (interface
 
 	scAddress := pathParams[1]
 	scRestPath := "/" + pathParams[2]
+	b, err := c.GetStateBlock(ctx, r)
+	if err != nil {
+		return nil, err
+	}
 	c.stateMutex.RLock()
 	defer c.stateMutex.RUnlock()
 
-	lfb := c.GetLatestFinalizedBlock()
-	if lfb == nil || lfb.ClientState == nil {
-		return nil, common.NewError("empty_lfb", "empty latest finalized block or state")
-	}
-	clientState := CreateTxnMPT(lfb.ClientState) // begin transaction
-	sctx := c.NewStateContext(lfb, clientState, &transaction.Transaction{})
+	clientState := CreateTxnMPT(b.ClientState) // begin transaction
+	sctx := c.NewStateContext(b, clientState, &transaction.Transaction{})
 	resp, err := smartcontract.ExecuteRestAPI(ctx, scAddress, scRestPath, r.URL.Query(), sctx)
 
 	if err != nil {
-		return nil, err
+		return nil, c.stateReadError(b, err)
 	}
 
 	return resp, nil
@@ -76,18 +76,16 @@ func (c *Chain) GetSCRestOutput(ctx context.Context, r *http.Request) (interface
 func (c *Chain) GetNodeFromSCState(ctx context.Context, r *http.Request) (interface{}, error) {
 	scAddress := r.FormValue("sc_address")
 	key := r.FormValue("key")
-	lfb := c.GetLatestFinalizedBlock()
-	if lfb == nil {
-		return nil, common.NewError("failed to get sc state", "finalized block doesn't exist")
-	}
-	if lfb.ClientState == nil {
-		return nil, common.NewError("failed to get sc state", "finalized block's state doesn't exist")
+	// the latest finalized block and its state are checked by GetStateBlock
+	b, err := c.GetStateBlock(ctx, r)
+	if err != nil {
+		return nil, err
 	}
 	c.stateMutex.RLock()
 	defer c.stateMutex.RUnlock()
-	node, err := lfb.ClientState.GetNodeValue(util.Path(encryption.Hash(scAddress + key)))
+	node, err := b.ClientState.GetNodeValue(util.Path(encryption.Hash(scAddress + key)))
 	if err != nil {
-		return nil, err
+		return nil, c.stateReadError(b, err)
 	}
 	if node == nil {
 		return nil, common.NewError("key_not_found", "key was not found")
@@ -103,14 +101,17 @@ func (c *Chain) GetNodeFromSCState(ctx context.Context, r *http.Request) (interf
 /*GetBalanceHandler - get the balance of a client */
 func (c *Chain) GetBalanceHandler(ctx context.Context, r *http.Request) (interface{}, error) {
 	clientID := r.FormValue("client_id")
-	lfb := c.GetLatestFinalizedBlock()
-	if lfb == nil {
+	if c.GetLatestFinalizedBlock() == nil {
 		return nil, common.ErrTemporaryFailure
 	}
-	state, err := c.GetState(lfb, clientID)
+	b, err := c.GetStateBlock(ctx, r)
 	if err != nil {
 		return nil, err
 	}
+	state, err := c.GetState(b, clientID)
+	if err != nil {
+		return nil, c.stateReadError(b, err)
+	}
 	state.ComputeProperties()
 	return state, nil
 }
@@ -0,0 +1,105 @@
+package chain
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	"0chain.net/core/util"
+)
+
+// ErrStatePruned is returned reading the state of a round that has been
+// pruned already.
+var ErrStatePruned = common.NewError("state_pruned",
+	"state of the round has been pruned")
+
+// getFinalizedBlockSummary returns the summary of the finalized block of the
+// round loaded by the finalized blocks loader if the node has one
+func (c *Chain) getFinalizedBlockSummary(ctx context.Context,
+	round int64) (*block.BlockSummary, error) {
+
+	if c.finalizedBlockLoader != nil {
+		bs, err := c.finalizedBlockLoader.LoadFinalizedBlockSummary(ctx, round)
+		if err == nil && bs != nil {
+			return bs, nil
+		}
+	}
+	return nil, common.NewError("round_not_available",
+		"block of the round is not available")
+}
+
+// GetStateBlock returns the latest finalized block, or a block with the
+// state of the finalized round given by the optional round parameter of
+// the request. It's the only lookup of the state block for REST handlers,
+// including the sharder state proof handler.
+func (c *Chain) GetStateBlock(ctx context.Context, r *http.Request) (
+	*block.Block, error) {
+
+	var round int64
+	if roundData := r.FormValue("round"); roundData != "" {
+		var err error
+		round, err = strconv.ParseInt(roundData, 10, 64)
+		if err != nil || round <= 0 {
+			return nil, common.InvalidRequest("invalid round number")
+		}
+	}
+	return c.GetStateBlockOfRound(ctx, round)
+}
+
+// GetStateBlockOfRound returns a block with the state of the finalized
+// round, or the latest finalized block for zero round; the state of the
+// round can be read while it's not pruned.
+func (c *Chain) GetStateBlockOfRound(ctx context.Context, round int64) (
+	*block.Block, error) {
+
+	lfb := c.GetLatestFinalizedBlock()
+	if lfb == nil || lfb.ClientState == nil {
+		return nil, common.NewError("empty_lfb",
+			"empty latest finalized block or state")
+	}
+	if round == 0 {
+		return lfb, nil
+	}
+	if round > lfb.Round {
+		return nil, common.NewError("round_not_finalized",
+			"round is not finalized yet")
+	}
+	// recent finalized blocks are in memory
+	for b := lfb; b != nil && b.Round >= round; b = b.PrevBlock {
+		if b.Round == round && b.ClientState != nil && b.IsStateComputed() {
+			return b, nil
+		}
+	}
+	if ps := c.GetPruneStats(); ps != nil && round < int64(ps.Version) {
+		return nil, ErrStatePruned
+	}
+	bs, err := c.getFinalizedBlockSummary(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	if len(bs.ClientStateHash) == 0 {
+		return nil, common.NewError("state_not_available",
+			"block of the round has no state hash")
+	}
+	b := block.NewBlock(c.GetKey(), round)
+	b.Hash = bs.Hash
+	b.MinerID = bs.MinerID
+	b.CreationDate = bs.CreationDate
+	b.SetRoundRandomSeed(bs.RoundRandomSeed)
+	b.ClientStateHash = bs.ClientStateHash
+	b.ClientState = util.NewMerklePatriciaTrie(c.stateDB, util.Sequence(round))
+	b.ClientState.SetRoot(bs.ClientStateHash)
+	b.SetStateStatus(block.StateSuccessful)
+	return b, nil
+}
+
+// stateReadError converts error reading state of a past round; missing
+// nodes of the state mean it has been pruned
+func (c *Chain) stateReadError(b *block.Block, err error) error {
+	if err == util.ErrNodeNotFound && b != c.GetLatestFinalizedBlock() {
+		return ErrStatePruned
+	}
+	return err
+}
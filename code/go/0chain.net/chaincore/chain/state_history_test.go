@@ -0,0 +1,101 @@
+package chain
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain_GetStateBlock(t *testing.T) {
+	var (
+		db     = util.NewMemoryNodeDB()
+		blocks = make(testBlockLoader)
+		prev   *block.Block
+
+		clientPath = util.Path(encryption.Hash("client"))
+	)
+	// balance of the client is the round number * 10
+	for round := int64(1); round <= 5; round++ {
+		mpt := util.NewMerklePatriciaTrie(
+			util.NewLevelNodeDB(util.NewMemoryNodeDB(), db, false),
+			util.Sequence(round))
+		if prev != nil {
+			mpt.SetRoot(prev.ClientStateHash)
+		}
+		st := &state.State{Balance: state.Balance(round * 10)}
+		st.SetTxnHash(encryption.Hash("txn"))
+		_, err := mpt.Insert(clientPath, st)
+		require.NoError(t, err)
+		require.NoError(t, mpt.SaveChanges(context.Background(), db, false))
+		b := block.NewBlock("", round)
+		b.Hash = "hash" + strconv.FormatInt(round, 10)
+		b.ClientStateHash = mpt.GetRoot()
+		b.ClientState = mpt
+		b.SetStateStatus(block.StateSuccessful)
+		blocks[round] = b
+		prev = b
+	}
+	lfb := blocks[5]
+	lfb.PrevBlock = blocks[4] // the only previous block in memory
+
+	c := &Chain{stateDB: db}
+	c.LatestFinalizedBlock = lfb
+
+	balance := func(b *block.Block) state.Balance {
+		ss, err := b.ClientState.GetNodeValue(clientPath)
+		require.NoError(t, err)
+		st := &state.State{}
+		require.NoError(t, st.Decode(ss.Encode()))
+		return st.Balance
+	}
+	get := func(round string) (*block.Block, error) {
+		r := httptest.NewRequest("GET", "/v1/client/get/balance?round="+round, nil)
+		return c.GetStateBlock(context.Background(), r)
+	}
+
+	b, err := c.GetStateBlock(context.Background(),
+		httptest.NewRequest("GET", "/v1/client/get/balance", nil))
+	require.NoError(t, err)
+	assert.True(t, b == lfb)
+
+	b, err = get("4")
+	require.NoError(t, err)
+	assert.True(t, b == blocks[4])
+
+	for _, round := range []string{"abc", "0", "6"} {
+		_, err = get(round)
+		assert.Error(t, err, round)
+	}
+
+	// not in memory and no loader
+	_, err = get("2")
+	assert.Error(t, err)
+
+	c.SetFinalizedBlockLoader(blocks)
+	b, err = get("2")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), b.Round)
+	assert.Equal(t, "hash2", b.Hash)
+	assert.Equal(t, state.Balance(20), balance(b))
+	assert.Equal(t, state.Balance(50), balance(lfb))
+
+	// pruned
+	c.pruneStats = &util.PruneStats{Version: 3}
+	_, err = get("2")
+	assert.Equal(t, ErrStatePruned, err)
+	b, err = get("3")
+	require.NoError(t, err)
+	assert.Equal(t, state.Balance(30), balance(b))
+
+	assert.Equal(t, ErrStatePruned, c.stateReadError(b, util.ErrNodeNotFound))
+	assert.Equal(t, util.ErrNodeNotFound, c.stateReadError(lfb,
+		util.ErrNodeNotFound))
+}
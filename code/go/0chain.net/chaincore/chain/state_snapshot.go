@@ -0,0 +1,189 @@
+package chain
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+	"go.uber.org/zap"
+)
+
+// NewStateSnapshotVerifier creates verifier of state snapshots signed by
+// the miners of the given magic block.
+func (c *Chain) NewStateSnapshotVerifier(
+	mb *block.MagicBlock) *util.StateProofVerifier {
+
+	miners := mb.Miners.CopyNodes()
+	v := &util.StateProofVerifier{
+		SignatureScheme: c.ClientSignatureScheme,
+		Miners:          make(map[string]string, len(miners)),
+		Threshold:       c.GetNotarizationThresholdCount(len(miners)),
+	}
+	for _, n := range miners {
+		v.Miners[n.GetKey()] = n.PublicKey
+	}
+	return v
+}
+
+// ExportBlockStateSnapshot writes snapshot of the state of the finalized
+// block. The state of the block should be in the state DB of the node.
+func (c *Chain) ExportBlockStateSnapshot(ctx context.Context, sb *block.Block,
+	w io.Writer) (int64, error) {
+
+	// the verification tickets sign the state only if the block hash
+	// covers the state hash
+	if !sb.GetHeader().SignedStateHash {
+		return 0, common.NewError("state_hash_not_signed",
+			"block hash doesn't cover the state hash, see state_hash_round")
+	}
+	// the block is required to sign the snapshot
+	var (
+		b   = sb
+		err error
+	)
+	if b.VerificationTicketsSize() == 0 {
+		if c.finalizedBlockLoader == nil {
+			return 0, common.NewError("block_not_available",
+				"block of the round is not available")
+		}
+		b, err = c.finalizedBlockLoader.LoadFinalizedBlock(ctx, sb.Round)
+		if err != nil {
+			return 0, err
+		}
+		if b.Hash != sb.Hash || b.VerificationTicketsSize() == 0 {
+			return 0, common.NewError("block_not_available",
+				"block of the round is not available")
+		}
+	}
+	header := &util.StateSnapshotHeader{Block: b.GetHeader()}
+	for _, vt := range b.GetVerificationTickets() {
+		header.Tickets = append(header.Tickets, &util.ProofTicket{
+			VerifierID: vt.VerifierID,
+			Signature:  vt.Signature,
+		})
+	}
+	// the state of a finalized round is saved already, export it from
+	// the state DB without locking the state of the chain
+	mpt := util.NewMerklePatriciaTrie(c.stateDB, util.Sequence(sb.Round))
+	mpt.SetRoot(sb.ClientStateHash)
+	return util.WriteStateSnapshot(ctx, w, mpt, header,
+		common.NewZStdCompDe())
+}
+
+// ExportStateSnapshotFile writes snapshot of the state of the finalized
+// block to the file.
+func (c *Chain) ExportStateSnapshotFile(ctx context.Context, b *block.Block,
+	file string) (err error) {
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(file)
+		}
+	}()
+	bw := bufio.NewWriter(f)
+	count, err := c.ExportBlockStateSnapshot(ctx, b, bw)
+	if err != nil {
+		return err
+	}
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+	logging.Logger.Info("export state snapshot", zap.String("file", file),
+		zap.Int64("round", b.Round), zap.String("block", b.Hash),
+		zap.Int64("nodes", count))
+	return f.Sync()
+}
+
+// ImportStateSnapshot imports state snapshot into the state DB of the node.
+// The snapshot block should be signed by the miners of the magic block of
+// its round and its state hash should match the nodes imported. The block
+// hash covers the state hash only starting from the state_hash_round, thus
+// snapshots of earlier rounds can't be imported.
+func (c *Chain) ImportStateSnapshot(ctx context.Context, r io.Reader) (
+	*util.StateSnapshotHeader, error) {
+
+	header, err := util.ReadStateSnapshotHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if !header.Block.SignedStateHash {
+		return nil, common.NewError("invalid_state_snapshot",
+			"block hash doesn't cover the state hash, see state_hash_round")
+	}
+	mb := c.GetMagicBlock(header.Block.Round)
+	err = c.NewStateSnapshotVerifier(mb).VerifyBlock(header.Block,
+		header.Tickets)
+	if err != nil {
+		return nil, common.NewError("invalid_state_snapshot", err.Error())
+	}
+	count, err := util.ReadStateSnapshotNodes(ctx, r, header, c.stateDB)
+	if err != nil {
+		return nil, err
+	}
+	logging.Logger.Info("import state snapshot",
+		zap.Int64("round", header.Block.Round),
+		zap.String("block", header.Block.Hash),
+		zap.String("state", util.ToHex(header.Block.ClientStateHash)),
+		zap.Int64("nodes", count))
+	return header, nil
+}
+
+// ImportStateSnapshotFile imports state snapshot from the file.
+func (c *Chain) ImportStateSnapshotFile(ctx context.Context, file string) (
+	*util.StateSnapshotHeader, error) {
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return c.ImportStateSnapshot(ctx, bufio.NewReader(f))
+}
+
+// StartFromStateSnapshot sets the block of an imported state snapshot as
+// the latest finalized block unless the node is at the round already; the
+// block is requested from sharders.
+func (c *Chain) StartFromStateSnapshot(ctx context.Context,
+	header *util.StateSnapshotHeader) error {
+
+	if lfb := c.GetLatestFinalizedBlock(); lfb != nil &&
+		lfb.Round >= header.Block.Round {
+		return nil
+	}
+
+	b, err := c.getFinalizedBlockFromSharders(ctx, &LFBTicket{
+		Round:   header.Block.Round,
+		LFBHash: header.Block.Hash,
+	})
+	if err != nil {
+		return err
+	}
+	if b == nil || !bytes.Equal(b.ClientStateHash, header.Block.ClientStateHash) {
+		return common.NewError("start_from_state_snapshot",
+			"can't get the block of the snapshot")
+	}
+	if err = b.InitStateDB(c.stateDB); err != nil {
+		return err
+	}
+	b.SetStateStatus(block.StateSuccessful)
+	lfmb := c.GetLatestFinalizedMagicBlock()
+	if lfmb == nil {
+		return common.NewError("start_from_state_snapshot",
+			"no latest finalized magic block")
+	}
+	c.AddLoadedFinalizedBlocks(b, lfmb)
+	return nil
+}
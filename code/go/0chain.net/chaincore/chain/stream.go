@@ -0,0 +1,319 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+)
+
+// stream event types
+const (
+	StreamEventBlock = "block"
+	StreamEventTxn   = "txn"
+	StreamEventSC    = "sc"
+)
+
+const (
+	// streamHistoryRounds is max number of last finalized rounds kept in
+	// memory to resume subscriptions from
+	streamHistoryRounds = 200
+	// streamHistorySize is max approximate size in bytes of the last
+	// finalized rounds kept in memory
+	streamHistorySize = 64 << 20
+	// streamTxnSize is approximate size of a transaction confirmation
+	// excluding the transaction data and output
+	streamTxnSize = 1 << 10
+	// streamQueueSize is number of finalized blocks waiting to be published
+	streamQueueSize = 64
+	// streamSubscriberBuffer is number of finalized rounds a subscriber
+	// can be behind; a slower subscriber is dropped and should resume
+	streamSubscriberBuffer = 32
+	// maxStreamSubscribers is max number of concurrent subscribers of a node
+	maxStreamSubscribers = 1000
+)
+
+var (
+	// ErrStreamRoundNotAvailable is returned by a stream resuming from a
+	// round that is neither kept in memory nor can be loaded.
+	ErrStreamRoundNotAvailable = errors.New("stream round not available")
+	// ErrTooManyStreamSubscribers is returned if the node can't serve more
+	// subscribers.
+	ErrTooManyStreamSubscribers = errors.New("too many stream subscribers")
+)
+
+// FinalizedBlockLoader loads finalized blocks of past rounds to replay them
+// to stream subscribers resuming from the round, and block summaries to read
+// the state of the rounds.
+type FinalizedBlockLoader interface {
+	LoadFinalizedBlock(ctx context.Context, round int64) (*block.Block, error)
+	LoadFinalizedBlockSummary(ctx context.Context, round int64) (
+		*block.BlockSummary, error)
+}
+
+// SetFinalizedBlockLoader sets loader of past finalized blocks.
+func (c *Chain) SetFinalizedBlockLoader(fbl FinalizedBlockLoader) {
+	c.finalizedBlockLoader = fbl
+}
+
+// SCEvent is a finalized smart contract call.
+type SCEvent struct {
+	Round        int64  `json:"round"`
+	BlockHash    string `json:"block_hash"`
+	TxnHash      string `json:"txn_hash"`
+	ClientID     string `json:"client_id"`
+	SCAddress    string `json:"sc_address"`
+	FunctionName string `json:"name"`
+	Status       int    `json:"transaction_status"`
+	Output       string `json:"output"`
+}
+
+// StreamRound is all stream events of a finalized round.
+type StreamRound struct {
+	Round    int64
+	Block    *block.BlockSummary
+	Txns     []*transaction.Confirmation
+	SCEvents []*SCEvent
+
+	size int // approximate size in bytes
+}
+
+// NewStreamRound creates stream events of the given finalized block; the
+// transaction confirmations include merkle paths of the transactions and
+// their receipts.
+func NewStreamRound(b *block.Block) *StreamRound {
+	sr := &StreamRound{Round: b.Round, Block: b.GetSummary()}
+	mt, rmt := b.GetMerkleTree(), b.GetReceiptsMerkleTree()
+	sr.Txns = make([]*transaction.Confirmation, 0, len(b.Txns))
+	for i, txn := range b.Txns {
+		c := transaction.TransactionConfirmationProvider().(*transaction.Confirmation)
+		c.Hash = txn.Hash
+		c.BlockHash = b.Hash
+		c.PreviousBlockHash = b.PrevHash
+		c.Transaction = txn
+		c.CreationDate = b.CreationDate
+		c.MinerID = b.MinerID
+		c.Round = b.Round
+		c.Status = txn.Status
+		c.RoundRandomSeed = b.GetRoundRandomSeed()
+		c.MerkleTreeRoot = mt.GetRoot()
+		c.MerkleTreePath = mt.GetPathByIndex(i)
+		c.ReceiptMerkleTreeRoot = rmt.GetRoot()
+		c.ReceiptMerkleTreePath = rmt.GetPathByIndex(i)
+		sr.Txns = append(sr.Txns, c)
+		sr.size += streamTxnSize + len(txn.TransactionData) +
+			len(txn.TransactionOutput)
+
+		if txn.TransactionType != transaction.TxnTypeSmartContract {
+			continue
+		}
+		var data struct {
+			FunctionName string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(txn.TransactionData), &data); err != nil {
+			continue
+		}
+		sr.SCEvents = append(sr.SCEvents, &SCEvent{
+			Round:        b.Round,
+			BlockHash:    b.Hash,
+			TxnHash:      txn.Hash,
+			ClientID:     txn.ClientID,
+			SCAddress:    txn.ToClientID,
+			FunctionName: data.FunctionName,
+			Status:       txn.Status,
+			Output:       txn.TransactionOutput,
+		})
+		sr.size += len(txn.TransactionOutput)
+	}
+	return sr
+}
+
+// StreamFilter selects stream events a subscriber is interested in.
+type StreamFilter struct {
+	Blocks    bool
+	Txns      bool
+	SCEvents  bool
+	ClientID  string // transactions from or to the client
+	SCAddress string // transactions and events of the smart contract
+}
+
+// MatchTxn returns true if the filter accepts the transaction.
+func (sf *StreamFilter) MatchTxn(txn *transaction.Transaction) bool {
+	if sf.ClientID != "" && txn.ClientID != sf.ClientID &&
+		txn.ToClientID != sf.ClientID {
+		return false
+	}
+	return sf.SCAddress == "" || txn.ToClientID == sf.SCAddress
+}
+
+// MatchSCEvent returns true if the filter accepts the smart contract event.
+func (sf *StreamFilter) MatchSCEvent(ev *SCEvent) bool {
+	if sf.ClientID != "" && ev.ClientID != sf.ClientID {
+		return false
+	}
+	return sf.SCAddress == "" || ev.SCAddress == sf.SCAddress
+}
+
+// StreamSubscriber receives finalized rounds; the channel is closed if the
+// subscriber is too slow and has been dropped.
+type StreamSubscriber struct {
+	C chan *StreamRound
+}
+
+// blockStream publishes finalized rounds to subscribers and keeps the
+// history of last rounds.
+type blockStream struct {
+	mutex   sync.Mutex
+	subs    map[*StreamSubscriber]struct{}
+	history []*StreamRound // last rounds ordered by round
+	size    int            // total size of the rounds in the history
+	last    int64          // last published round
+	queue   chan *block.Block
+}
+
+func newBlockStream() *blockStream {
+	bs := &blockStream{
+		subs:  make(map[*StreamSubscriber]struct{}),
+		queue: make(chan *block.Block, streamQueueSize),
+	}
+	go bs.run()
+	return bs
+}
+
+// run publishes the queued finalized blocks
+func (bs *blockStream) run() {
+	for b := range bs.queue {
+		if bs.hasSubscribers() {
+			bs.publish(NewStreamRound(b))
+		}
+	}
+}
+
+// enqueue the finalized block to publish it, never blocks; if the queue is
+// full, all subscribers are dropped, they should resume from the round
+// they missed
+func (bs *blockStream) enqueue(b *block.Block) {
+	select {
+	case bs.queue <- b:
+	default:
+		bs.mutex.Lock()
+		defer bs.mutex.Unlock()
+		for sub := range bs.subs {
+			delete(bs.subs, sub)
+			close(sub.C)
+		}
+	}
+}
+
+// publish the round to all subscribers, never blocks; a subscriber which
+// buffer is full is dropped
+func (bs *blockStream) publish(sr *StreamRound) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	bs.last = sr.Round
+	bs.history = append(bs.history, sr)
+	bs.size += sr.size
+	for len(bs.history) > streamHistoryRounds ||
+		(len(bs.history) > 1 && bs.size > streamHistorySize) {
+
+		bs.size -= bs.history[0].size
+		bs.history[0] = nil
+		bs.history = bs.history[1:]
+	}
+	for sub := range bs.subs {
+		select {
+		case sub.C <- sr:
+		default:
+			delete(bs.subs, sub)
+			close(sub.C)
+		}
+	}
+}
+
+func (bs *blockStream) hasSubscribers() bool {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	return len(bs.subs) > 0
+}
+
+func (bs *blockStream) subscribe() (*StreamSubscriber, error) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	if len(bs.subs) >= maxStreamSubscribers {
+		return nil, ErrTooManyStreamSubscribers
+	}
+	sub := &StreamSubscriber{C: make(chan *StreamRound, streamSubscriberBuffer)}
+	bs.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+func (bs *blockStream) unsubscribe(sub *StreamSubscriber) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	if _, ok := bs.subs[sub]; ok {
+		delete(bs.subs, sub)
+		close(sub.C)
+	}
+}
+
+// lastRound is the last published round; rounds finalized while there are
+// no subscribers are not published
+func (bs *blockStream) lastRound() int64 {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	return bs.last
+}
+
+// get the round from the history
+func (bs *blockStream) get(round int64) *StreamRound {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	i := sort.Search(len(bs.history), func(i int) bool {
+		return bs.history[i].Round >= round
+	})
+	if i == len(bs.history) || bs.history[i].Round != round {
+		return nil
+	}
+	return bs.history[i]
+}
+
+// PublishFinalizedBlock queues the finalized block to publish it to stream
+// subscribers asynchronously; nothing is done if there are no subscribers.
+func (c *Chain) PublishFinalizedBlock(b *block.Block) {
+	if c.stream == nil || !c.stream.hasSubscribers() {
+		return
+	}
+	c.stream.enqueue(b)
+}
+
+// SubscribeStream subscribes for finalized rounds.
+func (c *Chain) SubscribeStream() (*StreamSubscriber, error) {
+	return c.stream.subscribe()
+}
+
+// UnsubscribeStream unsubscribes from finalized rounds.
+func (c *Chain) UnsubscribeStream(sub *StreamSubscriber) {
+	c.stream.unsubscribe(sub)
+}
+
+// GetStreamRound returns events of a past finalized round to resume a
+// stream from, from the history kept in memory or loading the block.
+func (c *Chain) GetStreamRound(ctx context.Context, round int64) (
+	*StreamRound, error) {
+
+	if sr := c.stream.get(round); sr != nil {
+		return sr, nil
+	}
+	if c.finalizedBlockLoader == nil {
+		return nil, ErrStreamRoundNotAvailable
+	}
+	b, err := c.finalizedBlockLoader.LoadFinalizedBlock(ctx, round)
+	if err != nil || b == nil {
+		return nil, ErrStreamRoundNotAvailable
+	}
+	return NewStreamRound(b), nil
+}
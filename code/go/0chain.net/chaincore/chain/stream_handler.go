@@ -0,0 +1,241 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"0chain.net/core/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	// streamMaxDuration limits a stream connection to fit the write timeout
+	// of the server; clients reconnect resuming from the last event id
+	streamMaxDuration = 25 * time.Second
+	// streamKeepAlive is interval of keep alive comments of idle streams
+	streamKeepAlive = 10 * time.Second
+	// streamRetry is reconnection time suggested to clients, in milliseconds
+	streamRetry = 1000
+)
+
+// streamError is the last event of a stream that can't be continued, the
+// client should resume from the round
+type streamError struct {
+	Error       string `json:"error"`
+	ResumeRound int64  `json:"resume_round"`
+}
+
+func parseStreamFilter(r *http.Request) (*StreamFilter, error) {
+	sf := &StreamFilter{
+		ClientID:  r.FormValue("client_id"),
+		SCAddress: r.FormValue("sc_address"),
+	}
+	events := r.FormValue("events")
+	if events == "" {
+		sf.Blocks, sf.Txns, sf.SCEvents = true, true, true
+		return sf, nil
+	}
+	for _, event := range strings.Split(events, ",") {
+		switch strings.TrimSpace(event) {
+		case StreamEventBlock:
+			sf.Blocks = true
+		case StreamEventTxn:
+			sf.Txns = true
+		case StreamEventSC:
+			sf.SCEvents = true
+		default:
+			return nil, fmt.Errorf("unknown event type: %s", event)
+		}
+	}
+	return sf, nil
+}
+
+// parseStreamResumeRound returns the round the stream starts from, zero
+// for new rounds only; the Last-Event-ID header of a reconnecting client
+// takes precedence over the from_round parameter
+func parseStreamResumeRound(r *http.Request) (int64, error) {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		round, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || round < 0 {
+			return 0, fmt.Errorf("invalid Last-Event-ID: %s", id)
+		}
+		return round + 1, nil
+	}
+	fromRound := r.FormValue("from_round")
+	if fromRound == "" {
+		return 0, nil
+	}
+	round, err := strconv.ParseInt(fromRound, 10, 64)
+	if err != nil || round <= 0 {
+		return 0, fmt.Errorf("invalid from_round: %s", fromRound)
+	}
+	return round, nil
+}
+
+type streamWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	filter  *StreamFilter
+}
+
+func (sw *streamWriter) writeEvent(event string, data interface{}) error {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(sw.w, "event: %s\ndata: %s\n\n", event, buf)
+	return err
+}
+
+// writeRound writes the events of the round matching the filter, the round
+// is the id of the stream position
+func (sw *streamWriter) writeRound(sr *StreamRound) (err error) {
+	if sw.filter.Txns {
+		for _, c := range sr.Txns {
+			if !sw.filter.MatchTxn(c.Transaction) {
+				continue
+			}
+			if err = sw.writeEvent(StreamEventTxn, c); err != nil {
+				return
+			}
+		}
+	}
+	if sw.filter.SCEvents {
+		for _, ev := range sr.SCEvents {
+			if !sw.filter.MatchSCEvent(ev) {
+				continue
+			}
+			if err = sw.writeEvent(StreamEventSC, ev); err != nil {
+				return
+			}
+		}
+	}
+	if sw.filter.Blocks {
+		if err = sw.writeEvent(StreamEventBlock, sr.Block); err != nil {
+			return
+		}
+	}
+	if _, err = fmt.Fprintf(sw.w, "id: %d\n\n", sr.Round); err != nil {
+		return
+	}
+	sw.flusher.Flush()
+	return
+}
+
+func (sw *streamWriter) writeError(msg string, resumeRound int64) {
+	sw.writeEvent("error", &streamError{Error: msg, ResumeRound: resumeRound})
+	sw.flusher.Flush()
+}
+
+/*StreamHandler - streams finalized blocks, transaction confirmations and smart contract events as server-sent events */
+func StreamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	filter, err := parseStreamFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	next, err := parseStreamResumeRound(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c := GetServerChain()
+	sub, err := c.SubscribeStream()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer c.UnsubscribeStream(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	flusher.Flush()
+
+	var (
+		sw        = &streamWriter{w: w, flusher: flusher, filter: filter}
+		ctx       = r.Context()
+		keepAlive = time.NewTicker(streamKeepAlive)
+		done      = time.NewTimer(streamMaxDuration)
+	)
+	defer keepAlive.Stop()
+	defer done.Stop()
+
+	// replay the missed rounds up to the given one; a connection replays no
+	// more than the history rounds and stops at its max duration, keeping
+	// the rounds published meanwhile within the subscriber buffer; the
+	// client resumes from the last round sent
+	var replayed int
+	replay := func(to int64) bool {
+		for ; next > 0 && next <= to; next++ {
+			if replayed >= streamHistoryRounds {
+				return false
+			}
+			select {
+			case <-done.C:
+				return false
+			case <-ctx.Done():
+				return false
+			default:
+			}
+			replayed++
+			sr, err := c.GetStreamRound(ctx, next)
+			if err != nil {
+				sw.writeError(err.Error(), next)
+				return false
+			}
+			if err := sw.writeRound(sr); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+	if !replay(c.stream.lastRound()) {
+		return
+	}
+
+	for {
+		select {
+		case sr, ok := <-sub.C:
+			if !ok {
+				sw.writeError("client is too slow", next)
+				return
+			}
+			if next == 0 {
+				next = sr.Round
+			}
+			if !replay(sr.Round - 1) {
+				return
+			}
+			if sr.Round < next {
+				continue // already sent
+			}
+			if err := sw.writeRound(sr); err != nil {
+				logging.Logger.Debug("stream handler", zap.Error(err))
+				return
+			}
+			next = sr.Round + 1
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-done.C:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
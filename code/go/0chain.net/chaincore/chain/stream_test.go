@@ -0,0 +1,235 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/memorystore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBlockLoader map[int64]*block.Block
+
+func (tbl testBlockLoader) LoadFinalizedBlock(ctx context.Context,
+	round int64) (*block.Block, error) {
+
+	if b, ok := tbl[round]; ok {
+		return b, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (tbl testBlockLoader) LoadFinalizedBlockSummary(ctx context.Context,
+	round int64) (*block.BlockSummary, error) {
+
+	if b, ok := tbl[round]; ok {
+		return &block.BlockSummary{Hash: b.Hash, Round: b.Round,
+			ClientStateHash: b.ClientStateHash}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestBlockStream_publish(t *testing.T) {
+	bs := newBlockStream()
+	sub, err := bs.subscribe()
+	require.NoError(t, err)
+	slow, err := bs.subscribe()
+	require.NoError(t, err)
+
+	for i := 1; i <= streamSubscriberBuffer; i++ {
+		bs.publish(&StreamRound{Round: int64(i)})
+		assert.Equal(t, int64(i), (<-sub.C).Round)
+	}
+	assert.Len(t, slow.C, streamSubscriberBuffer)
+
+	// the slow subscriber is dropped, the other one keeps receiving
+	bs.publish(&StreamRound{Round: streamSubscriberBuffer + 1})
+	assert.Equal(t, int64(streamSubscriberBuffer+1), (<-sub.C).Round)
+	for range slow.C {
+	}
+	bs.unsubscribe(slow) // dropped already, no double close
+
+	bs.unsubscribe(sub)
+	_, ok := <-sub.C
+	assert.False(t, ok)
+	assert.Equal(t, int64(streamSubscriberBuffer+1), bs.lastRound())
+}
+
+func TestNewStreamRound_receiptPaths(t *testing.T) {
+	block.SetupBlockSummaryEntity(memorystore.GetStorageProvider())
+
+	b := block.NewBlock("", 10)
+	b.Hash = "block_hash"
+	for i := 0; i < 3; i++ {
+		txn := &transaction.Transaction{
+			ClientID:          "client",
+			ToClientID:        "to_client",
+			Value:             int64(i),
+			TransactionOutput: "same output",
+		}
+		txn.Hash = txn.ComputeHash()
+		txn.OutputHash = txn.ComputeOutputHash()
+		b.Txns = append(b.Txns, txn)
+	}
+
+	// receipts of equal outputs have equal hashes
+	sr := NewStreamRound(b)
+	require.Len(t, sr.Txns, 3)
+	mt, rmt := b.GetMerkleTree(), b.GetReceiptsMerkleTree()
+	for i, c := range sr.Txns {
+		assert.Equal(t, i, c.ReceiptMerkleTreePath.LeafIndex)
+		assert.NoError(t, c.VerifyInclusion(b.Hash, mt.GetRoot(),
+			rmt.GetRoot()))
+	}
+}
+
+func TestBlockStream_get(t *testing.T) {
+	bs := newBlockStream()
+	assert.Nil(t, bs.get(1))
+	assert.Zero(t, bs.lastRound())
+
+	last := int64(streamHistoryRounds + 50)
+	for i := int64(1); i <= last; i++ {
+		bs.publish(&StreamRound{Round: i})
+	}
+	assert.Nil(t, bs.get(last+1))
+	assert.Nil(t, bs.get(50))
+	for _, round := range []int64{51, 100, last - 1, last} {
+		sr := bs.get(round)
+		require.NotNil(t, sr)
+		assert.Equal(t, round, sr.Round)
+	}
+
+	c := &Chain{stream: bs}
+	_, err := c.GetStreamRound(context.Background(), 10)
+	assert.Equal(t, ErrStreamRoundNotAvailable, err)
+	c.SetFinalizedBlockLoader(testBlockLoader{})
+	_, err = c.GetStreamRound(context.Background(), 10)
+	assert.Equal(t, ErrStreamRoundNotAvailable, err)
+	sr, err := c.GetStreamRound(context.Background(), last)
+	require.NoError(t, err)
+	assert.Equal(t, last, sr.Round)
+}
+
+func TestBlockStream_historySize(t *testing.T) {
+	bs := newBlockStream()
+	size := streamHistorySize / 10
+	for i := int64(1); i <= 20; i++ {
+		bs.publish(&StreamRound{Round: i, size: size})
+	}
+	assert.Nil(t, bs.get(10))
+	for round := int64(11); round <= 20; round++ {
+		assert.NotNil(t, bs.get(round))
+	}
+	assert.Equal(t, 10*size, bs.size)
+
+	// a round bigger than the limit is kept alone
+	bs.publish(&StreamRound{Round: 21, size: 2 * streamHistorySize})
+	assert.Nil(t, bs.get(20))
+	assert.NotNil(t, bs.get(21))
+}
+
+func TestChain_PublishFinalizedBlock(t *testing.T) {
+	block.SetupBlockSummaryEntity(memorystore.GetStorageProvider())
+
+	c := &Chain{stream: newBlockStream()}
+	newBlock := func(round int64) *block.Block {
+		b := block.NewBlock("", round)
+		b.Hash = "block_hash"
+		return b
+	}
+
+	// no subscribers, nothing is published
+	c.PublishFinalizedBlock(newBlock(1))
+
+	sub, err := c.SubscribeStream()
+	require.NoError(t, err)
+	defer c.UnsubscribeStream(sub)
+	c.PublishFinalizedBlock(newBlock(2))
+	c.PublishFinalizedBlock(newBlock(3))
+
+	for _, round := range []int64{2, 3} {
+		select {
+		case sr := <-sub.C:
+			assert.Equal(t, round, sr.Round)
+		case <-time.After(5 * time.Second):
+			t.Fatal("round is not published")
+		}
+	}
+	assert.Nil(t, c.stream.get(1))
+	assert.Equal(t, int64(3), c.stream.lastRound())
+}
+
+func TestBlockStream_enqueue(t *testing.T) {
+	// a stream without the publishing worker
+	bs := &blockStream{
+		subs:  make(map[*StreamSubscriber]struct{}),
+		queue: make(chan *block.Block, streamQueueSize),
+	}
+	sub, err := bs.subscribe()
+	require.NoError(t, err)
+	for i := 0; i < streamQueueSize; i++ {
+		bs.enqueue(block.NewBlock("", int64(i+1)))
+	}
+	assert.True(t, bs.hasSubscribers())
+
+	// the queue is full, subscribers are dropped to resume
+	bs.enqueue(block.NewBlock("", streamQueueSize+1))
+	assert.False(t, bs.hasSubscribers())
+	_, ok := <-sub.C
+	assert.False(t, ok)
+}
+
+func TestStreamFilter(t *testing.T) {
+	txn := &transaction.Transaction{ClientID: "client", ToClientID: "sc"}
+	ev := &SCEvent{ClientID: "client", SCAddress: "sc"}
+
+	sf := &StreamFilter{}
+	assert.True(t, sf.MatchTxn(txn))
+	assert.True(t, sf.MatchSCEvent(ev))
+
+	sf = &StreamFilter{ClientID: "sc"}
+	assert.True(t, sf.MatchTxn(txn))
+	assert.False(t, sf.MatchSCEvent(ev))
+
+	sf = &StreamFilter{ClientID: "client", SCAddress: "other"}
+	assert.False(t, sf.MatchTxn(txn))
+	assert.False(t, sf.MatchSCEvent(ev))
+
+	sf = &StreamFilter{ClientID: "client", SCAddress: "sc"}
+	assert.True(t, sf.MatchTxn(txn))
+	assert.True(t, sf.MatchSCEvent(ev))
+}
+
+func TestParseStreamRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/v1/stream?events=block,sc&from_round=10", nil)
+	sf, err := parseStreamFilter(r)
+	require.NoError(t, err)
+	assert.True(t, sf.Blocks && sf.SCEvents && !sf.Txns)
+	round, err := parseStreamResumeRound(r)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), round)
+
+	// reconnecting client
+	r.Header.Set("Last-Event-ID", "20")
+	round, err = parseStreamResumeRound(r)
+	require.NoError(t, err)
+	assert.Equal(t, int64(21), round)
+
+	r = httptest.NewRequest("GET", "/v1/stream?events=blocks&from_round=-1", nil)
+	_, err = parseStreamFilter(r)
+	assert.Error(t, err)
+	_, err = parseStreamResumeRound(r)
+	assert.Error(t, err)
+
+	r = httptest.NewRequest("GET", "/v1/stream", nil)
+	sf, err = parseStreamFilter(r)
+	require.NoError(t, err)
+	assert.True(t, sf.Blocks && sf.SCEvents && sf.Txns)
+}
@@ -194,6 +194,11 @@ func GetThresholdCount() int {
 	return viper.GetInt("server_chain.block.consensus.threshold_by_count")
 }
 
+/*GetStateHashRound - the round starting from which the state hash is a part of the block hash, 0 - never */
+func GetStateHashRound() int64 {
+	return viper.GetInt64("server_chain.block.state_hash_round")
+}
+
 // LFB tickets.
 
 func GetReBroadcastLFBTicketTimeout() time.Duration {
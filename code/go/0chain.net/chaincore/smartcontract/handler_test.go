@@ -201,17 +201,17 @@ func TestGetSmartContract(t *testing.T) {
 		{
 			name:       "faucet",
 			address:    faucetsc.ADDRESS,
-			restpoints: 4,
+			restpoints: 5,
 		},
 		{
 			name:       "storage",
 			address:    storagesc.ADDRESS,
-			restpoints: 17,
+			restpoints: 21,
 		},
 		{
 			name:       "zrc20",
 			address:    zrc20sc.ADDRESS,
-			restpoints: 0,
+			restpoints: 1,
 		},
 		{
 			name:       "interest",
@@ -226,7 +226,7 @@ func TestGetSmartContract(t *testing.T) {
 		{
 			name:       "miner",
 			address:    minersc.ADDRESS,
-			restpoints: 13,
+			restpoints: 14,
 		},
 		{
 			name:       "vesting",
@@ -53,6 +53,10 @@ func (ps *PoolStats) Decode(input []byte) error {
 type DelegatePool struct {
 	*PoolStats                `json:"stats"`
 	*tokenpool.ZcnLockingPool `json:"pool"`
+	// AutoCompound restakes interests and rewards of the pool.
+	AutoCompound bool `json:"auto_compound,omitempty"`
+	// Compounded is total restaked interests and rewards.
+	Compounded state.Balance `json:"compounded,omitempty"`
 }
 
 func NewDelegatePool() *DelegatePool {
@@ -86,5 +90,19 @@ func (dp *DelegatePool) Decode(input []byte, tokenlock tokenpool.TokenLockInterf
 			return err
 		}
 	}
+	ac, ok := objMap["auto_compound"]
+	if ok {
+		err = json.Unmarshal(*ac, &dp.AutoCompound)
+		if err != nil {
+			return err
+		}
+	}
+	cd, ok := objMap["compounded"]
+	if ok {
+		err = json.Unmarshal(*cd, &dp.Compounded)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
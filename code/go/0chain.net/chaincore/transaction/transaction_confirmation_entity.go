@@ -3,6 +3,7 @@ package transaction
 import (
 	"context"
 
+	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/util"
 )
@@ -82,6 +83,32 @@ func (c *Confirmation) GetHashBytes() []byte {
 	return util.HashStringToBytes(c.Hash)
 }
 
+/*VerifyInclusion - verify the confirmation proves the transaction and its receipt are included into the block
+* with the given hash and merkle roots; the block hash and the roots should come from a trusted block summary */
+func (c *Confirmation) VerifyInclusion(blockHash, merkleTreeRoot, receiptMerkleTreeRoot string) error {
+	t := c.Transaction
+	if t == nil || c.MerkleTreePath == nil || c.ReceiptMerkleTreePath == nil {
+		return common.NewError("invalid_confirmation", "confirmation has no transaction or merkle paths")
+	}
+	if c.BlockHash != blockHash {
+		return common.NewError("invalid_confirmation", "confirmation is for other block")
+	}
+	if t.Hash != c.Hash || t.ComputeHash() != c.Hash {
+		return common.NewError("invalid_confirmation", "transaction doesn't match the confirmation hash")
+	}
+	if t.TransactionOutput != "" && t.OutputHash != t.ComputeOutputHash() {
+		return common.NewError("invalid_confirmation", "transaction output doesn't match the output hash")
+	}
+	if !util.VerifyMerklePath(c.Hash, c.MerkleTreePath, merkleTreeRoot) {
+		return common.NewError("invalid_confirmation", "transaction is not included into the block")
+	}
+	if c.ReceiptMerkleTreePath.LeafIndex != c.MerkleTreePath.LeafIndex ||
+		!util.VerifyMerklePath(t.OutputHash, c.ReceiptMerkleTreePath, receiptMerkleTreeRoot) {
+		return common.NewError("invalid_confirmation", "transaction receipt is not included into the block")
+	}
+	return nil
+}
+
 func TransactionConfirmationProvider() datastore.Entity {
 	t := &Confirmation{}
 	t.Version = "1.0"
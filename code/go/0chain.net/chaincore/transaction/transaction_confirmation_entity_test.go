@@ -0,0 +1,85 @@
+package transaction
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/common"
+	"0chain.net/core/util"
+)
+
+func newConfirmationTxns(n int) []*Transaction {
+	txns := make([]*Transaction, 0, n)
+	for i := 0; i < n; i++ {
+		t := &Transaction{
+			ClientID:          "client" + strconv.Itoa(i),
+			ToClientID:        "to_client",
+			Value:             int64(i * 10),
+			TransactionData:   "data",
+			CreationDate:      common.Timestamp(1000 + i),
+			TransactionOutput: "output" + strconv.Itoa(i),
+		}
+		t.Hash = t.ComputeHash()
+		t.OutputHash = t.ComputeOutputHash()
+		txns = append(txns, t)
+	}
+	return txns
+}
+
+func TestConfirmation_VerifyInclusion(t *testing.T) {
+	txns := newConfirmationTxns(7)
+	var txnHashables, receiptHashables []util.Hashable
+	for _, txn := range txns {
+		txnHashables = append(txnHashables, txn)
+		receiptHashables = append(receiptHashables, NewTransactionReceipt(txn))
+	}
+	var mt, rmt util.MerkleTree
+	mt.ComputeTree(txnHashables)
+	rmt.ComputeTree(receiptHashables)
+
+	txn := txns[3]
+	newConfirmation := func() *Confirmation {
+		return &Confirmation{
+			Hash:                  txn.Hash,
+			BlockHash:             "block_hash",
+			Transaction:           txn,
+			MerkleTreeRoot:        mt.GetRoot(),
+			MerkleTreePath:        mt.GetPath(txn),
+			ReceiptMerkleTreeRoot: rmt.GetRoot(),
+			ReceiptMerkleTreePath: rmt.GetPath(NewTransactionReceipt(txn)),
+		}
+	}
+
+	c := newConfirmation()
+	require.NoError(t, c.VerifyInclusion("block_hash", mt.GetRoot(), rmt.GetRoot()))
+
+	// other block
+	assert.Error(t, c.VerifyInclusion("other_hash", mt.GetRoot(), rmt.GetRoot()))
+	assert.Error(t, c.VerifyInclusion("block_hash", rmt.GetRoot(), rmt.GetRoot()))
+	assert.Error(t, c.VerifyInclusion("block_hash", mt.GetRoot(), mt.GetRoot()))
+
+	// no merkle paths
+	c.ReceiptMerkleTreePath = nil
+	assert.Error(t, c.VerifyInclusion("block_hash", mt.GetRoot(), rmt.GetRoot()))
+
+	// tampered transaction
+	c = newConfirmation()
+	tampered := newConfirmationTxns(4)[3]
+	tampered.Value = 1e6
+	c.Transaction = tampered
+	assert.Error(t, c.VerifyInclusion("block_hash", mt.GetRoot(), rmt.GetRoot()))
+
+	// tampered output
+	tampered = newConfirmationTxns(4)[3]
+	tampered.TransactionOutput = "other output"
+	c.Transaction = tampered
+	assert.Error(t, c.VerifyInclusion("block_hash", mt.GetRoot(), rmt.GetRoot()))
+
+	// receipt of other transaction
+	c = newConfirmation()
+	c.ReceiptMerkleTreePath = rmt.GetPath(NewTransactionReceipt(txns[4]))
+	assert.Error(t, c.VerifyInclusion("block_hash", mt.GetRoot(), rmt.GetRoot()))
+}
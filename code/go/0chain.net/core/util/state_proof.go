@@ -0,0 +1,192 @@
+package util
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+//ErrInvalidProof - indicates a state proof that doesn't prove the value
+var ErrInvalidProof = errors.New("invalid state proof")
+
+//BlockHeader - the part of a block required to compute the block hash without the transactions
+type BlockHeader struct {
+	Hash                  string           `json:"hash"`
+	MinerID               string           `json:"miner_id"`
+	PrevHash              string           `json:"prev_hash"`
+	CreationDate          common.Timestamp `json:"creation_date"`
+	Round                 int64            `json:"round"`
+	RoundRandomSeed       int64            `json:"round_random_seed"`
+	MerkleTreeRoot        string           `json:"merkle_tree_root"`
+	ReceiptMerkleTreeRoot string           `json:"receipt_merkle_tree_root"`
+	ClientStateHash       Key              `json:"state_hash"`
+	MagicBlockHash        string           `json:"magic_block_hash,omitempty"`
+	// SignedStateHash - the state hash is a part of the block hash, it's so
+	// for blocks starting from the state hash round of the chain
+	SignedStateHash bool `json:"signed_state_hash,omitempty"`
+}
+
+//HashData - the data the block hash is computed from
+func (bh *BlockHeader) HashData() string {
+	hashData := bh.MinerID + ":" + bh.PrevHash + ":" + common.TimeToString(bh.CreationDate) + ":" + strconv.FormatInt(bh.Round, 10) + ":" + strconv.FormatInt(bh.RoundRandomSeed, 10) + ":" + bh.MerkleTreeRoot + ":" + bh.ReceiptMerkleTreeRoot
+	if bh.SignedStateHash {
+		hashData += ":" + ToHex(bh.ClientStateHash)
+	}
+	if bh.MagicBlockHash != "" {
+		hashData += ":" + bh.MagicBlockHash
+	}
+	return hashData
+}
+
+//ComputeHash - compute the block hash
+func (bh *BlockHeader) ComputeHash() string {
+	return encryption.Hash(bh.HashData())
+}
+
+//ProofTicket - a verification ticket of a block, the block hash signed by a miner
+type ProofTicket struct {
+	VerifierID string `json:"verifier_id"`
+	Signature  string `json:"signature"`
+}
+
+/*StateProof - a proof of a value of the global state. The MPT nodes of the value path
+* are anchored by the state hash of the block and the block by its verification tickets */
+type StateProof struct {
+	Block   *BlockHeader   `json:"block"`
+	Tickets []*ProofTicket `json:"verification_tickets"`
+	Nodes   []string       `json:"nodes"` // hex encoded MPT nodes from the root to the leaf
+}
+
+//NewStateProof - create a proof of the value at the end of the given MPT path nodes
+func NewStateProof(bh *BlockHeader, tickets []*ProofTicket, nodes []Node) *StateProof {
+	sp := &StateProof{Block: bh, Tickets: tickets}
+	sp.Nodes = make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		sp.Nodes = append(sp.Nodes, hex.EncodeToString(node.Encode()))
+	}
+	return sp
+}
+
+//VerifyMPTPath - verify the path nodes lead from the root to the leaf of the path, returns the encoded value of the leaf
+func VerifyMPTPath(root Key, path Path, encodedNodes []string) ([]byte, error) {
+	for _, c := range path {
+		if bytes.IndexByte(PathElements, c) < 0 {
+			return nil, ErrInvalidProof
+		}
+	}
+	key := root
+	for i, encoded := range encodedNodes {
+		buf, err := hex.DecodeString(encoded)
+		if err != nil || len(buf) == 0 {
+			return nil, ErrInvalidProof
+		}
+		node, err := decodeProofNode(buf)
+		if err != nil {
+			return nil, ErrInvalidProof
+		}
+		if !bytes.Equal(node.GetHashBytes(), key) {
+			return nil, ErrInvalidProof
+		}
+		switch nodeImpl := node.(type) {
+		case *LeafNode:
+			if i != len(encodedNodes)-1 || !bytes.Equal(nodeImpl.Path, path) || !nodeImpl.HasValue() {
+				return nil, ErrInvalidProof
+			}
+			return nodeImpl.GetValue().Encode(), nil
+		case *FullNode:
+			if len(path) == 0 {
+				return nil, ErrInvalidProof
+			}
+			key = nodeImpl.GetChild(path[0])
+			path = path[1:]
+		case *ExtensionNode:
+			if len(nodeImpl.Path) == 0 || !bytes.HasPrefix(path, nodeImpl.Path) {
+				return nil, ErrInvalidProof
+			}
+			key = nodeImpl.NodeKey
+			path = path[len(nodeImpl.Path):]
+		default:
+			return nil, ErrInvalidProof
+		}
+		if key == nil {
+			return nil, ErrInvalidProof
+		}
+	}
+	return nil, ErrInvalidProof
+}
+
+//decodeProofNode - decode a path node of an untrusted proof, a malformed node is an error
+func decodeProofNode(buf []byte) (node Node, err error) {
+	switch buf[0] & NodeTypesAll {
+	case NodeTypeLeafNode, NodeTypeFullNode, NodeTypeExtensionNode:
+	default:
+		return nil, ErrInvalidEncoding
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			node, err = nil, ErrInvalidEncoding
+		}
+	}()
+	return CreateNode(bytes.NewReader(buf))
+}
+
+/*StateProofVerifier - verifies state proofs against the miners of a magic block a client trusts.
+* A block is accepted if it has valid verification tickets of at least Threshold distinct miners */
+type StateProofVerifier struct {
+	SignatureScheme string
+	Miners          map[string]string // miner id -> public key
+	Threshold       int
+}
+
+//VerifyBlock - verify the block hash and the verification tickets of the block; a block which hash doesn't cover the state hash is rejected
+func (v *StateProofVerifier) VerifyBlock(bh *BlockHeader, tickets []*ProofTicket) error {
+	if bh == nil || bh.Hash != bh.ComputeHash() {
+		return errors.New("invalid block hash")
+	}
+	if !bh.SignedStateHash || len(bh.ClientStateHash) == 0 {
+		return errors.New("block hash doesn't cover the state hash")
+	}
+	if v.Threshold <= 0 {
+		return errors.New("invalid verification threshold")
+	}
+	if !encryption.IsValidSignatureScheme(v.SignatureScheme) {
+		return errors.New("invalid signature scheme")
+	}
+	verified := make(map[string]bool, len(tickets))
+	for _, ticket := range tickets {
+		if ticket == nil || verified[ticket.VerifierID] {
+			continue
+		}
+		publicKey, ok := v.Miners[ticket.VerifierID]
+		if !ok {
+			continue
+		}
+		scheme := encryption.GetSignatureScheme(v.SignatureScheme)
+		if err := scheme.SetPublicKey(publicKey); err != nil {
+			continue
+		}
+		if ok, err := scheme.Verify(ticket.Signature, bh.Hash); err != nil || !ok {
+			continue
+		}
+		verified[ticket.VerifierID] = true
+	}
+	if len(verified) < v.Threshold {
+		return errors.New("not enough valid verification tickets")
+	}
+	return nil
+}
+
+//Verify - verify the state proof of the given path, returns the encoded value
+func (v *StateProofVerifier) Verify(path Path, sp *StateProof) ([]byte, error) {
+	if sp == nil {
+		return nil, ErrInvalidProof
+	}
+	if err := v.VerifyBlock(sp.Block, sp.Tickets); err != nil {
+		return nil, err
+	}
+	return VerifyMPTPath(sp.Block.ClientStateHash, path, sp.Nodes)
+}
@@ -0,0 +1,143 @@
+package util
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/encryption"
+)
+
+func newProofMPT(t *testing.T, n int) *MerklePatriciaTrie {
+	mpt := NewMerklePatriciaTrie(NewMemoryNodeDB(), Sequence(1))
+	for i := 0; i < n; i++ {
+		_, err := mpt.Insert(Path(encryption.Hash("key"+strconv.Itoa(i))),
+			&AState{balance: int64(i * 100)})
+		require.NoError(t, err)
+	}
+	return mpt
+}
+
+func TestVerifyMPTPath(t *testing.T) {
+	mpt := newProofMPT(t, 50)
+	path := Path(encryption.Hash("key7"))
+	nodes, err := mpt.GetPathNodes(path)
+	require.NoError(t, err)
+	proof := NewStateProof(nil, nil, nodes)
+
+	value, err := VerifyMPTPath(mpt.GetRoot(), path, proof.Nodes)
+	require.NoError(t, err)
+	assert.Equal(t, "700", string(value))
+
+	// other path
+	_, err = VerifyMPTPath(mpt.GetRoot(), Path(encryption.Hash("key8")),
+		proof.Nodes)
+	assert.Equal(t, ErrInvalidProof, err)
+
+	// other root
+	_, err = VerifyMPTPath(Key(encryption.RawHash("root")), path, proof.Nodes)
+	assert.Equal(t, ErrInvalidProof, err)
+
+	// tampered leaf
+	leaf := nodes[len(nodes)-1].Clone().(*LeafNode)
+	leaf.SetValue(&AState{balance: 1e6})
+	tampered := append([]string{}, proof.Nodes...)
+	tampered[len(tampered)-1] = NewStateProof(nil, nil,
+		[]Node{leaf}).Nodes[0]
+	_, err = VerifyMPTPath(mpt.GetRoot(), path, tampered)
+	assert.Equal(t, ErrInvalidProof, err)
+
+	// malformed and incomplete proofs
+	_, err = VerifyMPTPath(mpt.GetRoot(), path, []string{"ff00"})
+	assert.Equal(t, ErrInvalidProof, err)
+	_, err = VerifyMPTPath(mpt.GetRoot(), path, proof.Nodes[:len(nodes)-1])
+	assert.Equal(t, ErrInvalidProof, err)
+}
+
+func TestStateProofVerifier_Verify(t *testing.T) {
+	mpt := newProofMPT(t, 10)
+	path := Path(encryption.Hash("key3"))
+	nodes, err := mpt.GetPathNodes(path)
+	require.NoError(t, err)
+
+	bh := &BlockHeader{
+		MinerID:         "miner",
+		PrevHash:        "prev",
+		Round:           10,
+		ClientStateHash: mpt.GetRoot(),
+		SignedStateHash: true,
+	}
+	bh.Hash = bh.ComputeHash()
+
+	verifier := &StateProofVerifier{
+		SignatureScheme: "ed25519",
+		Miners:          make(map[string]string),
+		Threshold:       2,
+	}
+	var (
+		schemes  []encryption.SignatureScheme
+		tickets  []*ProofTicket
+		signHash = func(hash string) (tickets []*ProofTicket) {
+			for i, scheme := range schemes {
+				sig, err := scheme.Sign(hash)
+				require.NoError(t, err)
+				tickets = append(tickets, &ProofTicket{
+					VerifierID: "miner" + strconv.Itoa(i), Signature: sig})
+			}
+			return
+		}
+	)
+	for i := 0; i < 3; i++ {
+		scheme := encryption.NewED25519Scheme()
+		require.NoError(t, scheme.GenerateKeys())
+		verifier.Miners["miner"+strconv.Itoa(i)] = scheme.GetPublicKey()
+		schemes = append(schemes, scheme)
+	}
+	tickets = signHash(bh.Hash)
+
+	value, err := verifier.Verify(path, NewStateProof(bh, tickets, nodes))
+	require.NoError(t, err)
+	assert.Equal(t, "300", string(value))
+
+	// not enough tickets
+	_, err = verifier.Verify(path, NewStateProof(bh, tickets[:1], nodes))
+	assert.Error(t, err)
+	_, err = verifier.Verify(path, NewStateProof(bh,
+		[]*ProofTicket{tickets[0], tickets[0]}, nodes))
+	assert.Error(t, err)
+
+	// state hash not anchored by the tickets
+	other := *bh
+	other.ClientStateHash = Key(encryption.RawHash("other"))
+	_, err = verifier.Verify(path, NewStateProof(&other, tickets, nodes))
+	assert.Error(t, err)
+
+	// the hash of the block doesn't cover the state hash
+	other = *bh
+	other.SignedStateHash = false
+	other.Hash = other.ComputeHash()
+	_, err = verifier.Verify(path, NewStateProof(&other, signHash(other.Hash), nodes))
+	assert.Error(t, err)
+}
+
+func TestBlockHeader_HashData(t *testing.T) {
+	bh := &BlockHeader{
+		MinerID:               "miner",
+		PrevHash:              "prev",
+		CreationDate:          100,
+		Round:                 10,
+		RoundRandomSeed:       12345,
+		MerkleTreeRoot:        "txns",
+		ReceiptMerkleTreeRoot: "receipts",
+		ClientStateHash:       Key(encryption.RawHash("state")),
+	}
+	// the state hash isn't a part of the block hash until it's signed
+	assert.Equal(t, "miner:prev:100:10:12345:txns:receipts", bh.HashData())
+	bh.MagicBlockHash = "mb"
+	assert.Equal(t, "miner:prev:100:10:12345:txns:receipts:mb", bh.HashData())
+	bh.SignedStateHash = true
+	assert.Equal(t, "miner:prev:100:10:12345:txns:receipts:"+
+		ToHex(bh.ClientStateHash)+":mb", bh.HashData())
+}
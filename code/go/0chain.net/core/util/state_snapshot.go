@@ -0,0 +1,309 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+/*
+ * A state snapshot file is
+ *   magic, 4 bytes header length, JSON encoded header
+ *   chunks: 4 bytes chunk length, 32 bytes hash of the chunk, compressed chunk
+ *   4 zero bytes, 32 bytes hash of all the chunk hashes
+ * A chunk is a list of MPT nodes, each one is 4 bytes length and the encoded node.
+ */
+
+const (
+	// StateSnapshotVersion is the version of the snapshot format
+	StateSnapshotVersion = 1
+
+	stateSnapshotMagic = "0chain_state_snapshot"
+	// size of the uncompressed nodes of a chunk
+	stateSnapshotChunkSize = 4 * 1024 * 1024
+	// limits of untrusted lengths read from a snapshot
+	maxStateSnapshotHeaderSize = 1024 * 1024
+	maxStateSnapshotChunkSize  = 2 * stateSnapshotChunkSize
+	maxStateSnapshotNodeSize   = stateSnapshotChunkSize
+)
+
+//ErrInvalidStateSnapshot - indicates a malformed or tampered snapshot
+var ErrInvalidStateSnapshot = errors.New("invalid state snapshot")
+
+//StateSnapshotHeader - the block the state snapshot is of, signed by the miners of the round
+type StateSnapshotHeader struct {
+	Version  int            `json:"version"`
+	Block    *BlockHeader   `json:"block"`
+	Tickets  []*ProofTicket `json:"verification_tickets"`
+	Encoding string         `json:"encoding"`
+}
+
+//GetStateSnapshotCompDe - get the compression of a snapshot by its encoding
+func GetStateSnapshotCompDe(encoding string) (common.CompDe, error) {
+	switch encoding {
+	case "snappy":
+		return common.NewSnappyCompDe(), nil
+	case "zstd":
+		return common.NewZStdCompDe(), nil
+	case "zlib":
+		return common.NewZLibCompDe(), nil
+	}
+	return nil, errors.New("unknown state snapshot encoding: " + encoding)
+}
+
+type stateSnapshotWriter struct {
+	w       io.Writer
+	compDe  common.CompDe
+	chunk   bytes.Buffer
+	digest  bytes.Buffer // hashes of the chunks written
+	scratch [4]byte
+}
+
+func (ssw *stateSnapshotWriter) writeUint32(w io.Writer, n int) error {
+	binary.BigEndian.PutUint32(ssw.scratch[:], uint32(n))
+	_, err := w.Write(ssw.scratch[:])
+	return err
+}
+
+func (ssw *stateSnapshotWriter) addNode(node Node) error {
+	data := node.Encode()
+	ssw.writeUint32(&ssw.chunk, len(data))
+	ssw.chunk.Write(data)
+	if ssw.chunk.Len() >= stateSnapshotChunkSize {
+		return ssw.flush()
+	}
+	return nil
+}
+
+func (ssw *stateSnapshotWriter) flush() error {
+	if ssw.chunk.Len() == 0 {
+		return nil
+	}
+	data := ssw.compDe.Compress(ssw.chunk.Bytes())
+	ssw.chunk.Reset()
+	hash := encryption.RawHash(data)
+	ssw.digest.Write(hash)
+	if err := ssw.writeUint32(ssw.w, len(data)); err != nil {
+		return err
+	}
+	if _, err := ssw.w.Write(hash); err != nil {
+		return err
+	}
+	_, err := ssw.w.Write(data)
+	return err
+}
+
+//WriteStateSnapshot - write all the nodes of the state of the block, returns the number of nodes written
+func WriteStateSnapshot(ctx context.Context, w io.Writer, mpt MerklePatriciaTrieI,
+	header *StateSnapshotHeader, compDe common.CompDe) (int64, error) {
+	if header.Block == nil || !bytes.Equal(header.Block.ClientStateHash, mpt.GetRoot()) {
+		return 0, errors.New("state snapshot header doesn't match the state")
+	}
+	header.Version = StateSnapshotVersion
+	header.Encoding = compDe.Encoding()
+	hdata, err := json.Marshal(header)
+	if err != nil {
+		return 0, err
+	}
+	ssw := &stateSnapshotWriter{w: w, compDe: compDe}
+	if _, err = io.WriteString(w, stateSnapshotMagic); err != nil {
+		return 0, err
+	}
+	if err = ssw.writeUint32(w, len(hdata)); err != nil {
+		return 0, err
+	}
+	if _, err = w.Write(hdata); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	handler := func(ctx context.Context, path Path, key Key, node Node) error {
+		if node == nil {
+			return ErrNodeNotFound
+		}
+		count++
+		return ssw.addNode(node)
+	}
+	err = mpt.Iterate(ctx, handler,
+		NodeTypeLeafNode|NodeTypeFullNode|NodeTypeExtensionNode)
+	if err != nil {
+		return count, err
+	}
+	if err = ssw.flush(); err != nil {
+		return count, err
+	}
+	if err = ssw.writeUint32(w, 0); err != nil {
+		return count, err
+	}
+	_, err = w.Write(encryption.RawHash(ssw.digest.Bytes()))
+	return count, err
+}
+
+func readStateSnapshotLength(r io.Reader, max int) (int, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, err
+	}
+	n := int(binary.BigEndian.Uint32(buf[:]))
+	if n > max {
+		return 0, ErrInvalidStateSnapshot
+	}
+	return n, nil
+}
+
+//ReadStateSnapshotHeader - read the header of a snapshot, the header should be verified before importing the nodes
+func ReadStateSnapshotHeader(r io.Reader) (*StateSnapshotHeader, error) {
+	magic := make([]byte, len(stateSnapshotMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != stateSnapshotMagic {
+		return nil, ErrInvalidStateSnapshot
+	}
+	n, err := readStateSnapshotLength(r, maxStateSnapshotHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	hdata := make([]byte, n)
+	if _, err = io.ReadFull(r, hdata); err != nil {
+		return nil, err
+	}
+	header := &StateSnapshotHeader{}
+	if err = json.Unmarshal(hdata, header); err != nil {
+		return nil, ErrInvalidStateSnapshot
+	}
+	if header.Version != StateSnapshotVersion || header.Block == nil ||
+		len(header.Block.ClientStateHash) == 0 {
+		return nil, ErrInvalidStateSnapshot
+	}
+	return header, nil
+}
+
+func decodeStateSnapshotChunk(data []byte, version Sequence) ([]Key, []Node, error) {
+	var (
+		keys  []Key
+		nodes []Node
+		r     = bytes.NewReader(data)
+	)
+	for r.Len() > 0 {
+		n, err := readStateSnapshotLength(r, maxStateSnapshotNodeSize)
+		if err != nil || n == 0 || n > r.Len() {
+			return nil, nil, ErrInvalidStateSnapshot
+		}
+		buf := make([]byte, n)
+		r.Read(buf)
+		node, err := decodeProofNode(buf)
+		if err != nil {
+			return nil, nil, ErrInvalidStateSnapshot
+		}
+		node.SetVersion(version)
+		keys = append(keys, node.GetHashBytes())
+		nodes = append(nodes, node)
+	}
+	return keys, nodes, nil
+}
+
+/*stateSnapshotFrontier - the nodes referenced by the nodes imported so far, but not imported yet;
+* a snapshot lists the nodes parent first, thus the frontier is about the depth of the trie */
+type stateSnapshotFrontier map[string]int
+
+func (ssf stateSnapshotFrontier) expect(key Key) {
+	ssf[string(key)]++
+}
+
+//accept - accept the node if it's referenced by an imported node (or it's the root)
+func (ssf stateSnapshotFrontier) accept(node Node) bool {
+	key := string(node.GetHashBytes())
+	n, ok := ssf[key]
+	if !ok {
+		return false
+	}
+	if n == 1 {
+		delete(ssf, key)
+	} else {
+		ssf[key] = n - 1
+	}
+	switch nodeImpl := node.(type) {
+	case *FullNode:
+		for _, child := range nodeImpl.Children {
+			if child != nil {
+				ssf.expect(child)
+			}
+		}
+	case *ExtensionNode:
+		ssf.expect(nodeImpl.NodeKey)
+	}
+	return true
+}
+
+/*ReadStateSnapshotNodes - import the nodes of a snapshot with the given header into the node db;
+* every node is verified to be a node of the state of the header before it's imported, thus an
+* invalid snapshot can leave some of the state imported, but nothing else; returns the number of
+* nodes imported */
+func ReadStateSnapshotNodes(ctx context.Context, r io.Reader, header *StateSnapshotHeader,
+	ndb NodeDB) (int64, error) {
+	compDe, err := GetStateSnapshotCompDe(header.Encoding)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		version = Sequence(header.Block.Round)
+		digest  bytes.Buffer
+		hash    = make([]byte, 32)
+		count   int64
+		// the root is expected first
+		frontier = stateSnapshotFrontier{}
+	)
+	frontier.expect(header.Block.ClientStateHash)
+	for {
+		if err = ctx.Err(); err != nil {
+			return count, err
+		}
+		n, err := readStateSnapshotLength(r, maxStateSnapshotChunkSize)
+		if err != nil {
+			return count, err
+		}
+		if _, err = io.ReadFull(r, hash); err != nil {
+			return count, err
+		}
+		if n == 0 {
+			break
+		}
+		data := make([]byte, n)
+		if _, err = io.ReadFull(r, data); err != nil {
+			return count, err
+		}
+		if !bytes.Equal(encryption.RawHash(data), hash) {
+			return count, ErrInvalidStateSnapshot
+		}
+		digest.Write(hash)
+		if data, err = compDe.Decompress(data); err != nil {
+			return count, ErrInvalidStateSnapshot
+		}
+		keys, nodes, err := decodeStateSnapshotChunk(data, version)
+		if err != nil {
+			return count, err
+		}
+		for _, node := range nodes {
+			if !frontier.accept(node) {
+				return count, ErrInvalidStateSnapshot
+			}
+		}
+		if err = ndb.MultiPutNode(keys, nodes); err != nil {
+			return count, err
+		}
+		count += int64(len(nodes))
+	}
+	// all the nodes of the state should be imported
+	if !bytes.Equal(encryption.RawHash(digest.Bytes()), hash) ||
+		len(frontier) != 0 {
+		return count, ErrInvalidStateSnapshot
+	}
+	return count, nil
+}
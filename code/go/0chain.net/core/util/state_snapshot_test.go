@@ -0,0 +1,94 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+func TestStateSnapshot(t *testing.T) {
+	mpt := newProofMPT(t, 300)
+	header := &StateSnapshotHeader{
+		Block: &BlockHeader{Round: 10, ClientStateHash: mpt.GetRoot()},
+	}
+	var buf bytes.Buffer
+	count, err := WriteStateSnapshot(context.Background(), &buf, mpt, header,
+		common.NewZStdCompDe())
+	require.NoError(t, err)
+	assert.True(t, count > 300)
+	snapshot := buf.Bytes()
+
+	importSnapshot := func(snapshot []byte, ndb NodeDB) (int64, error) {
+		r := bytes.NewReader(snapshot)
+		header, err := ReadStateSnapshotHeader(r)
+		if err != nil {
+			return 0, err
+		}
+		return ReadStateSnapshotNodes(context.Background(), r, header, ndb)
+	}
+
+	ndb := NewMemoryNodeDB()
+	imported, err := importSnapshot(snapshot, ndb)
+	require.NoError(t, err)
+	assert.Equal(t, count, imported)
+
+	state := NewMerklePatriciaTrie(ndb, Sequence(10))
+	state.SetRoot(mpt.GetRoot())
+	for i := 0; i < 300; i += 37 {
+		value, err := state.GetNodeValue(Path(encryption.Hash("key" + strconv.Itoa(i))))
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(i*100), string(value.Encode()))
+	}
+
+	// tampered and truncated snapshots
+	tampered := append([]byte{}, snapshot...)
+	tampered[len(tampered)/2] ^= 0xff
+	for _, bad := range [][]byte{tampered, snapshot[:len(snapshot)-1], snapshot[1:]} {
+		bndb := NewMemoryNodeDB()
+		_, err = importSnapshot(bad, bndb)
+		assert.Error(t, err)
+		// only the nodes of the state can be imported
+		for key := range bndb.Nodes {
+			_, err = ndb.GetNode(Key(key))
+			assert.NoError(t, err)
+		}
+	}
+
+
+	// the nodes don't make the state of the header
+	r := bytes.NewReader(snapshot)
+	header, err = ReadStateSnapshotHeader(r)
+	require.NoError(t, err)
+	header.Block.ClientStateHash = Key(encryption.RawHash("other"))
+	other := NewMemoryNodeDB()
+	_, err = ReadStateSnapshotNodes(context.Background(), r, header, other)
+	assert.Equal(t, ErrInvalidStateSnapshot, err)
+	assert.Zero(t, other.Size(context.Background()), "nothing imported")
+
+	// a snapshot of the root only, the state is incomplete
+	root, err := ndb.GetNode(mpt.GetRoot())
+	require.NoError(t, err)
+	var partial bytes.Buffer
+	ssw := &stateSnapshotWriter{w: &partial, compDe: common.NewZStdCompDe()}
+	require.NoError(t, ssw.addNode(root))
+	require.NoError(t, ssw.flush())
+	require.NoError(t, ssw.writeUint32(&partial, 0))
+	partial.Write(encryption.RawHash(ssw.digest.Bytes()))
+	rootOnly := NewMemoryNodeDB()
+	_, err = ReadStateSnapshotNodes(context.Background(), &partial,
+		&StateSnapshotHeader{Block: &BlockHeader{Round: 10,
+			ClientStateHash: mpt.GetRoot()}, Encoding: "zstd"}, rootOnly)
+	assert.Equal(t, ErrInvalidStateSnapshot, err)
+
+	// the header doesn't match the state exported
+	_, err = WriteStateSnapshot(context.Background(), &buf, mpt,
+		&StateSnapshotHeader{Block: &BlockHeader{}}, common.NewSnappyCompDe())
+	assert.Error(t, err)
+}
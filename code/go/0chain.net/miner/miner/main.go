@@ -43,6 +43,7 @@ func main() {
 	delayFile := flag.String("delay_file", "", "delay_file")
 	magicBlockFile := flag.String("magic_block_file", "", "magic_block_file")
 	initialStatesFile := flag.String("initial_states", "", "initial_states")
+	stateSnapshotImport := flag.String("state_snapshot_import", "", "import state snapshot file and start from its round")
 	flag.Parse()
 	config.Configuration.DeploymentMode = byte(*deploymentMode)
 	config.SetupDefaultConfig()
@@ -221,6 +222,16 @@ func main() {
 	// if there is errors
 	mc.SetupLatestAndPreviousMagicBlocks(ctx)
 
+	if *stateSnapshotImport != "" {
+		header, err := mc.ImportStateSnapshotFile(ctx, *stateSnapshotImport)
+		if err == nil {
+			err = mc.StartFromStateSnapshot(ctx, header)
+		}
+		if err != nil {
+			logging.Logger.Panic("import state snapshot", zap.Error(err))
+		}
+	}
+
 	mb = mc.GetLatestMagicBlock()
 	if mb.StartingRound == 0 && mb.IsActiveNode(node.Self.Underlying().GetKey(), mb.StartingRound) {
 		genesisDKG := viper.GetInt64("network.genesis_dkg")
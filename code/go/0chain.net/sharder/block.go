@@ -103,6 +103,33 @@ func (sc *Chain) GetBlockFromHash(ctx context.Context, hash string, roundNum int
 	return b, nil
 }
 
+/*LoadFinalizedBlock - get the finalized block of the round, to replay it to stream subscribers */
+func (sc *Chain) LoadFinalizedBlock(ctx context.Context, roundNum int64) (*block.Block, error) {
+	if lfb := sc.GetLatestFinalizedBlock(); lfb == nil || roundNum > lfb.Round {
+		return nil, common.NewError("block_not_available", "round is not finalized yet")
+	}
+	hash, err := sc.GetBlockHash(ctx, roundNum)
+	if err != nil {
+		return nil, err
+	}
+	return sc.GetBlockFromHash(ctx, hash, roundNum)
+}
+
+/*LoadFinalizedBlockSummary - get the summary of the finalized block of the round */
+func (sc *Chain) LoadFinalizedBlockSummary(ctx context.Context, roundNum int64) (*block.BlockSummary, error) {
+	if lfb := sc.GetLatestFinalizedBlock(); lfb == nil || roundNum > lfb.Round {
+		return nil, common.NewError("block_not_available", "round is not finalized yet")
+	}
+	hash, err := sc.GetBlockHash(ctx, roundNum)
+	if err != nil {
+		return nil, err
+	}
+	bSummaryEntityMetadata := datastore.GetEntityMetadata("block_summary")
+	bctx := ememorystore.WithEntityConnection(ctx, bSummaryEntityMetadata)
+	defer ememorystore.Close(bctx)
+	return sc.GetBlockSummary(bctx, hash)
+}
+
 /*StoreBlockSummaryFromBlock - gets block summary from block and stores it to ememory/rocksdb */
 func (sc *Chain) StoreBlockSummaryFromBlock(ctx context.Context, b *block.Block) error {
 	bs := b.GetSummary()
@@ -37,6 +37,7 @@ func SetupSharderChain(c *chain.Chain) {
 	c.SetViewChanger(sharderChain)
 	c.SetAfterFetcher(sharderChain)
 	c.SetMagicBlockSaver(sharderChain)
+	c.SetFinalizedBlockLoader(sharderChain)
 	sharderChain.BlockSyncStats = &SyncStats{}
 	sharderChain.TieringStats = &MinioStats{}
 	c.RoundF = SharderRoundFactory{}
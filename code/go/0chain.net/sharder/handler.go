@@ -26,6 +26,8 @@ func SetupHandlers() {
 	http.HandleFunc("/_chain_stats", common.UserRateLimit(ChainStatsWriter))
 	http.HandleFunc("/_health_check", common.UserRateLimit(HealthCheckWriter))
 	http.HandleFunc("/v1/sharder/get/stats", common.UserRateLimit(common.ToJSONResponse(SharderStatsHandler)))
+	http.HandleFunc("/v1/scstate/proof", common.UserRateLimit(common.ToJSONResponse(SCStateProofHandler)))
+	http.HandleFunc("/v1/client/get/balance_proof", common.UserRateLimit(common.ToJSONResponse(BalanceProofHandler)))
 }
 
 /*BlockHandler - a handler to respond to block queries */
@@ -89,6 +89,9 @@ func main() {
 	minioFile := flag.String("minio_file", "", "minio_file")
 	initialStatesFile := flag.String("initial_states", "", "initial_states")
 	flag.String("nodes_file", "", "nodes_file (deprecated)")
+	stateSnapshotExport := flag.String("state_snapshot_export", "", "export state of the finalized round to the snapshot file and exit")
+	stateSnapshotRound := flag.Int64("state_snapshot_round", 0, "round of the state snapshot to export, the latest finalized round by default")
+	stateSnapshotImport := flag.String("state_snapshot_import", "", "import state snapshot file and start from its round")
 	flag.Parse()
 	config.Configuration.DeploymentMode = byte(*deploymentMode)
 	config.SetupDefaultConfig()
@@ -258,12 +261,34 @@ func main() {
 		return
 	}
 
+	if *stateSnapshotExport != "" {
+		var b *block.Block
+		b, err = sc.GetStateBlockOfRound(ctx, *stateSnapshotRound)
+		if err == nil {
+			err = sc.ExportStateSnapshotFile(ctx, b, *stateSnapshotExport)
+		}
+		if err != nil {
+			Logger.Error("export state snapshot: " + err.Error())
+		}
+		return
+	}
+
 	startBlocksInfoLogs(sc)
 
 	if err := sc.UpdateLatesMagicBlockFromSharders(ctx); err != nil {
 		Logger.Fatal("update LFMB from sharders", zap.Error(err))
 	}
 
+	if *stateSnapshotImport != "" {
+		header, err := sc.ImportStateSnapshotFile(ctx, *stateSnapshotImport)
+		if err == nil {
+			err = sc.StartFromStateSnapshot(ctx, header)
+		}
+		if err != nil {
+			Logger.Fatal("import state snapshot", zap.Error(err))
+		}
+	}
+
 	if serverChain.GetCurrentMagicBlock().MagicBlockNumber <
 		serverChain.GetLatestMagicBlock().MagicBlockNumber {
 
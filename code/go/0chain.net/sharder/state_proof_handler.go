@@ -0,0 +1,106 @@
+package sharder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+/*StateProofResponse - a value of the global state with the proof of the value */
+type StateProofResponse struct {
+	Value interface{} `json:"value"`
+	*util.StateProof
+}
+
+/*getStateProofBlock - get the finalized block of the requested round, or the latest finalized block, along with its state */
+func getStateProofBlock(ctx context.Context, r *http.Request) (*block.Block, util.MerklePatriciaTrieI, error) {
+	sc := GetSharderChain()
+	sb, err := sc.GetStateBlock(ctx, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	// a block of a past round is restored from its summary without the transactions
+	// and the verification tickets required by the proof
+	if len(sb.GetVerificationTickets()) > 0 {
+		return sb, sb.ClientState, nil
+	}
+	b, err := sc.GetBlockFromHash(ctx, sb.Hash, sb.Round)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, sb.ClientState, nil
+}
+
+/*getStateProof - get the value of the path in the state of the block along with the proof of the value */
+func getStateProof(b *block.Block, mpt util.MerklePatriciaTrieI, path util.Path) (util.Serializable, *util.StateProof, error) {
+	nodes, err := mpt.GetPathNodes(path)
+	if err == util.ErrValueNotPresent {
+		return nil, nil, common.NewError("key_not_found", "key was not found")
+	}
+	if err == util.ErrNodeNotFound {
+		return nil, nil, common.NewError("state_not_available", "state of the round is not available")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var value util.Serializable
+	if len(nodes) > 0 {
+		value = util.GetValueNode(nodes[len(nodes)-1]).GetValue()
+	}
+	if value == nil {
+		return nil, nil, common.NewError("key_not_found", "key was not found")
+	}
+	bvt := b.GetVerificationTickets()
+	tickets := make([]*util.ProofTicket, 0, len(bvt))
+	for _, vt := range bvt {
+		tickets = append(tickets, &util.ProofTicket{VerifierID: vt.VerifierID, Signature: vt.Signature})
+	}
+	return value, util.NewStateProof(b.GetHeader(), tickets, nodes), nil
+}
+
+/*SCStateProofHandler - a handler to respond with a node of a smart contract state and its proof;
+* the proof can be verified only for blocks of the state_hash_round and later, the hash of an earlier
+* block doesn't cover its state hash (signed_state_hash is false) and a verifier rejects the proof */
+func SCStateProofHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	scAddress := r.FormValue("sc_address")
+	key := r.FormValue("key")
+	b, mpt, err := getStateProofBlock(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	value, proof, err := getStateProof(b, mpt, util.Path(encryption.Hash(scAddress+key)))
+	if err != nil {
+		return nil, err
+	}
+	var retObj interface{}
+	if err = json.Unmarshal(value.Encode(), &retObj); err != nil {
+		return nil, err
+	}
+	return &StateProofResponse{Value: retObj, StateProof: proof}, nil
+}
+
+/*BalanceProofHandler - a handler to respond with the balance of a client and its proof;
+* like the SCStateProofHandler the proof can be verified only starting from the state_hash_round */
+func BalanceProofHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	clientID := r.FormValue("client_id")
+	b, mpt, err := getStateProofBlock(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	value, proof, err := getStateProof(b, mpt, util.Path(clientID))
+	if err != nil {
+		return nil, err
+	}
+	st := &state.State{}
+	if err = st.Decode(value.Encode()); err != nil {
+		return nil, err
+	}
+	st.ComputeProperties()
+	return &StateProofResponse{Value: st, StateProof: proof}, nil
+}
@@ -82,15 +82,26 @@ func (sc *Chain) GetTransactionConfirmation(ctx context.Context, hash string) (*
 		confirmation.RoundRandomSeed = b.GetRoundRandomSeed()
 		confirmation.CreationDate = b.CreationDate
 	}
-	txn := b.GetTransaction(hash)
+	var txn *transaction.Transaction
+	txnIndex := -1
+	for i, t := range b.Txns {
+		if t.Hash == hash {
+			txn, txnIndex = t, i
+			break
+		}
+	}
+	if txn == nil {
+		return nil, common.NewError("txn_not_found", "transaction is not found in the block")
+	}
 	confirmation.Status = txn.Status
 	confirmation.Transaction = txn
 	mt := b.GetMerkleTree()
 	confirmation.MerkleTreeRoot = mt.GetRoot()
-	confirmation.MerkleTreePath = mt.GetPath(confirmation)
+	confirmation.MerkleTreePath = mt.GetPathByIndex(txnIndex)
 	rmt := b.GetReceiptsMerkleTree()
 	confirmation.ReceiptMerkleTreeRoot = rmt.GetRoot()
-	confirmation.ReceiptMerkleTreePath = rmt.GetPath(transaction.NewTransactionReceipt(txn))
+	// receipts of equal outputs have equal hashes, find the path by the index
+	confirmation.ReceiptMerkleTreePath = rmt.GetPathByIndex(txnIndex)
 	confirmation.PreviousBlockHash = b.PrevHash
 	return confirmation, nil
 }
@@ -0,0 +1,150 @@
+package faucetsc
+
+import (
+	"encoding/json"
+	"math/bits"
+	"strconv"
+
+	c_state "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+// max proof-of-work difficulty, in leading zero bits of the solution hash
+const maxPowDifficulty = 64
+
+// pourRequest is an optional input of a pour; it carries a solution of the
+// pour challenge of the client if challenges are enabled: either a nonce of
+// the proof-of-work or a token signed by an allowed attester
+type pourRequest struct {
+	Nonce    string           `json:"nonce,omitempty"`
+	Attester string           `json:"attester,omitempty"` // public key
+	Token    string           `json:"token,omitempty"`    // signature
+	Expires  common.Timestamp `json:"expires,omitempty"`
+}
+
+func (pr *pourRequest) decode(input []byte) error {
+	err := json.Unmarshal(input, pr)
+	return err
+}
+
+// pourChallenge is the challenge a client should solve to pour
+type pourChallenge struct {
+	Seed          string   `json:"seed"`
+	PowDifficulty int      `json:"pow_difficulty"`
+	Attesters     []string `json:"attesters"`
+}
+
+// challengeSeed is the seed of the next pour challenge of the client, a
+// solution can't be used for other pour or other client
+func (un *UserNode) challengeSeed() string {
+	return encryption.Hash(un.ID + ":" + strconv.FormatInt(un.Pours, 10))
+}
+
+// attestationHash is the hash an attester signs to allow the pour
+func attestationHash(seed string, expires common.Timestamp) string {
+	return encryption.Hash(seed + ":" + strconv.FormatInt(int64(expires), 10))
+}
+
+func leadingZeroBits(hash []byte) (n int) {
+	for _, b := range hash {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return
+}
+
+// verifyPow checks the hash of the seed and the nonce has at least
+// difficulty leading zero bits
+func verifyPow(seed, nonce string, difficulty int) bool {
+	return leadingZeroBits(encryption.RawHash(seed+":"+nonce)) >= difficulty
+}
+
+func (gn *GlobalNode) challengeEnabled() bool {
+	return gn.PowDifficulty > 0 || len(gn.Attesters) > 0
+}
+
+func (gn *GlobalNode) isAttester(publicKey string) bool {
+	for _, key := range gn.Attesters {
+		if key == publicKey {
+			return true
+		}
+	}
+	return false
+}
+
+// validateChallengeLimits checks the challenge configurations of a limits
+// request
+func validateChallengeLimits(lr *limitRequest,
+	balances c_state.StateContextI) error {
+
+	if lr.PowDifficulty != nil &&
+		(*lr.PowDifficulty < 0 || *lr.PowDifficulty > maxPowDifficulty) {
+		return common.NewError("bad_request",
+			"pow_difficulty is not in [0; "+
+				strconv.Itoa(maxPowDifficulty)+"] range")
+	}
+	if lr.Attesters == nil {
+		return nil
+	}
+	for _, key := range *lr.Attesters {
+		if err := balances.GetSignatureScheme().SetPublicKey(key); err != nil {
+			return common.NewError("bad_request",
+				"invalid attester public key: "+key)
+		}
+	}
+	return nil
+}
+
+// verifyChallenge verifies the solution of the pour challenge of the client
+// if challenges are enabled; a valid attester token or a proof-of-work
+// solution is accepted
+func (un *UserNode) verifyChallenge(t *transaction.Transaction,
+	inputData []byte, balances c_state.StateContextI, gn *GlobalNode) error {
+
+	if !gn.challengeEnabled() {
+		return nil
+	}
+	var pr pourRequest
+	if err := pr.decode(inputData); err != nil {
+		return common.NewError("invalid_challenge",
+			"pour request not formated correctly")
+	}
+	var seed = un.challengeSeed()
+	switch {
+	case pr.Token != "":
+		if !gn.isAttester(pr.Attester) {
+			return common.NewError("invalid_challenge",
+				"token is not signed by an allowed attester")
+		}
+		if pr.Expires <= t.CreationDate {
+			return common.NewError("invalid_challenge", "token expired")
+		}
+		var scheme = balances.GetSignatureScheme()
+		if err := scheme.SetPublicKey(pr.Attester); err != nil {
+			return common.NewError("invalid_challenge",
+				"invalid attester public key")
+		}
+		ok, err := scheme.Verify(pr.Token, attestationHash(seed, pr.Expires))
+		if err != nil || !ok {
+			return common.NewError("invalid_challenge",
+				"invalid token signature")
+		}
+		return nil
+	case pr.Nonce != "":
+		if gn.PowDifficulty == 0 {
+			return common.NewError("invalid_challenge",
+				"proof of work is disabled, an attester token required")
+		}
+		if !verifyPow(seed, pr.Nonce, gn.PowDifficulty) {
+			return common.NewError("invalid_challenge",
+				"proof of work doesn't meet the difficulty")
+		}
+		return nil
+	}
+	return common.NewError("invalid_challenge",
+		"pour challenge solution required")
+}
@@ -18,6 +18,8 @@ type faucetConfig struct {
 	GlobalLimit     state.Balance `json:"global_limit"`
 	IndividualReset time.Duration `json:"individual_reset"` //in hours
 	GlobalReset     time.Duration `json:"global_rest"`      //in hours
+	PowDifficulty   int           `json:"pow_difficulty"`
+	Attesters       []string      `json:"attesters"`
 }
 
 // configurations from sc.yaml
@@ -29,6 +31,8 @@ func getConfig() (conf *faucetConfig, err error) {
 	conf.GlobalLimit = state.Balance(config.SmartContractConfig.GetInt("smart_contracts.faucetsc.global_limit"))
 	conf.IndividualReset = config.SmartContractConfig.GetDuration("smart_contracts.faucetsc.individual_reset")
 	conf.GlobalReset = config.SmartContractConfig.GetDuration("smart_contracts.faucetsc.global_reset")
+	conf.PowDifficulty = config.SmartContractConfig.GetInt("smart_contracts.faucetsc.pow_difficulty")
+	conf.Attesters = config.SmartContractConfig.GetStringSlice("smart_contracts.faucetsc.attesters")
 	return
 }
 
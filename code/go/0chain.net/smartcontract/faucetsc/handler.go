@@ -9,6 +9,7 @@ import (
 	"net/url"
 
 	c_state "0chain.net/chaincore/chain/state"
+	"0chain.net/core/util"
 )
 
 const (
@@ -62,3 +63,19 @@ func (fc *FaucetSmartContract) pourAmount(ctx context.Context, params url.Values
 	}
 	return fmt.Sprintf("Pour amount per request: %v", gn.PourAmount), nil
 }
+
+func (fc *FaucetSmartContract) pourChallenge(ctx context.Context, params url.Values, balances c_state.StateContextI) (interface{}, error) {
+	gn, err := fc.getGlobalNode(balances)
+	if err != nil {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, "can't get pour challenge", noGlobalNodeMsg)
+	}
+	un, err := fc.getUserNode(params.Get("client_id"), gn.ID, balances)
+	if err != nil && err != util.ErrValueNotPresent {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, "can't get pour challenge", noClient)
+	}
+	return &pourChallenge{
+		Seed:          un.challengeSeed(),
+		PowDifficulty: gn.PowDifficulty,
+		Attesters:     gn.Attesters,
+	}, nil
+}
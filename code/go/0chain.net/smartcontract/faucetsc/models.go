@@ -17,6 +17,9 @@ type limitRequest struct {
 	GlobalLimit     state.Balance `json:"global_limit"`
 	IndividualReset time.Duration `json:"individual_reset"` //in hours
 	GlobalReset     time.Duration `json:"global_rest"`      //in hours
+	// pour challenge, nil means not changed
+	PowDifficulty *int      `json:"pow_difficulty,omitempty"`
+	Attesters     *[]string `json:"attesters,omitempty"`
 }
 
 func (lr *limitRequest) encode() []byte {
@@ -56,6 +59,9 @@ type GlobalNode struct {
 	GlobalReset     time.Duration `json:"global_rest"`      //in hours
 	Used            state.Balance `json:"used"`
 	StartTime       time.Time     `json:"start_time"`
+	// pour challenge, disabled if there are no difficulty and no attesters
+	PowDifficulty int      `json:"pow_difficulty,omitempty"`
+	Attesters     []string `json:"attesters,omitempty"`
 }
 
 func (gn *GlobalNode) GetKey() datastore.Key {
@@ -84,6 +90,7 @@ type UserNode struct {
 	ID        string        `json:"id"`
 	StartTime time.Time     `json:"start_time"`
 	Used      state.Balance `json:"used"`
+	Pours     int64         `json:"pours,omitempty"` // total number of pours
 }
 
 func (un *UserNode) GetKey(globalKey string) datastore.Key {
@@ -14,6 +14,7 @@ import (
 	"0chain.net/core/common"
 	. "0chain.net/core/logging"
 	"0chain.net/core/util"
+	commonsc "0chain.net/smartcontract"
 	metrics "github.com/rcrowley/go-metrics"
 	"go.uber.org/zap"
 )
@@ -63,6 +64,7 @@ func (fc *FaucetSmartContract) setSC(sc *smartcontractinterface.SmartContract, _
 	fc.SmartContract.RestHandlers["/globalPerodicLimit"] = fc.globalPerodicLimit
 	fc.SmartContract.RestHandlers["/pourAmount"] = fc.pourAmount
 	fc.SmartContract.RestHandlers["/getConfig"] = fc.getConfigHandler
+	fc.SmartContract.RestHandlers["/pourChallenge"] = fc.pourChallenge
 	fc.SmartContractExecutionStats["updateLimits"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", fc.ID, "updateLimits"), nil)
 	fc.SmartContractExecutionStats["pour"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", fc.ID, "pour"), nil)
 	fc.SmartContractExecutionStats["refill"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", fc.ID, "refill"), nil)
@@ -92,7 +94,7 @@ func (un *UserNode) validPourRequest(t *transaction.Transaction, balances c_stat
 }
 
 func (fc *FaucetSmartContract) updateLimits(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI, gn *GlobalNode) (string, error) {
-	if t.ClientID != owner {
+	if !commonsc.IsConfigOwner(t.ClientID, owner) {
 		return "", common.NewError("unauthorized_access", "only the owner can update the limits")
 	}
 	var newRequest limitRequest
@@ -119,6 +121,15 @@ func (fc *FaucetSmartContract) updateLimits(t *transaction.Transaction, inputDat
 	if newRequest.GlobalReset > 0 {
 		gn.GlobalReset = newRequest.GlobalReset
 	}
+	if err = validateChallengeLimits(&newRequest, balances); err != nil {
+		return "", err
+	}
+	if newRequest.PowDifficulty != nil {
+		gn.PowDifficulty = *newRequest.PowDifficulty
+	}
+	if newRequest.Attesters != nil {
+		gn.Attesters = *newRequest.Attesters
+	}
 	_, err = balances.InsertTrieNode(gn.GetKey(), gn)
 	if err != nil {
 		return "", err
@@ -126,10 +137,22 @@ func (fc *FaucetSmartContract) updateLimits(t *transaction.Transaction, inputDat
 	return string(gn.Encode()), nil
 }
 
+// ValidateLimitsUpdate validates given updateLimits input.
+func ValidateLimitsUpdate(input []byte, balances c_state.StateContextI) error {
+	var lr limitRequest
+	if err := commonsc.DecodeConfigUpdate(input, &lr); err != nil {
+		return fmt.Errorf("decoding limits: %v", err)
+	}
+	return validateChallengeLimits(&lr, balances)
+}
+
 func (fc *FaucetSmartContract) pour(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI, gn *GlobalNode) (string, error) {
 	user := fc.getUserVariables(t, gn, balances)
 	ok, err := user.validPourRequest(t, balances, gn)
 	if ok {
+		if err = user.verifyChallenge(t, inputData, balances, gn); err != nil {
+			return "", err
+		}
 		var pourAmount = gn.PourAmount
 		if t.Value > 0 && t.Value < int64(gn.MaxPourAmount) {
 			pourAmount = state.Balance(t.Value)
@@ -138,6 +161,7 @@ func (fc *FaucetSmartContract) pour(t *transaction.Transaction, inputData []byte
 		transfer := state.NewTransfer(t.ToClientID, t.ClientID, pourAmount)
 		balances.AddTransfer(transfer)
 		user.Used += transfer.Amount
+		user.Pours++
 		gn.Used += transfer.Amount
 		_, err = balances.InsertTrieNode(user.GetKey(gn.ID), user)
 		if err != nil {
@@ -224,6 +248,8 @@ func (fc *FaucetSmartContract) getGlobalVariables(t *transaction.Transaction, ba
 	gn.GlobalLimit = state.Balance(config.SmartContractConfig.GetInt("smart_contracts.faucetsc.global_limit"))
 	gn.IndividualReset = config.SmartContractConfig.GetDuration("smart_contracts.faucetsc.individual_reset")
 	gn.GlobalReset = config.SmartContractConfig.GetDuration("smart_contracts.faucetsc.global_reset")
+	gn.PowDifficulty = config.SmartContractConfig.GetInt("smart_contracts.faucetsc.pow_difficulty")
+	gn.Attesters = config.SmartContractConfig.GetStringSlice("smart_contracts.faucetsc.attesters")
 	gn.Used = 0
 	gn.StartTime = common.ToTime(t.CreationDate)
 	return gn
@@ -0,0 +1,27 @@
+package smartcontract
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// GovernanceAddress is address of the governance smart contract. Smart
+// contracts with owner gated configurations accept configurations updates
+// from this address too; the updates are applied by the governance SC after
+// an accepted proposal.
+const GovernanceAddress = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712db"
+
+// IsConfigOwner returns true if given client can update configurations of a
+// smart contract owned by given owner.
+func IsConfigOwner(clientID, owner string) bool {
+	return clientID == owner || clientID == GovernanceAddress
+}
+
+// DecodeConfigUpdate decodes configurations update of a smart contract on
+// top of given configurations; unknown fields are rejected, thus a mistyped
+// field of a proposal doesn't pass as a no-op.
+func DecodeConfigUpdate(input []byte, conf interface{}) error {
+	var dec = json.NewDecoder(bytes.NewReader(input))
+	dec.DisallowUnknownFields()
+	return dec.Decode(conf)
+}
@@ -0,0 +1,84 @@
+package governancesc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	configpkg "0chain.net/chaincore/config"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+)
+
+type config struct {
+	// VotingPeriod is number of rounds a proposal is open for votes.
+	VotingPeriod int64 `json:"voting_period"`
+	// Quorum is ratio of total miners and sharders stake should approve
+	// a proposal to apply it.
+	Quorum float64 `json:"quorum"`
+	// MinProposerStake is minimal stake of a proposer in miners and
+	// sharders delegate pools.
+	MinProposerStake state.Balance `json:"min_proposer_stake"`
+	// MaxDescriptionLength of a proposal.
+	MaxDescriptionLength int `json:"max_description_length"`
+	// MaxProposals is max number of proposals kept, the oldest closed
+	// proposals are deleted to open new ones.
+	MaxProposals int `json:"max_proposals"`
+}
+
+func (c *config) validate() (err error) {
+	switch {
+	case c.VotingPeriod < 1:
+		return errors.New("invalid voting_period (< 1)")
+	case c.Quorum <= 0 || c.Quorum > 1:
+		return errors.New("invalid quorum, out of (0; 1] range")
+	case c.MinProposerStake <= 0:
+		return errors.New("invalid min_proposer_stake (<= 0)")
+	case c.MaxDescriptionLength < 1:
+		return errors.New("invalid max_description_length (< 1)")
+	case c.MaxProposals < 1:
+		return errors.New("invalid max_proposals (< 1)")
+	}
+	return
+}
+
+//
+// helpers
+//
+
+// configurations from sc.yaml
+func getConfig() (conf *config, err error) {
+
+	const prefix = "smart_contracts.governancesc."
+
+	conf = new(config)
+
+	// short hand
+	var scconf = configpkg.SmartContractConfig
+	conf.VotingPeriod = scconf.GetInt64(prefix + "voting_period")
+	conf.Quorum = scconf.GetFloat64(prefix + "quorum")
+	conf.MinProposerStake = state.Balance(
+		scconf.GetFloat64(prefix+"min_proposer_stake") * 1e10)
+	conf.MaxDescriptionLength = scconf.GetInt(prefix + "max_description_length")
+	conf.MaxProposals = scconf.GetInt(prefix + "max_proposals")
+
+	if err = conf.validate(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+//
+// REST-handler
+//
+
+func (gsc *GovernanceSmartContract) getConfigHandler(context.Context,
+	url.Values, chainstate.StateContextI) (interface{}, error) {
+
+	res, err := getConfig()
+	if err != nil {
+		return nil, common.NewErrInternal("can't get config", err.Error())
+	}
+	return res, nil
+}
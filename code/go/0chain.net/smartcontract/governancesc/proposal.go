@@ -0,0 +1,559 @@
+package governancesc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/smartcontract"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+	commonsc "0chain.net/smartcontract"
+	"0chain.net/smartcontract/faucetsc"
+	"0chain.net/smartcontract/interestpoolsc"
+	"0chain.net/smartcontract/minersc"
+	"0chain.net/smartcontract/storagesc"
+)
+
+// proposal statuses
+const (
+	StatusOpen     = "open"     // voting in progress
+	StatusApplied  = "applied"  // accepted and applied
+	StatusRejected = "rejected" // can't be accepted anymore
+	StatusExpired  = "expired"  // not accepted before its deadline
+	StatusFailed   = "failed"   // accepted, but the target SC refused it
+)
+
+// target is configurations update function of a smart contract and
+// validation of its input
+type target struct {
+	address  datastore.Key
+	function string
+	validate func(input []byte, balances chainstate.StateContextI) error
+}
+
+// targets of proposals by names
+var targets = map[string]target{
+	"miner":    {minersc.ADDRESS, "update_globals", minersc.ValidateGlobalsUpdate},
+	"storage":  {storagesc.ADDRESS, "update_config", storagesc.ValidateConfigUpdate},
+	"interest": {interestpoolsc.ADDRESS, "updateVariables", interestpoolsc.ValidateVariablesUpdate},
+	"faucet":   {faucetsc.ADDRESS, "updateLimits", faucetsc.ValidateLimitsUpdate},
+}
+
+func proposalKey(gscKey, proposalID datastore.Key) datastore.Key {
+	return gscKey + ":proposal:" + proposalID
+}
+
+func proposalsKey(gscKey datastore.Key) datastore.Key {
+	return gscKey + ":proposals"
+}
+
+//
+// create proposal request
+//
+
+type proposalRequest struct {
+	// Target is name of smart contract to update: miner, storage,
+	// interest or faucet.
+	Target string `json:"target"`
+	// Changes is configurations diff in format of the target SC
+	// configurations update function.
+	Changes json.RawMessage `json:"changes"`
+	// Description of the proposal.
+	Description string `json:"description"`
+}
+
+func (pr *proposalRequest) decode(b []byte) error {
+	return json.Unmarshal(b, pr)
+}
+
+// validate the request and its changes applied to current configurations
+// of the target SC
+func (pr *proposalRequest) validate(conf *config,
+	balances chainstate.StateContextI) (err error) {
+
+	var tg, ok = targets[pr.Target]
+	if !ok {
+		return fmt.Errorf("unknown target %q", pr.Target)
+	}
+	var diff map[string]json.RawMessage
+	if err = json.Unmarshal(pr.Changes, &diff); err != nil {
+		return fmt.Errorf("changes is not a JSON object: %v", err)
+	}
+	if len(diff) == 0 {
+		return errors.New("empty changes")
+	}
+	if len(pr.Description) > conf.MaxDescriptionLength {
+		return errors.New("description too long")
+	}
+	if err = tg.validate(pr.Changes, balances); err != nil {
+		return fmt.Errorf("invalid changes: %v", err)
+	}
+	return
+}
+
+//
+// vote request
+//
+
+type voteRequest struct {
+	ProposalID datastore.Key `json:"proposal_id"`
+	Approve    bool          `json:"approve"`
+}
+
+func (vr *voteRequest) decode(b []byte) error {
+	return json.Unmarshal(b, vr)
+}
+
+//
+// proposal
+//
+
+type vote struct {
+	Approve bool          `json:"approve"`
+	Weight  state.Balance `json:"weight"`
+	Round   int64         `json:"round"`
+}
+
+type proposal struct {
+	ID          datastore.Key    `json:"id"`
+	Proposer    datastore.Key    `json:"proposer"`
+	Target      string           `json:"target"`
+	Changes     json.RawMessage  `json:"changes"`
+	Description string           `json:"description"`
+	Round       int64            `json:"round"`    // created at
+	Deadline    int64            `json:"deadline"` // last voting round
+	CreatedAt   common.Timestamp `json:"created_at"`
+	// TotalStake is the largest total active stake of miners and sharders
+	// seen from the creation to the last vote, the quorum is ratio of it.
+	TotalStake state.Balance `json:"total_stake"`
+
+	Status string           `json:"status"`
+	Yes    state.Balance    `json:"yes"`
+	No     state.Balance    `json:"no"`
+	Votes  map[string]*vote `json:"votes"` // client ID -> vote
+
+	// response or error of the target SC for an accepted proposal
+	Result string `json:"result,omitempty"`
+}
+
+func (p *proposal) Encode() (b []byte) {
+	var err error
+	if b, err = json.Marshal(p); err != nil {
+		panic(err) // must not happen
+	}
+	return
+}
+
+func (p *proposal) Decode(b []byte) error {
+	return json.Unmarshal(b, p)
+}
+
+// statusAt returns status of the proposal for given round, an open proposal
+// expires after its deadline
+func (p *proposal) statusAt(round int64) string {
+	if p.Status == StatusOpen && round > p.Deadline {
+		return StatusExpired
+	}
+	return p.Status
+}
+
+// addVote adds or replaces vote of given client
+func (p *proposal) addVote(clientID datastore.Key, v *vote) {
+	if prev, ok := p.Votes[clientID]; ok {
+		if prev.Approve {
+			p.Yes -= prev.Weight
+		} else {
+			p.No -= prev.Weight
+		}
+	}
+	if v.Approve {
+		p.Yes += v.Weight
+	} else {
+		p.No += v.Weight
+	}
+	p.Votes[clientID] = v
+}
+
+// isAccepted returns true if approved stake reaches the quorum
+func (p *proposal) isAccepted(total state.Balance, quorum float64) bool {
+	return float64(p.Yes) >= float64(total)*quorum
+}
+
+// isRejected returns true if the proposal can't reach the quorum anymore
+func (p *proposal) isRejected(total state.Balance, quorum float64) bool {
+	return float64(total-p.No) < float64(total)*quorum
+}
+
+func (p *proposal) save(gscKey datastore.Key,
+	balances chainstate.StateContextI) (err error) {
+
+	_, err = balances.InsertTrieNode(proposalKey(gscKey, p.ID), p)
+	return
+}
+
+//
+// list of all proposals
+//
+
+type proposals struct {
+	List []datastore.Key `json:"list"` // proposal IDs
+}
+
+func (ps *proposals) Encode() (b []byte) {
+	var err error
+	if b, err = json.Marshal(ps); err != nil {
+		panic(err) // must not happen
+	}
+	return
+}
+
+func (ps *proposals) Decode(b []byte) error {
+	return json.Unmarshal(b, ps)
+}
+
+// makeRoom removes the oldest closed proposals from the list to make room
+// for a new one in the list of given max length; the removed proposals are
+// deleted; it returns false if there are too many open proposals
+func (ps *proposals) makeRoom(gscKey datastore.Key, max int, round int64,
+	balances chainstate.StateContextI) (ok bool, err error) {
+
+	var (
+		remove = len(ps.List) - max + 1
+		kept   = ps.List[:0]
+	)
+	for _, id := range ps.List {
+		if remove <= 0 {
+			kept = append(kept, id)
+			continue
+		}
+		var val util.Serializable
+		if val, err = balances.GetTrieNode(proposalKey(gscKey, id)); err != nil {
+			return false, fmt.Errorf("getting proposal %s: %v", id, err)
+		}
+		var p proposal
+		if err = p.Decode(val.Encode()); err != nil {
+			return false, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+		}
+		if p.statusAt(round) == StatusOpen {
+			kept = append(kept, id)
+			continue
+		}
+		if _, err = balances.DeleteTrieNode(proposalKey(gscKey, id)); err != nil {
+			return false, fmt.Errorf("deleting proposal %s: %v", id, err)
+		}
+		remove--
+	}
+	ps.List = kept
+	return remove <= 0, nil
+}
+
+func (ps *proposals) save(gscKey datastore.Key,
+	balances chainstate.StateContextI) (err error) {
+
+	_, err = balances.InsertTrieNode(proposalsKey(gscKey), ps)
+	return
+}
+
+//
+// SC helpers
+//
+
+func (gsc *GovernanceSmartContract) getProposal(proposalID datastore.Key,
+	balances chainstate.StateContextI) (p *proposal, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(proposalKey(gsc.ID, proposalID))
+	if err != nil {
+		return
+	}
+	p = new(proposal)
+	if err = p.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	if p.Votes == nil {
+		p.Votes = make(map[string]*vote)
+	}
+	return
+}
+
+func (gsc *GovernanceSmartContract) getProposals(
+	balances chainstate.StateContextI) (ps *proposals, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(proposalsKey(gsc.ID))
+	if err == util.ErrValueNotPresent {
+		return new(proposals), nil
+	}
+	if err != nil {
+		return
+	}
+	ps = new(proposals)
+	if err = ps.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return
+}
+
+// apply accepted proposal through configurations update function of the
+// target SC; the target SC accepts the update from the governance SC; the
+// update is made in a nested state context committed only if the target SC
+// succeeds, thus a refused update doesn't leave partial changes
+func (gsc *GovernanceSmartContract) apply(t *transaction.Transaction,
+	p *proposal, balances chainstate.StateContextI) {
+
+	var (
+		tg = targets[p.Target]
+		sc = smartcontract.GetSmartContract(tg.address)
+	)
+	if sc == nil {
+		p.Status, p.Result = StatusFailed, "target smart contract is disabled"
+		return
+	}
+
+	var nested, ok = balances.(chainstate.NestedStateContextI)
+	if !ok {
+		p.Status, p.Result = StatusFailed, "nested state is not supported"
+		return
+	}
+
+	var tx = &transaction.Transaction{
+		ClientID:     ADDRESS,
+		ToClientID:   tg.address,
+		CreationDate: t.CreationDate,
+	}
+	tx.Hash = t.Hash
+
+	var update = nested.NestedStateContext(tx)
+	var resp, err = smartcontract.ExecuteWithStats(sc, tx, tg.function,
+		p.Changes, update)
+	if err != nil {
+		p.Status, p.Result = StatusFailed, err.Error()
+		return
+	}
+	if err = nested.CommitNested(update); err != nil {
+		p.Status, p.Result = StatusFailed, err.Error()
+		return
+	}
+	p.Status, p.Result = StatusApplied, resp
+}
+
+//
+// SC functions
+//
+
+// createProposal opens new proposal, the proposer should have stake in
+// miners or sharders delegate pools
+func (gsc *GovernanceSmartContract) createProposal(t *transaction.Transaction,
+	input []byte, balances chainstate.StateContextI) (resp string, err error) {
+
+	var conf *config
+	if conf, err = getConfig(); err != nil {
+		return "", common.NewError("create_proposal_failed",
+			"can't get SC configurations: "+err.Error())
+	}
+
+	var pr proposalRequest
+	if err = pr.decode(input); err != nil {
+		return "", common.NewError("create_proposal_failed",
+			"malformed request: "+err.Error())
+	}
+	if err = pr.validate(conf, balances); err != nil {
+		return "", common.NewError("create_proposal_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var stake state.Balance
+	if stake, err = minersc.GetDelegateStake(t.ClientID, balances); err != nil {
+		return "", common.NewError("create_proposal_failed",
+			"can't get stake of proposer: "+err.Error())
+	}
+	if stake < conf.MinProposerStake {
+		return "", common.NewError("create_proposal_failed",
+			fmt.Sprintf("not enough stake to propose: %d < %d", stake,
+				conf.MinProposerStake))
+	}
+
+	var total state.Balance
+	if total, err = minersc.GetTotalStake(balances); err != nil {
+		return "", common.NewError("create_proposal_failed",
+			"can't get total stake: "+err.Error())
+	}
+
+	var ps *proposals
+	if ps, err = gsc.getProposals(balances); err != nil {
+		return "", common.NewError("create_proposal_failed",
+			"can't get proposals list: "+err.Error())
+	}
+
+	var round = balances.GetBlock().Round
+	var ok bool
+	if ok, err = ps.makeRoom(gsc.ID, conf.MaxProposals, round,
+		balances); err != nil {
+		return "", common.NewError("create_proposal_failed",
+			"cleaning proposals list: "+err.Error())
+	}
+	if !ok {
+		return "", common.NewError("create_proposal_failed",
+			"too many open proposals")
+	}
+
+	var p = &proposal{
+		ID:          t.Hash,
+		Proposer:    t.ClientID,
+		Target:      pr.Target,
+		Changes:     pr.Changes,
+		Description: pr.Description,
+		Round:       round,
+		Deadline:    round + conf.VotingPeriod,
+		CreatedAt:   t.CreationDate,
+		TotalStake:  total,
+		Status:      StatusOpen,
+		Votes:       make(map[string]*vote),
+	}
+
+	if err = p.save(gsc.ID, balances); err != nil {
+		return "", common.NewError("create_proposal_failed",
+			"saving proposal: "+err.Error())
+	}
+	ps.List = append(ps.List, p.ID)
+	if err = ps.save(gsc.ID, balances); err != nil {
+		return "", common.NewError("create_proposal_failed",
+			"saving proposals list: "+err.Error())
+	}
+
+	return string(p.Encode()), nil
+}
+
+// vote for or against a proposal weighted by the voter stake in miners and
+// sharders delegate pools, a vote replaces previous vote of the voter; the
+// stake of the voter is locked until the proposal deadline; the proposal
+// applied once it reaches the quorum of the total stake, which is the total
+// stake at its creation, raised by stake added after it
+func (gsc *GovernanceSmartContract) vote(t *transaction.Transaction,
+	input []byte, balances chainstate.StateContextI) (resp string, err error) {
+
+	var conf *config
+	if conf, err = getConfig(); err != nil {
+		return "", common.NewError("vote_failed",
+			"can't get SC configurations: "+err.Error())
+	}
+
+	var vr voteRequest
+	if err = vr.decode(input); err != nil {
+		return "", common.NewError("vote_failed",
+			"malformed request: "+err.Error())
+	}
+
+	var p *proposal
+	if p, err = gsc.getProposal(vr.ProposalID, balances); err != nil {
+		return "", common.NewError("vote_failed",
+			"can't get proposal: "+err.Error())
+	}
+
+	var round = balances.GetBlock().Round
+	if status := p.statusAt(round); status != StatusOpen {
+		return "", common.NewError("vote_failed",
+			"proposal is "+status)
+	}
+
+	var weight state.Balance
+	if weight, err = minersc.GetDelegateStake(t.ClientID, balances); err != nil {
+		return "", common.NewError("vote_failed",
+			"can't get stake of voter: "+err.Error())
+	}
+	if weight == 0 {
+		return "", common.NewError("vote_failed", "no stake to vote")
+	}
+
+	// the weight can include stake added after the creation, thus the total
+	// should include it too; the stake of the previous voters is locked, so
+	// the approved stake never exceeds the total
+	var total state.Balance
+	if total, err = minersc.GetTotalStake(balances); err != nil {
+		return "", common.NewError("vote_failed",
+			"can't get total stake: "+err.Error())
+	}
+	if total > p.TotalStake {
+		p.TotalStake = total
+	}
+
+	// the voter can't unlock the stake and vote again as another client
+	if err = minersc.LockDelegateStake(t.ClientID, p.Deadline, balances); err != nil {
+		return "", common.NewError("vote_failed",
+			"can't lock stake of voter: "+err.Error())
+	}
+
+	p.addVote(t.ClientID, &vote{
+		Approve: vr.Approve,
+		Weight:  weight,
+		Round:   round,
+	})
+
+	switch {
+	case p.isAccepted(p.TotalStake, conf.Quorum):
+		gsc.apply(t, p, balances)
+	case p.isRejected(p.TotalStake, conf.Quorum):
+		p.Status = StatusRejected
+	}
+
+	if err = p.save(gsc.ID, balances); err != nil {
+		return "", common.NewError("vote_failed",
+			"saving proposal: "+err.Error())
+	}
+
+	return string(p.Encode()), nil
+}
+
+//
+// REST-handlers
+//
+
+func (gsc *GovernanceSmartContract) getProposalHandler(ctx context.Context,
+	params url.Values, balances chainstate.StateContextI) (
+	resp interface{}, err error) {
+
+	var p *proposal
+	if p, err = gsc.getProposal(params.Get("id"), balances); err != nil {
+		return nil, commonsc.NewErrNoResourceOrErrInternal(err, true,
+			"can't get proposal")
+	}
+	p.Status = p.statusAt(balances.GetBlock().Round)
+	return p, nil
+}
+
+// getProposalsHandler returns all proposals, or proposals with given status
+func (gsc *GovernanceSmartContract) getProposalsHandler(ctx context.Context,
+	params url.Values, balances chainstate.StateContextI) (
+	resp interface{}, err error) {
+
+	var ps *proposals
+	if ps, err = gsc.getProposals(balances); err != nil {
+		return nil, commonsc.NewErrNoResourceOrErrInternal(err, true,
+			"can't get proposals list")
+	}
+
+	var (
+		status = params.Get("status")
+		round  = balances.GetBlock().Round
+		list   = make([]*proposal, 0, len(ps.List))
+	)
+	for _, id := range ps.List {
+		var p *proposal
+		if p, err = gsc.getProposal(id, balances); err != nil {
+			return nil, commonsc.NewErrNoResourceOrErrInternal(err, true,
+				"can't get proposal", id)
+		}
+		if p.Status = p.statusAt(round); status != "" && p.Status != status {
+			continue
+		}
+		list = append(list, p)
+	}
+	return list, nil
+}
@@ -0,0 +1,383 @@
+package governancesc
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	configpkg "0chain.net/chaincore/config"
+	"0chain.net/chaincore/smartcontract"
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+	"0chain.net/core/viper"
+	"0chain.net/smartcontract/minersc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	configpkg.SmartContractConfig = viper.New()
+}
+
+func setConfig() {
+	const prefix = "smart_contracts.governancesc."
+	var scconf = configpkg.SmartContractConfig
+	scconf.Set(prefix+"voting_period", 100)
+	scconf.Set(prefix+"quorum", 0.6)
+	scconf.Set(prefix+"min_proposer_stake", 1)
+	scconf.Set(prefix+"max_description_length", 20)
+	scconf.Set(prefix+"max_proposals", 4)
+}
+
+// updatedKey is a key of a node changed by the testTarget
+const updatedKey = "updated"
+
+// testTarget is configurations update function of a target SC
+type testTarget struct {
+	sci.SmartContractInterface
+	calls  []*transaction.Transaction
+	refuse bool
+}
+
+func (tt *testTarget) Execute(t *transaction.Transaction, function string,
+	input []byte, balances chainstate.StateContextI) (string, error) {
+
+	if function != "update_globals" {
+		return "", common.NewError("failed execution", "unexpected function")
+	}
+	// partial changes of a refused update should be discarded
+	var _, err = balances.InsertTrieNode(updatedKey, &proposals{})
+	if err != nil {
+		return "", err
+	}
+	if tt.refuse {
+		return "", common.NewError("update_globals", "invalid configurations")
+	}
+	tt.calls = append(tt.calls, t)
+	return "updated", nil
+}
+
+func (tt *testTarget) GetExecutionStats() map[string]interface{} {
+	return nil
+}
+
+// addStakes adds given delegates with given active stakes in a miner
+func addStakes(t *testing.T, balances *testBalances,
+	stakes map[string]state.Balance) {
+
+	var mn = minersc.NewMinerNode()
+	mn.ID = "miner"
+	for clientID, stake := range stakes {
+		var dp = sci.NewDelegatePool()
+		dp.ID = "pool:" + clientID
+		dp.Balance = stake
+		dp.DelegateID = clientID
+		dp.Status = minersc.ACTIVE
+		mn.Active[dp.ID] = dp
+
+		var un = minersc.NewUserNode()
+		un.ID = clientID
+		un.Pools[mn.ID] = []datastore.Key{dp.ID}
+		_, err := balances.InsertTrieNode(un.GetKey(), un)
+		require.NoError(t, err)
+	}
+	var err error
+	_, err = balances.InsertTrieNode(minersc.ADDRESS+mn.ID, mn)
+	require.NoError(t, err)
+	_, err = balances.InsertTrieNode(minersc.AllMinersKey,
+		&minersc.MinerNodes{Nodes: []*minersc.MinerNode{mn}})
+	require.NoError(t, err)
+}
+
+// setMinerGlobals saves valid configurations of the Miner SC
+func setMinerGlobals(t *testing.T, balances *testBalances) {
+	var gn = &minersc.GlobalNode{
+		MinN:         1,
+		MaxN:         10,
+		MinS:         1,
+		MaxS:         10,
+		MaxDelegates: 10,
+	}
+	var _, err = balances.InsertTrieNode(minersc.GlobalNodeKey, gn)
+	require.NoError(t, err)
+}
+
+func TestGovernanceSmartContract(t *testing.T) {
+	setConfig()
+
+	var (
+		gsc      = NewGovernanceSmartContract().(*GovernanceSmartContract)
+		balances = newTestBalances(10)
+		tt       = new(testTarget)
+		err      error
+	)
+
+	smartcontract.ContractMap[minersc.ADDRESS] = tt
+	defer delete(smartcontract.ContractMap, minersc.ADDRESS)
+
+	setMinerGlobals(t, balances)
+	addStakes(t, balances, map[string]state.Balance{
+		"alice":   50e10,
+		"bob":     30e10,
+		"charlie": 20e10,
+	})
+
+	var txn = func(clientID string) *transaction.Transaction {
+		var tx = &transaction.Transaction{
+			ClientID:     clientID,
+			ToClientID:   ADDRESS,
+			CreationDate: common.Now(),
+		}
+		tx.Hash = encryption.Hash(clientID + balances.block.Hash +
+			common.TimeToString(tx.CreationDate))
+		balances.block.Hash = tx.Hash // make next hash different
+		balances.txn = tx
+		return tx
+	}
+
+	var propose = func(clientID, target, changes string) (
+		p *proposal, err error) {
+
+		var input, _ = json.Marshal(&proposalRequest{
+			Target:      target,
+			Changes:     json.RawMessage(changes),
+			Description: "test",
+		})
+		var resp string
+		if resp, err = gsc.Execute(txn(clientID), "create_proposal", input,
+			balances); err != nil {
+			return
+		}
+		p = new(proposal)
+		require.NoError(t, p.Decode([]byte(resp)))
+		return
+	}
+
+	var vote = func(clientID string, p *proposal, approve bool) (
+		_ *proposal, err error) {
+
+		var input, _ = json.Marshal(&voteRequest{
+			ProposalID: p.ID,
+			Approve:    approve,
+		})
+		if _, err = gsc.Execute(txn(clientID), "vote", input,
+			balances); err != nil {
+			return
+		}
+		return gsc.getProposal(p.ID, balances)
+	}
+
+	// create
+
+	_, err = propose("dave", "miner", `{"max_n":100}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not enough stake to propose")
+
+	_, err = propose("alice", "zrc20", `{"max_n":100}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown target "zrc20"`)
+
+	_, err = propose("alice", "miner", `{}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty changes")
+
+	_, err = propose("alice", "miner", `[1, 2]`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changes is not a JSON object")
+
+	// validated against configurations of the target SC
+	_, err = propose("alice", "miner", `{"max_n":"many"}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid changes: decoding configurations")
+
+	_, err = propose("alice", "miner", `{"max_m":100}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "max_m"`)
+
+	_, err = propose("alice", "miner", `{"min_n":0}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid changes: min_n is too small")
+
+	var accepted *proposal
+	accepted, err = propose("alice", "miner", `{"max_n":100}`)
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, accepted.Status)
+	assert.EqualValues(t, 110, accepted.Deadline)
+	assert.EqualValues(t, 100e10, accepted.TotalStake)
+
+	// raw keys are not proposal IDs
+	_, err = vote("alice", &proposal{ID: proposalsKey(gsc.ID)}, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't get proposal")
+	_, err = vote("alice", &proposal{ID: proposalKey(gsc.ID, accepted.ID)},
+		true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't get proposal")
+
+	// the quorum isn't lowered by stake removed after the creation
+	addStakes(t, balances, map[string]state.Balance{
+		"alice": 50e10,
+		"bob":   30e10,
+	})
+
+	// accept
+
+	accepted, err = vote("alice", accepted, true)
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, accepted.Status)
+	assert.EqualValues(t, 50e10, accepted.Yes)
+	assert.EqualValues(t, 100e10, accepted.TotalStake)
+
+	addStakes(t, balances, map[string]state.Balance{
+		"alice":   50e10,
+		"bob":     30e10,
+		"charlie": 20e10,
+	})
+	accepted, err = vote("alice", accepted, true) // lock the stake again
+	require.NoError(t, err)
+	assert.EqualValues(t, 50e10, accepted.Yes)
+
+	_, err = vote("dave", accepted, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no stake to vote")
+
+	accepted, err = vote("bob", accepted, false)
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, accepted.Status)
+	assert.EqualValues(t, 30e10, accepted.No)
+
+	// change the vote
+	accepted, err = vote("bob", accepted, true)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApplied, accepted.Status)
+	assert.EqualValues(t, 80e10, accepted.Yes)
+	assert.Zero(t, accepted.No)
+	assert.Equal(t, "updated", accepted.Result)
+	_, err = balances.GetTrieNode(updatedKey)
+	require.NoError(t, err)
+	delete(balances.tree, updatedKey)
+
+	// the stake of the voters is locked until the deadline
+	for _, clientID := range []string{"alice", "bob"} {
+		var un = minersc.NewUserNode()
+		un.ID = clientID
+		var val, err = balances.GetTrieNode(un.GetKey())
+		require.NoError(t, err)
+		require.NoError(t, un.Decode(val.Encode()))
+		assert.Equal(t, accepted.Deadline, un.LockedUntil)
+	}
+	addStakes(t, balances, map[string]state.Balance{
+		"alice":   50e10,
+		"bob":     30e10,
+		"charlie": 20e10,
+	})
+
+	require.Len(t, tt.calls, 1)
+	assert.Equal(t, ADDRESS, tt.calls[0].ClientID)
+	assert.Equal(t, minersc.ADDRESS, tt.calls[0].ToClientID)
+
+	_, err = vote("charlie", accepted, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "proposal is applied")
+
+	// reject
+
+	var rejected *proposal
+	rejected, err = propose("bob", "miner", `{"max_n":200}`)
+	require.NoError(t, err)
+	_, err = vote("bob", rejected, false)
+	require.NoError(t, err)
+	rejected, err = vote("charlie", rejected, false)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRejected, rejected.Status)
+
+	// refused by the target SC
+
+	var failed *proposal
+	failed, err = propose("charlie", "miner", `{"max_n":5}`)
+	require.NoError(t, err)
+	_, err = vote("alice", failed, true)
+	require.NoError(t, err)
+	tt.refuse = true
+	failed, err = vote("charlie", failed, true)
+	tt.refuse = false
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, failed.Status)
+	assert.Equal(t, "update_globals: invalid configurations", failed.Result)
+	require.Len(t, tt.calls, 1)
+	_, err = balances.GetTrieNode(updatedKey)
+	assert.Equal(t, util.ErrValueNotPresent, err)
+
+	// expire
+
+	var expired *proposal
+	expired, err = propose("alice", "miner", `{"max_n":300}`)
+	require.NoError(t, err)
+
+	// stake added after the creation raises the total stake
+	addStakes(t, balances, map[string]state.Balance{
+		"alice":   50e10,
+		"bob":     30e10,
+		"charlie": 20e10,
+		"eve":     100e10,
+	})
+	expired, err = vote("eve", expired, true)
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, expired.Status)
+	assert.EqualValues(t, 100e10, expired.Yes)
+	assert.EqualValues(t, 200e10, expired.TotalStake)
+	addStakes(t, balances, map[string]state.Balance{
+		"alice":   50e10,
+		"bob":     30e10,
+		"charlie": 20e10,
+	})
+
+	balances.block.Round = expired.Deadline + 1
+	_, err = vote("alice", expired, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "proposal is expired")
+
+	// REST handlers
+
+	var resp interface{}
+	resp, err = gsc.getProposalHandler(context.Background(),
+		url.Values{"id": []string{expired.ID}}, balances)
+	require.NoError(t, err)
+	assert.Equal(t, StatusExpired, resp.(*proposal).Status)
+
+	resp, err = gsc.getProposalsHandler(context.Background(), url.Values{},
+		balances)
+	require.NoError(t, err)
+	assert.Len(t, resp.([]*proposal), 4)
+
+	resp, err = gsc.getProposalsHandler(context.Background(),
+		url.Values{"status": []string{StatusRejected}}, balances)
+	require.NoError(t, err)
+	require.Len(t, resp.([]*proposal), 1)
+	assert.Equal(t, rejected.ID, resp.([]*proposal)[0].ID)
+
+	// the list is full, the oldest closed proposal is deleted
+	var open *proposal
+	open, err = propose("alice", "miner", `{"max_n":4}`)
+	require.NoError(t, err)
+	_, err = gsc.getProposal(accepted.ID, balances)
+	require.Error(t, err)
+
+	// full of open proposals
+	balances.block.Round = open.Deadline - 1
+	for i := 0; i < 3; i++ {
+		_, err = propose("alice", "miner", `{"max_n":3}`)
+		require.NoError(t, err)
+	}
+	_, err = propose("alice", "miner", `{"max_n":3}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many open proposals")
+}
@@ -0,0 +1,95 @@
+package governancesc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/smartcontract"
+	"0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	commonsc "0chain.net/smartcontract"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+const (
+	ADDRESS = commonsc.GovernanceAddress
+	name    = "governance"
+)
+
+type RestPoints = map[string]smartcontractinterface.SmartContractRestHandler
+
+type GovernanceSmartContract struct {
+	*smartcontractinterface.SmartContract
+}
+
+func NewGovernanceSmartContract() smartcontractinterface.SmartContractInterface {
+	var gscCopy = &GovernanceSmartContract{
+		smartcontractinterface.NewSC(ADDRESS),
+	}
+	gscCopy.setSC(gscCopy.SmartContract, &smartcontract.BCContext{})
+	return gscCopy
+}
+
+func (gsc *GovernanceSmartContract) GetHandlerStats(ctx context.Context,
+	params url.Values) (interface{}, error) {
+
+	return gsc.SmartContract.HandlerStats(ctx, params)
+}
+
+func (gsc *GovernanceSmartContract) GetExecutionStats() map[string]interface{} {
+	return gsc.SmartContractExecutionStats
+}
+
+func (gsc *GovernanceSmartContract) GetName() string {
+	return name
+}
+
+func (gsc *GovernanceSmartContract) GetAddress() string {
+	return ADDRESS
+}
+
+func (gsc *GovernanceSmartContract) GetRestPoints() RestPoints {
+	return gsc.RestHandlers
+}
+
+func (gsc *GovernanceSmartContract) setSC(
+	sc *smartcontractinterface.SmartContract,
+	bcContext smartcontractinterface.BCContextI) {
+
+	gsc.SmartContract = sc
+
+	// information (statistics) and configurations
+	gsc.SmartContract.RestHandlers["/getConfig"] = gsc.getConfigHandler
+	gsc.SmartContract.RestHandlers["/getProposal"] = gsc.getProposalHandler
+	gsc.SmartContract.RestHandlers["/getProposals"] = gsc.getProposalsHandler
+
+	// open a proposal {target,changes,description}
+	gsc.SmartContractExecutionStats["create_proposal"] = metrics.GetOrRegisterTimer(
+		fmt.Sprintf("sc:%v:func:%v", gsc.ID, "create_proposal"), nil)
+
+	// vote for a proposal {proposal_id,approve}, applies accepted proposal
+	gsc.SmartContractExecutionStats["vote"] = metrics.GetOrRegisterTimer(
+		fmt.Sprintf("sc:%v:func:%v", gsc.ID, "vote"), nil)
+}
+
+func (gsc *GovernanceSmartContract) Execute(t *transaction.Transaction,
+	function string, input []byte, balances chainstate.StateContextI) (
+	resp string, err error) {
+
+	switch function {
+
+	case "create_proposal":
+		resp, err = gsc.createProposal(t, input, balances)
+	case "vote":
+		resp, err = gsc.vote(t, input, balances)
+
+	default:
+		err = common.NewError("governance_sc_failed",
+			fmt.Sprintf("no function with %q name", function))
+	}
+	return
+}
@@ -162,5 +162,7 @@ func testConfiguredGlobalNode() *GlobalNode {
 	gn.APR = conf.GetFloat64(pfx + "apr")
 	gn.MinLock = state.Balance(conf.GetInt64(pfx + "min_lock"))
 	gn.MaxMint = state.Balance(conf.GetFloat64(pfx+"max_mint") * 1e10)
+	gn.EarlyUnlockPenalty = conf.GetFloat64(pfx + "early_unlock_penalty")
+	gn.Tiers = getConfiguredTiers(pfx + "tiers.")
 	return gn
 }
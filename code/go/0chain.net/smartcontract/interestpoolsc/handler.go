@@ -12,7 +12,7 @@ import (
 
 func (ip *InterestPoolSmartContract) getPoolsStats(ctx context.Context, params url.Values, balances c_state.StateContextI) (interface{}, error) {
 	un := ip.getUserNode(params.Get("client_id"), balances)
-	if len(un.Pools) == 0 {
+	if len(un.Pools) == 0 && len(un.Closed) == 0 {
 		return nil, common.NewErrNoResource("can't find user node")
 	}
 	t := time.Now()
@@ -24,6 +24,9 @@ func (ip *InterestPoolSmartContract) getPoolsStats(ctx context.Context, params u
 		}
 		stats.addStat(stat)
 	}
+	for _, stat := range un.Closed {
+		stats.addStat(stat)
+	}
 	return stats, nil
 }
 
@@ -39,6 +42,8 @@ func (ip *InterestPoolSmartContract) getPoolStats(pool *interestPool, t time.Tim
 	stat.Balance = pool.Balance
 	stat.APR = pool.APR
 	stat.TokensEarned = pool.TokensEarned
+	stat.Realised = pool.accrued(t)
+	stat.History = pool.History
 	return stat, nil
 }
 
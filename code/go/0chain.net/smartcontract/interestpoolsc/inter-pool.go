@@ -11,6 +11,7 @@ type interestPool struct {
 	*tokenpool.ZcnLockingPool `json:"pool"`
 	APR                       float64       `json:"apr"`
 	TokensEarned              state.Balance `json:"tokens_earned"`
+	History                   []*yieldStat  `json:"history,omitempty"`
 }
 
 func newInterestPool() *interestPool {
@@ -46,6 +47,13 @@ func (ip *interestPool) decode(input []byte) error {
 		}
 		ip.TokensEarned = earned
 	}
+	h, ok := objMap["history"]
+	if ok {
+		err = json.Unmarshal(*h, &ip.History)
+		if err != nil {
+			return err
+		}
+	}
 	p, ok := objMap["pool"]
 	if ok {
 		err = ip.ZcnLockingPool.Decode(*p, &tokenLock{})
@@ -37,6 +37,11 @@ type poolStat struct {
 	APR          float64          `json:"apr"`
 	TokensEarned state.Balance    `json:"tokens_earned"`
 	Balance      state.Balance    `json:"balance"`
+
+	Realised  state.Balance    `json:"realised,omitempty"`  // accrued yield
+	Forfeited state.Balance    `json:"forfeited,omitempty"` // early unlock
+	Closed    common.Timestamp `json:"closed,omitempty"`    // unlocked at
+	History   []*yieldStat     `json:"history,omitempty"`
 }
 
 func (ps *poolStat) encode() []byte {
@@ -0,0 +1,180 @@
+package interestpoolsc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"0chain.net/chaincore/config"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+)
+
+// max number of closed pools kept in a user node for the stats
+const maxClosedPools = 20
+
+// pool history events
+const (
+	eventLock        = "lock"
+	eventUnlock      = "unlock"
+	eventEarlyUnlock = "early_unlock"
+)
+
+// rateTier is an interest rate for locks not shorter and not less than
+// given ones
+type rateTier struct {
+	Name        string        `json:"name"`
+	MinDuration time.Duration `json:"min_duration"`
+	MinAmount   state.Balance `json:"min_amount"`
+	APR         float64       `json:"apr"`
+}
+
+func (rt *rateTier) validate() error {
+	switch {
+	case rt.MinDuration < 0:
+		return errors.New("negative min_duration")
+	case rt.MinAmount < 0:
+		return errors.New("negative min_amount")
+	case rt.APR < 0:
+		return errors.New("negative apr")
+	}
+	return nil
+}
+
+func (rt *rateTier) match(amount state.Balance, duration time.Duration) bool {
+	return duration >= rt.MinDuration && amount >= rt.MinAmount
+}
+
+// sortTiers by name to keep the global node deterministic
+func sortTiers(tiers []*rateTier) {
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].Name < tiers[j].Name
+	})
+}
+
+func validateRates(tiers []*rateTier, penalty float64) (err error) {
+	for _, rt := range tiers {
+		if err = rt.validate(); err != nil {
+			return fmt.Errorf("invalid tier %q: %v", rt.Name, err)
+		}
+	}
+	if penalty < 0.0 || penalty > 1.0 {
+		return errors.New("early_unlock_penalty is not in [0; 1] range")
+	}
+	return
+}
+
+// getConfiguredTiers returns rate tiers configured as
+// 'interestpoolsc.tiers.<name>' in sc.yaml, or nil if there are no tiers
+func getConfiguredTiers(pfx string) (tiers []*rateTier) {
+	var conf = config.SmartContractConfig
+	for name := range conf.GetStringMap(pfx[:len(pfx)-1]) {
+		var tier = pfx + name + "."
+		tiers = append(tiers, &rateTier{
+			Name:        name,
+			MinDuration: conf.GetDuration(tier + "min_duration"),
+			MinAmount: state.Balance(
+				conf.GetFloat64(tier+"min_amount") * 1e10),
+			APR: conf.GetFloat64(tier + "apr"),
+		})
+	}
+	sortTiers(tiers)
+	return
+}
+
+// rate returns APR for a new lock; it's the highest APR of tiers matching
+// the lock, or the base APR if there are no such tiers
+func (gn *GlobalNode) rate(amount state.Balance, duration time.Duration) (
+	apr float64) {
+
+	apr = gn.APR
+	var matched bool
+	for _, rt := range gn.Tiers {
+		if !rt.match(amount, duration) {
+			continue
+		}
+		if !matched || rt.APR > apr {
+			apr, matched = rt.APR, true
+		}
+	}
+	return
+}
+
+// yieldStat is a point of an interest pool history
+type yieldStat struct {
+	Time      common.Timestamp `json:"time"`
+	Event     string           `json:"event"`
+	APR       float64          `json:"apr"`
+	Projected state.Balance    `json:"projected"`
+	Realised  state.Balance    `json:"realised"`
+	Forfeited state.Balance    `json:"forfeited,omitempty"`
+}
+
+// lock of the pool
+func (ip *interestPool) lock() (tl tokenLock) {
+	switch lock := ip.TokenLockInterface.(type) {
+	case tokenLock:
+		return lock
+	case *tokenLock:
+		return *lock
+	}
+	return
+}
+
+// accrued returns interest accrued by the pool by given time with the APR
+// the pool opened with
+func (ip *interestPool) accrued(now time.Time) (accrued state.Balance) {
+	var (
+		tl      = ip.lock()
+		elapsed = now.Sub(common.ToTime(tl.StartTime))
+	)
+	if elapsed <= 0 {
+		return 0
+	}
+	if elapsed >= tl.Duration {
+		return ip.TokensEarned
+	}
+	accrued = state.Balance(float64(ip.Balance) * ip.APR *
+		float64(elapsed) / float64(YEAR))
+	if accrued > ip.TokensEarned {
+		accrued = ip.TokensEarned
+	}
+	return
+}
+
+// addHistory adds new point to the pool history
+func (ip *interestPool) addHistory(now common.Timestamp, event string,
+	realised, forfeited state.Balance) {
+
+	ip.History = append(ip.History, &yieldStat{
+		Time:      now,
+		Event:     event,
+		APR:       ip.APR,
+		Projected: ip.TokensEarned,
+		Realised:  realised,
+		Forfeited: forfeited,
+	})
+}
+
+// closedStat returns stats of an unlocked pool with given principal
+func (ip *interestPool) closedStat(principal state.Balance,
+	now common.Timestamp) *poolStat {
+
+	var (
+		tl   = ip.lock()
+		last = ip.History[len(ip.History)-1]
+	)
+	return &poolStat{
+		ID:           ip.ID,
+		StartTime:    tl.StartTime,
+		Duartion:     tl.Duration,
+		APR:          ip.APR,
+		TokensEarned: ip.TokensEarned,
+		Balance:      principal,
+		Realised:     last.Realised,
+		Forfeited:    last.Forfeited,
+		Closed:       now,
+		History:      ip.History,
+	}
+}
@@ -0,0 +1,205 @@
+package interestpoolsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalNode_rate(t *testing.T) {
+	var gn = testGlobalNode(globalNode1Ok, 100, 0, 10, 0.1, time.Minute)
+	assert.Equal(t, 0.1, gn.rate(10, time.Hour))
+
+	gn.Tiers = []*rateTier{
+		{Name: "long", MinDuration: 720 * time.Hour, APR: 0.15},
+		{Name: "large", MinAmount: 1000, APR: 0.12},
+		{Name: "low", MinAmount: 10, APR: 0.05},
+	}
+	assert.Equal(t, 0.1, gn.rate(9, time.Hour))
+	assert.Equal(t, 0.05, gn.rate(10, time.Hour))
+	assert.Equal(t, 0.12, gn.rate(1000, time.Hour))
+	assert.Equal(t, 0.15, gn.rate(1000, 720*time.Hour))
+
+	assert.Error(t, validateRates([]*rateTier{{Name: "x", APR: -1}}, 0))
+	assert.Error(t, validateRates(nil, 1.1))
+	assert.NoError(t, validateRates(gn.Tiers, 0.5))
+}
+
+func TestEarlyUnlock(t *testing.T) {
+	var flags = lockFlags{
+		tokens:   1.0,
+		duration: 1 * time.Hour,
+	}
+	var _, userNode, globalNode, err = testLock(t, flags.tokens,
+		flags.duration, clientStartZCN, startMinted)
+	require.NoError(t, err)
+	globalNode.EarlyUnlockPenalty = 0.5
+
+	var f = formulae{sc: scYml, lockFlags: flags}
+	var earned = f.tokensEarned()
+
+	// rate changes don't affect existing locks
+	globalNode.APR = 0.5
+	var half = common.Timestamp(common.ToTime(startTime).
+		Add(flags.duration / 2).Unix())
+
+	var un *UserNode
+	var transfer *state.Transfer
+	un, transfer, err = testEarlyUnlock(t, userNode, globalNode, half)
+	require.NoError(t, err)
+	require.Len(t, un.Pools, 0)
+
+	var (
+		accrued   = earned / 2
+		forfeited = accrued / 2
+	)
+	assert.EqualValues(t, storageScId, transfer.ClientID)
+	assert.EqualValues(t, clientId, transfer.ToClientID)
+	assert.EqualValues(t, zcnToBalance(flags.tokens)-(earned-accrued)-
+		forfeited, transfer.Amount)
+
+	require.Len(t, un.Closed, 1)
+	var closed = un.Closed[0]
+	assert.Equal(t, scYml.apr, closed.APR)
+	assert.Equal(t, earned, closed.TokensEarned)
+	assert.Equal(t, accrued-forfeited, closed.Realised)
+	assert.Equal(t, forfeited, closed.Forfeited)
+	assert.Equal(t, half, closed.Closed)
+	require.Len(t, closed.History, 2)
+	assert.Equal(t, eventLock, closed.History[0].Event)
+	assert.Equal(t, eventEarlyUnlock, closed.History[1].Event)
+
+	// stats
+	var isc = &InterestPoolSmartContract{
+		SmartContract: &smartcontractinterface.SmartContract{ID: storageScId},
+	}
+	var ctx = &mockStateContext{store: map[datastore.Key]util.Serializable{
+		un.getKey(globalNode.ID): un,
+	}}
+	var resp interface{}
+	resp, err = isc.getPoolsStats(context.Background(),
+		url.Values{"client_id": {clientId}}, ctx)
+	require.NoError(t, err)
+	require.Len(t, resp.(*poolStats).Stats, 1)
+	assert.Equal(t, forfeited, resp.(*poolStats).Stats[0].Forfeited)
+
+	// not locked
+	_, userNode, globalNode, err = testLock(t, flags.tokens, flags.duration,
+		clientStartZCN, startMinted)
+	require.NoError(t, err)
+	var after = common.Timestamp(common.ToTime(startTime).
+		Add(flags.duration + 1).Unix())
+	_, _, err = testEarlyUnlock(t, userNode, globalNode, after)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pool is not locked, use unlock")
+}
+
+func TestInterestPoolSmartContract_updateVariables_rates(t *testing.T) {
+	var (
+		isc = &InterestPoolSmartContract{
+			SmartContract: &smartcontractinterface.SmartContract{ID: ADDRESS},
+		}
+		gn       = testGlobalNode(ADDRESS, 100, 0, 10, 0.1, time.Minute)
+		balances = testBalance(owner, 0)
+		update   = testGlobalNode(ADDRESS, 0, 0, 0, 0, 0)
+		err      error
+	)
+
+	update.EarlyUnlockPenalty = 2
+	update.APR = 0.5
+	_, err = isc.updateVariables(testTxn(owner, 0), gn, update.Encode(),
+		balances)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "early_unlock_penalty is not in [0; 1]")
+	assert.Equal(t, 0.1, gn.APR) // nothing applied
+	update.APR = 0
+
+	update.EarlyUnlockPenalty = 0.3
+	update.Tiers = []*rateTier{
+		{Name: "b", MinAmount: 100, APR: 0.2},
+		{Name: "a", MinDuration: time.Hour, APR: 0.3},
+	}
+	_, err = isc.updateVariables(testTxn(owner, 0), gn, update.Encode(),
+		balances)
+	require.NoError(t, err)
+	assert.Equal(t, 0.3, gn.EarlyUnlockPenalty)
+	require.Len(t, gn.Tiers, 2)
+	assert.Equal(t, "a", gn.Tiers[0].Name)
+	assert.Equal(t, 0.1, gn.APR) // not changed
+
+	// the penalty can be reset
+	_, err = isc.updateVariables(testTxn(owner, 0), gn,
+		[]byte(`{"simple_global_node":{"early_unlock_penalty":0}}`), balances)
+	require.NoError(t, err)
+	assert.Zero(t, gn.EarlyUnlockPenalty)
+	require.Len(t, gn.Tiers, 2) // kept
+}
+
+func TestValidateVariablesUpdate(t *testing.T) {
+	var validate = func(input string) error {
+		return ValidateVariablesUpdate([]byte(input), nil)
+	}
+	assert.NoError(t, validate(`{"min_lock_period":"1m",`+
+		`"simple_global_node":{"apr":0.2,"early_unlock_penalty":0}}`))
+	assert.Error(t, validate(`{"simple_global_node":{"arp":0.2}}`))
+	assert.Error(t, validate(`{"apr":0.2}`))
+	assert.Error(t, validate(`{"simple_global_node":{"apr":-0.2}}`))
+	assert.Error(t, validate(
+		`{"simple_global_node":{"early_unlock_penalty":1.5}}`))
+	assert.Error(t, validate(
+		`{"simple_global_node":{"tiers":[{"name":"x","apr":-1}]}}`))
+}
+
+func testEarlyUnlock(t *testing.T, userNode *UserNode, globalNode *GlobalNode,
+	now common.Timestamp) (*UserNode, *state.Transfer, error) {
+
+	input, err := json.Marshal(&poolStat{ID: txHash})
+	require.NoError(t, err)
+	var isc = &InterestPoolSmartContract{
+		SmartContract: &smartcontractinterface.SmartContract{
+			ID: storageScId,
+		},
+	}
+	var txn = &transaction.Transaction{
+		HashIDField:  datastore.HashIDField{Hash: txHash},
+		ClientID:     clientId,
+		ToClientID:   storageScId,
+		CreationDate: now,
+	}
+	var ctx = &mockStateContext{
+		ctx: *cstate.NewStateContext(
+			nil,
+			&util.MerklePatriciaTrie{},
+			&state.Deserializer{},
+			txn,
+			nil,
+			nil,
+			nil,
+			nil,
+		),
+		store: make(map[datastore.Key]util.Serializable),
+	}
+
+	if _, err = isc.earlyUnlock(txn, userNode, globalNode, input,
+		ctx); err != nil {
+		return nil, nil, err
+	}
+
+	var transfers = ctx.ctx.GetTransfers()
+	require.Len(t, transfers, 1)
+
+	return isc.getUserNode(userNode.ClientID, ctx), transfers[0], nil
+}
@@ -3,6 +3,8 @@ package interestpoolsc
 import (
 	"0chain.net/chaincore/smartcontract"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"time"
@@ -15,6 +17,7 @@ import (
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/util"
+	commonsc "0chain.net/smartcontract"
 
 	"github.com/rcrowley/go-metrics"
 )
@@ -65,6 +68,7 @@ func (ipsc *InterestPoolSmartContract) setSC(sc *smartcontractinterface.SmartCon
 	ipsc.SmartContract.RestHandlers["/getLockConfig"] = ipsc.getLockConfig
 	ipsc.SmartContractExecutionStats["lock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ipsc.ID, "lock"), nil)
 	ipsc.SmartContractExecutionStats["unlock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ipsc.ID, "unlock"), nil)
+	ipsc.SmartContractExecutionStats["early_unlock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ipsc.ID, "early_unlock"), nil)
 	ipsc.SmartContractExecutionStats["updateVariables"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ipsc.ID, "updateVariables"), nil)
 }
 
@@ -98,10 +102,12 @@ func (ip *InterestPoolSmartContract) lock(t *transaction.Transaction, un *UserNo
 	transfer, resp, err := pool.DigPool(t.Hash, t)
 	if err == nil {
 		balances.AddTransfer(transfer)
-		pool.APR = gn.APR
+		// the pool keeps the rate it opened with
+		pool.APR = gn.rate(transfer.Amount, npr.Duration)
 		pool.TokensEarned = state.Balance(
-			float64(transfer.Amount) * gn.APR * float64(npr.Duration) / float64(YEAR),
+			float64(transfer.Amount) * pool.APR * float64(npr.Duration) / float64(YEAR),
 		)
+		pool.addHistory(t.CreationDate, eventLock, 0, 0)
 		if err := balances.AddMint(&state.Mint{
 			Minter:     ip.ID,
 			ToClientID: transfer.ClientID,
@@ -139,6 +145,8 @@ func (ip *InterestPoolSmartContract) unlock(t *transaction.Transaction, un *User
 		if err != nil {
 			return "", common.NewError("failed to unlock tokens", fmt.Sprintf("error deleting pool from user node: %v", err.Error()))
 		}
+		pool.addHistory(t.CreationDate, eventUnlock, pool.TokensEarned, 0)
+		un.addClosed(pool.closedStat(transfer.Amount, t.CreationDate))
 		balances.AddTransfer(transfer)
 		balances.InsertTrieNode(un.getKey(gn.ID), un)
 		return response, nil
@@ -146,37 +154,161 @@ func (ip *InterestPoolSmartContract) unlock(t *transaction.Transaction, un *User
 	return "", common.NewError("failed to unlock tokens", fmt.Sprintf("pool (%v) doesn't exist", ps.ID))
 }
 
-func (ip *InterestPoolSmartContract) updateVariables(t *transaction.Transaction, gn *GlobalNode, inputData []byte, balances c_state.StateContextI) (string, error) {
-	if t.ClientID != owner {
-		return "", common.NewError("failed to update variables", "unauthorized access - only the owner can update the variables")
+// earlyUnlock unlocks a pool before its lock period ends. The interest is
+// minted on a lock for entire lock period, thus the pool returns its tokens
+// less interest not accrued yet and forfeited part of accrued interest.
+// Withheld tokens are kept by the SC forever.
+func (ip *InterestPoolSmartContract) earlyUnlock(t *transaction.Transaction, un *UserNode, gn *GlobalNode, inputData []byte, balances c_state.StateContextI) (string, error) {
+	ps := &poolStat{}
+	err := ps.decode(inputData)
+	if err != nil {
+		return "", common.NewError("failed to early unlock tokens",
+			fmt.Sprintf("input not formatted correctly: %v", err.Error()))
 	}
-	newGn := &GlobalNode{SimpleGlobalNode: &SimpleGlobalNode{}}
-	err := newGn.Decode(inputData)
+	pool, ok := un.Pools[ps.ID]
+	if !ok {
+		return "", common.NewError("failed to early unlock tokens", fmt.Sprintf("pool (%v) doesn't exist", ps.ID))
+	}
+	var now = common.ToTime(t.CreationDate)
+	if !pool.IsLocked(now) {
+		return "", common.NewError("failed to early unlock tokens", "pool is not locked, use unlock")
+	}
+	var (
+		principal = pool.Balance
+		accrued   = pool.accrued(now)
+		forfeited = state.Balance(float64(accrued) * gn.EarlyUnlockPenalty)
+		withheld  = pool.TokensEarned - accrued + forfeited
+	)
+	if withheld >= principal {
+		return "", common.NewError("failed to early unlock tokens", "no tokens left after withholding interest")
+	}
+	transfer, response, err := pool.ZcnPool.DrainPool(ip.ID, t.ClientID, principal-withheld, nil)
 	if err != nil {
-		return "", common.NewError("failed to update variables", "request not formatted correctly")
+		return "", common.NewError("failed to early unlock tokens", fmt.Sprintf("error draining pool %v", err.Error()))
+	}
+	if err = un.deletePool(pool.ID); err != nil {
+		return "", common.NewError("failed to early unlock tokens", fmt.Sprintf("error deleting pool from user node: %v", err.Error()))
 	}
+	pool.addHistory(t.CreationDate, eventEarlyUnlock, accrued-forfeited, forfeited)
+	un.addClosed(pool.closedStat(principal, t.CreationDate))
+	if err = balances.AddTransfer(transfer); err != nil {
+		return "", common.NewError("failed to early unlock tokens", err.Error())
+	}
+	balances.InsertTrieNode(un.getKey(gn.ID), un)
+	return response, nil
+}
+
+// variablesUpdate is updateVariables input; zero values are not applied,
+// excluding an early unlock penalty given explicitly
+type variablesUpdate struct {
+	*GlobalNode
+	hasPenalty bool
+}
+
+func (vu *variablesUpdate) decode(input []byte) (err error) {
+	vu.GlobalNode = &GlobalNode{SimpleGlobalNode: &SimpleGlobalNode{}}
+	if err = vu.GlobalNode.Decode(input); err != nil {
+		return
+	}
+	var raw struct {
+		SimpleGlobalNode map[string]json.RawMessage `json:"simple_global_node"`
+	}
+	if err = json.Unmarshal(input, &raw); err != nil {
+		return
+	}
+	_, vu.hasPenalty = raw.SimpleGlobalNode["early_unlock_penalty"]
+	return
+}
+
+// validate all the variables before applying any of them
+func (vu *variablesUpdate) validate() error {
+	switch {
+	case vu.APR < 0.0:
+		return errors.New("negative apr")
+	case vu.MinLockPeriod < 0:
+		return errors.New("negative min_lock_period")
+	case vu.MinLock < 0:
+		return errors.New("negative min_lock")
+	case vu.MaxMint < 0:
+		return errors.New("negative max_mint")
+	}
+	return validateRates(vu.Tiers, vu.EarlyUnlockPenalty)
+}
+
+// apply the validated update to given global node and the configurations
+func (vu *variablesUpdate) apply(gn *GlobalNode) {
 	const pfx = "smart_contracts.interestpoolsc."
 	var conf = config.SmartContractConfig
-	if newGn.APR > 0.0 {
-		gn.APR = newGn.APR
+	if vu.APR > 0.0 {
+		gn.APR = vu.APR
 		conf.Set(pfx+"interest_rate", gn.APR)
 	}
-	if newGn.MinLockPeriod > 0 {
-		gn.MinLockPeriod = newGn.MinLockPeriod
+	if vu.MinLockPeriod > 0 {
+		gn.MinLockPeriod = vu.MinLockPeriod
 		conf.Set(pfx+"min_lock_period", gn.MinLockPeriod)
 	}
-	if newGn.MinLock > 0 {
-		gn.MinLock = newGn.MinLock
+	if vu.MinLock > 0 {
+		gn.MinLock = vu.MinLock
 		conf.Set(pfx+"min_lock", gn.MinLock)
 	}
-	if newGn.MaxMint > 0 {
-		gn.MaxMint = newGn.MaxMint
+	if vu.MaxMint > 0 {
+		gn.MaxMint = vu.MaxMint
 		conf.Set(pfx+"max_mint", gn.MaxMint)
 	}
+	if vu.Tiers != nil {
+		sortTiers(vu.Tiers)
+		gn.Tiers = vu.Tiers
+	}
+	if vu.hasPenalty || vu.EarlyUnlockPenalty > 0.0 {
+		gn.EarlyUnlockPenalty = vu.EarlyUnlockPenalty
+		conf.Set(pfx+"early_unlock_penalty", gn.EarlyUnlockPenalty)
+	}
+}
+
+func (ip *InterestPoolSmartContract) updateVariables(t *transaction.Transaction, gn *GlobalNode, inputData []byte, balances c_state.StateContextI) (string, error) {
+	if !commonsc.IsConfigOwner(t.ClientID, owner) {
+		return "", common.NewError("failed to update variables", "unauthorized access - only the owner can update the variables")
+	}
+	var update variablesUpdate
+	if err := update.decode(inputData); err != nil {
+		return "", common.NewError("failed to update variables", "request not formatted correctly")
+	}
+	if err := update.validate(); err != nil {
+		return "", common.NewError("failed to update variables", err.Error())
+	}
+	update.apply(gn)
 	balances.InsertTrieNode(gn.getKey(), gn)
 	return string(gn.Encode()), nil
 }
 
+// ValidateVariablesUpdate validates given updateVariables input the same way
+// the updateVariables does, rejecting unknown fields.
+func ValidateVariablesUpdate(input []byte, _ c_state.StateContextI) (
+	err error) {
+
+	var raw map[string]json.RawMessage
+	if err = json.Unmarshal(input, &raw); err != nil {
+		return fmt.Errorf("decoding variables: %v", err)
+	}
+	for key, val := range raw {
+		switch key {
+		case "min_lock_period":
+		case "simple_global_node":
+			var sgn SimpleGlobalNode
+			if err = commonsc.DecodeConfigUpdate(val, &sgn); err != nil {
+				return fmt.Errorf("decoding variables: %v", err)
+			}
+		default:
+			return fmt.Errorf("decoding variables: unknown field %q", key)
+		}
+	}
+	var update variablesUpdate
+	if err = update.decode(input); err != nil {
+		return fmt.Errorf("decoding variables: %v", err)
+	}
+	return update.validate()
+}
+
 func (ip *InterestPoolSmartContract) getUserNode(id datastore.Key, balances c_state.StateContextI) *UserNode {
 	un := newUserNode(id)
 	userBytes, err := balances.GetTrieNode(un.getKey(ip.ID))
@@ -203,6 +335,8 @@ func (ip *InterestPoolSmartContract) getGlobalNode(balances c_state.StateContext
 	gn.APR = conf.GetFloat64(pfx + "apr")
 	gn.MinLock = state.Balance(conf.GetInt64(pfx + "min_lock"))
 	gn.MaxMint = state.Balance(conf.GetFloat64(pfx+"max_mint") * 1e10)
+	gn.EarlyUnlockPenalty = conf.GetFloat64(pfx + "early_unlock_penalty")
+	gn.Tiers = getConfiguredTiers(pfx + "tiers.")
 	if err == util.ErrValueNotPresent && funcName != "updateVariables" {
 		balances.InsertTrieNode(gn.getKey(), gn)
 	}
@@ -217,6 +351,8 @@ func (ip *InterestPoolSmartContract) Execute(t *transaction.Transaction, funcNam
 		return ip.lock(t, un, gn, inputData, balances)
 	case "unlock":
 		return ip.unlock(t, un, gn, inputData, balances)
+	case "early_unlock":
+		return ip.earlyUnlock(t, un, gn, inputData, balances)
 	case "updateVariables":
 		return ip.updateVariables(t, gn, inputData, balances)
 	default:
@@ -11,6 +11,11 @@ type SimpleGlobalNode struct {
 	TotalMinted state.Balance `json:"total_minted"`
 	MinLock     state.Balance `json:"min_lock"`
 	APR         float64       `json:"apr"`
+	// Tiers of interest rates by lock duration and amount.
+	Tiers []*rateTier `json:"tiers,omitempty"`
+	// EarlyUnlockPenalty is share of accrued interest forfeited on an early
+	// unlock.
+	EarlyUnlockPenalty float64 `json:"early_unlock_penalty,omitempty"`
 }
 
 func (sgn *SimpleGlobalNode) Encode() []byte {
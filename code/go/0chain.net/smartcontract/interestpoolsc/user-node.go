@@ -12,6 +12,8 @@ import (
 type UserNode struct {
 	ClientID datastore.Key                   `json:"client_id"`
 	Pools    map[datastore.Key]*interestPool `json:"pools"`
+	// Closed is stats of last unlocked pools, the latest last.
+	Closed []*poolStat `json:"closed,omitempty"`
 }
 
 func newUserNode(clientID datastore.Key) *UserNode {
@@ -29,10 +31,17 @@ func (un *UserNode) Encode() []byte {
 	poolsJson, _ := json.Marshal(un.Pools)
 	poolsRW := json.RawMessage(poolsJson)
 
-	buf, _ := json.Marshal(map[string]*json.RawMessage{
+	var rawMessage = map[string]*json.RawMessage{
 		"client_id": &cIdRW,
 		"pools":     &poolsRW,
-	})
+	}
+	// encoding closed pools
+	if len(un.Closed) > 0 {
+		closedJson, _ := json.Marshal(un.Closed)
+		closedRW := json.RawMessage(closedJson)
+		rawMessage["closed"] = &closedRW
+	}
+	buf, _ := json.Marshal(rawMessage)
 	return buf
 }
 
@@ -67,6 +76,13 @@ func (un *UserNode) Decode(input []byte) error {
 			un.addPool(tempPool)
 		}
 	}
+	c, ok := objMap["closed"]
+	if ok {
+		err = json.Unmarshal(*c, &un.Closed)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -106,3 +122,11 @@ func (un *UserNode) deletePool(poolID datastore.Key) error {
 	delete(un.Pools, poolID)
 	return nil
 }
+
+// addClosed adds stats of an unlocked pool, keeping last maxClosedPools
+func (un *UserNode) addClosed(ps *poolStat) {
+	un.Closed = append(un.Closed, ps)
+	if over := len(un.Closed) - maxClosedPools; over > 0 {
+		un.Closed = un.Closed[over:]
+	}
+}
@@ -0,0 +1,235 @@
+package minersc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	cstate "0chain.net/chaincore/chain/state"
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+)
+
+// maxCompoundHistory is number of latest compounding stats kept for a pool.
+const maxCompoundHistory = 50
+
+// CompoundStat is interests and rewards of a round restaked by an auto
+// compounding pool.
+type CompoundStat struct {
+	Round    int64         `json:"round"`
+	Restaked state.Balance `json:"restaked"`
+	PaidOut  state.Balance `json:"paid_out"` // overflow
+}
+
+// compoundHistory is the latest compounding stats of a delegate pool; it's
+// stored apart from the MinerNode to keep size of the node the same
+type compoundHistory struct {
+	Stats []*CompoundStat `json:"stats"`
+}
+
+func compoundHistoryKey(poolID datastore.Key) datastore.Key {
+	return globalKeyHash("compound_history:" + poolID)
+}
+
+func (ch *compoundHistory) Encode() []byte {
+	var b, err = json.Marshal(ch)
+	if err != nil {
+		panic(err) // must never happen
+	}
+	return b
+}
+
+func (ch *compoundHistory) Decode(b []byte) error {
+	return json.Unmarshal(b, ch)
+}
+
+// add restaked and paid out (overflow) values of a round
+func (ch *compoundHistory) add(round int64, restaked, paidOut state.Balance) {
+	if n := len(ch.Stats); n > 0 && ch.Stats[n-1].Round == round {
+		ch.Stats[n-1].Restaked += restaked
+		ch.Stats[n-1].PaidOut += paidOut
+		return
+	}
+	ch.Stats = append(ch.Stats, &CompoundStat{
+		Round:    round,
+		Restaked: restaked,
+		PaidOut:  paidOut,
+	})
+	if n := len(ch.Stats); n > maxCompoundHistory {
+		ch.Stats = ch.Stats[n-maxCompoundHistory:]
+	}
+}
+
+// nodePoolStat is a delegate pool with its compounding history
+type nodePoolStat struct {
+	*sci.DelegatePool
+	CompoundHistory []*CompoundStat `json:"compound_history,omitempty"`
+}
+
+func getCompoundHistory(poolID datastore.Key,
+	balances cstate.StateContextI) (ch *compoundHistory, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(compoundHistoryKey(poolID))
+	if err != nil && err != util.ErrValueNotPresent {
+		return
+	}
+	ch = new(compoundHistory)
+	if err == util.ErrValueNotPresent {
+		return ch, nil
+	}
+	if err = ch.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return
+}
+
+// addCompound adds a compounding of a round to history of given pool
+func addCompound(poolID datastore.Key, round int64,
+	restaked, paidOut state.Balance, balances cstate.StateContextI) (
+	err error) {
+
+	var ch *compoundHistory
+	if ch, err = getCompoundHistory(poolID, balances); err != nil {
+		return fmt.Errorf("getting compound history: %v", err)
+	}
+	ch.add(round, restaked, paidOut)
+	if _, err = balances.InsertTrieNode(compoundHistoryKey(poolID), ch); err != nil {
+		return fmt.Errorf("saving compound history: %v", err)
+	}
+	return
+}
+
+// deleteCompoundHistory of an emptied pool
+func deleteCompoundHistory(poolID datastore.Key,
+	balances cstate.StateContextI) (err error) {
+
+	_, err = balances.DeleteTrieNode(compoundHistoryKey(poolID))
+	if err == util.ErrValueNotPresent {
+		return nil
+	}
+	return
+}
+
+// restake given interests or rewards to an auto compounding pool up to max
+// stake of the node, the rest should be paid out; the number of delegates
+// never changes since the same pool used
+func (mn *MinerNode) restake(pool *sci.DelegatePool, value state.Balance) (
+	restaked, paidOut state.Balance) {
+
+	if !pool.AutoCompound {
+		return 0, value
+	}
+	restaked = value
+	if room := mn.MaxStake - pool.Balance; restaked > room {
+		restaked = room
+	}
+	if restaked < 0 {
+		restaked = 0
+	}
+	paidOut = value - restaked
+	pool.Balance += restaked
+	pool.Compounded += restaked
+	mn.TotalStaked += int64(restaked)
+	return
+}
+
+// mintDelegate mints given interests or rewards for a delegate pool,
+// restaking them if the pool is auto compounding
+func (msc *MinerSmartContract) mintDelegate(mn *MinerNode,
+	pool *sci.DelegatePool, value state.Balance, gn *GlobalNode,
+	balances cstate.StateContextI) (resp string, err error) {
+
+	var restaked, paidOut = mn.restake(pool, value)
+	if pool.AutoCompound {
+		err = addCompound(pool.ID, balances.GetBlock().Round, restaked,
+			paidOut, balances)
+		if err != nil {
+			return
+		}
+	}
+	if restaked > 0 {
+		var mint = state.NewMint(ADDRESS, ADDRESS, restaked)
+		if err = balances.AddMint(mint); err != nil {
+			return "", fmt.Errorf("adding mint: %v", err)
+		}
+		msc.addMint(gn, mint.Amount)
+		resp += string(mint.Encode())
+	}
+	if paidOut > 0 {
+		var mint = state.NewMint(ADDRESS, pool.DelegateID, paidOut)
+		if err = balances.AddMint(mint); err != nil {
+			return "", fmt.Errorf("adding mint: %v", err)
+		}
+		msc.addMint(gn, mint.Amount)
+		resp += string(mint.Encode())
+	}
+	return
+}
+
+// payDelegate pays given fees to a delegate pool, restaking them if the pool
+// is auto compounding; the fees are already owned by the SC
+func (msc *MinerSmartContract) payDelegate(mn *MinerNode,
+	pool *sci.DelegatePool, value state.Balance,
+	balances cstate.StateContextI) (resp string, err error) {
+
+	var restaked, paidOut = mn.restake(pool, value)
+	if pool.AutoCompound {
+		err = addCompound(pool.ID, balances.GetBlock().Round, restaked,
+			paidOut, balances)
+		if err != nil {
+			return
+		}
+	}
+	if paidOut == 0 {
+		return
+	}
+	var transfer = state.NewTransfer(ADDRESS, pool.DelegateID, paidOut)
+	if err = balances.AddTransfer(transfer); err != nil {
+		return "", fmt.Errorf("adding transfer: %v", err)
+	}
+	return string(transfer.Encode()), nil
+}
+
+// setAutoCompound turns on or off auto compounding of a delegate pool
+func (msc *MinerSmartContract) setAutoCompound(t *transaction.Transaction,
+	inputData []byte, _ *GlobalNode, balances cstate.StateContextI) (
+	resp string, err error) {
+
+	var dp deletePool
+	if err = dp.Decode(inputData); err != nil {
+		return "", common.NewErrorf("set_auto_compound",
+			"decoding request: %v", err)
+	}
+
+	var mn *MinerNode
+	if mn, err = getMinerNode(dp.MinerID, balances); err != nil {
+		return "", common.NewErrorf("set_auto_compound",
+			"getting miner node: %v", err)
+	}
+
+	var pool, ok = mn.Pending[dp.PoolID]
+	if !ok {
+		if pool, ok = mn.Active[dp.PoolID]; !ok {
+			return "", common.NewError("set_auto_compound",
+				"no such delegate pool")
+		}
+	}
+
+	if pool.DelegateID != t.ClientID {
+		return "", common.NewErrorf("set_auto_compound",
+			"you (%v) do not own the pool, it belongs to %v",
+			t.ClientID, pool.DelegateID)
+	}
+
+	pool.AutoCompound = dp.AutoCompound
+	if err = mn.save(balances); err != nil {
+		return "", common.NewErrorf("set_auto_compound",
+			"saving miner node: %v", err)
+	}
+
+	return string(pool.Encode()), nil
+}
@@ -0,0 +1,141 @@
+package minersc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"0chain.net/chaincore/block"
+	sci "0chain.net/chaincore/smartcontractinterface"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinerSmartContract_autoCompound(t *testing.T) {
+	var (
+		balances = newTestBalances()
+		msc      = newTestMinerSC()
+		now      = int64(10000)
+		err      error
+	)
+
+	var gn = setConfig(t, balances)
+
+	var (
+		mn               = newMiner(t, msc, now, 2, 100e10, balances)
+		compound, payout = mn.stakers[0], mn.stakers[1]
+	)
+	_, err = compound.callAddToDelegatePool(t, msc, now, 10e10, mn.miner.id,
+		balances)
+	require.NoError(t, err)
+	_, err = payout.callAddToDelegatePool(t, msc, now, 10e10, mn.miner.id,
+		balances)
+	require.NoError(t, err)
+
+	var node *MinerNode
+	node, err = getMinerNode(mn.miner.id, balances)
+	require.NoError(t, err)
+
+	var poolOf = func(node *MinerNode, c *Client) (pool *sci.DelegatePool) {
+		for _, dp := range node.Pending {
+			if dp.DelegateID == c.id {
+				return dp
+			}
+		}
+		for _, dp := range node.Active {
+			if dp.DelegateID == c.id {
+				return dp
+			}
+		}
+		t.Fatal("missing delegate pool")
+		return
+	}
+
+	var setAutoCompound = func(c *Client, on bool) (err error) {
+		var tx = newTransaction(c.id, ADDRESS, 0, now)
+		balances.txn = tx
+		_, err = msc.setAutoCompound(tx, mustEncode(t, &deletePool{
+			MinerID:      mn.miner.id,
+			PoolID:       poolOf(node, compound).ID,
+			AutoCompound: on,
+		}), gn, balances)
+		return
+	}
+
+	err = setAutoCompound(payout, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "do not own the pool")
+	require.NoError(t, setAutoCompound(compound, true))
+
+	node, err = getMinerNode(mn.miner.id, balances)
+	require.NoError(t, err)
+	msc.activatePending(node)
+	require.NoError(t, node.save(balances))
+	assert.True(t, poolOf(node, compound).AutoCompound)
+	assert.False(t, poolOf(node, payout).AutoCompound)
+
+	balances.block = block.Provider().(*block.Block)
+	balances.block.Round = 100
+	balances.txn = newTransaction(mn.miner.id, ADDRESS, 0, now)
+
+	// fees, half for every pool
+	_, err = msc.payStakeHolders(2e10, node, false, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 11e10, poolOf(node, compound).Balance)
+	assert.EqualValues(t, 10e10, poolOf(node, payout).Balance)
+	assert.EqualValues(t, 90e10, balances.balances[compound.id])
+	assert.EqualValues(t, 91e10, balances.balances[payout.id])
+	assert.EqualValues(t, 21e10, node.TotalStaked)
+
+	// interests, overflow of the max stake is paid out
+	balances.block.Round = 101
+	poolOf(node, compound).Balance = node.MaxStake - 1e10
+	_, err = msc.mintDelegate(node, poolOf(node, compound), 3e10, gn,
+		balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, node.MaxStake, poolOf(node, compound).Balance)
+	assert.EqualValues(t, 92e10, balances.balances[compound.id])
+	require.NoError(t, node.save(balances))
+
+	var resp interface{}
+	resp, err = msc.nodePoolStatHandler(context.Background(), url.Values{
+		"id":      []string{mn.miner.id},
+		"pool_id": []string{poolOf(node, compound).ID},
+	}, balances)
+	require.NoError(t, err)
+
+	var dp = resp.(*nodePoolStat)
+	assert.EqualValues(t, 2e10, dp.Compounded)
+	require.Len(t, dp.CompoundHistory, 2)
+	assert.Equal(t, CompoundStat{Round: 100, Restaked: 1e10},
+		*dp.CompoundHistory[0])
+	assert.Equal(t, CompoundStat{Round: 101, Restaked: 1e10,
+		PaidOut: 2e10}, *dp.CompoundHistory[1])
+
+	// the history isn't a part of the miner node
+	assert.NotContains(t, string(node.Encode()), "compound_history")
+
+	// and it's deleted with the pool
+	_, err = msc.emptyPool(node, poolOf(node, compound), 102, gn, balances)
+	require.NoError(t, err)
+	var ch *compoundHistory
+	ch, err = getCompoundHistory(poolOf(node, compound).ID, balances)
+	require.NoError(t, err)
+	assert.Len(t, ch.Stats, 0)
+}
+
+func TestCompoundHistory_add(t *testing.T) {
+	var ch compoundHistory
+	ch.add(1, 2, 0)
+	ch.add(1, 1, 3)
+	assert.Equal(t, []*CompoundStat{
+		{Round: 1, Restaked: 3, PaidOut: 3},
+	}, ch.Stats)
+
+	for i := int64(2); i < maxCompoundHistory+10; i++ {
+		ch.add(i, 1, 0)
+	}
+	assert.Len(t, ch.Stats, maxCompoundHistory)
+	assert.Equal(t, int64(10), ch.Stats[0].Round)
+}
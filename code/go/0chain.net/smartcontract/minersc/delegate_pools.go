@@ -1,6 +1,8 @@
 package minersc
 
 import (
+	"fmt"
+
 	cstate "0chain.net/chaincore/chain/state"
 	sci "0chain.net/chaincore/smartcontractinterface"
 	"0chain.net/chaincore/state"
@@ -70,6 +72,7 @@ func (msc *MinerSmartContract) addToDelegatePool(t *transaction.Transaction,
 	}
 	pool.DelegateID = t.ClientID
 	pool.Status = PENDING
+	pool.AutoCompound = dp.AutoCompound
 
 	Logger.Info("add delegate pool", zap.Any("pool", pool))
 
@@ -177,6 +180,12 @@ func (msc *MinerSmartContract) deleteFromDelegatePool(
 			t.ClientID, pool.DelegateID)
 	}
 
+	if un.LockedUntil > 0 && un.LockedUntil >= balances.GetBlock().Round {
+		return "", common.NewErrorf("delegate_pool_del",
+			"stake is locked by a governance vote until round %d",
+			un.LockedUntil)
+	}
+
 	pool.Status = DELETING // mark as deleting
 	pool.TokenLockInterface = &ViewChangeLock{
 		Owner:               t.ClientID,
@@ -190,5 +199,9 @@ func (msc *MinerSmartContract) deleteFromDelegatePool(
 			"saving miner node: %v", err)
 	}
 
+	if gn.UnbondingPeriod > 0 {
+		return fmt.Sprintf(`{"action": "pool will be unbonding next VC `+
+			`for %d rounds"}`, gn.UnbondingPeriod), nil
+	}
 	return `{"action": "pool will be released next VC"}`, nil
 }
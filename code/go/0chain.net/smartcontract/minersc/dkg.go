@@ -474,6 +474,11 @@ func (msc *MinerSmartContract) createMagicBlockForWait(
 		}
 	}
 
+	if err = excludeIneligibleNodes(dkgMinersList, sharders, balances); err != nil {
+		return common.NewError("create_magic_block_failed",
+			"excluding ineligible nodes: "+err.Error())
+	}
+
 	if err = dkgMinersList.reduceNodes(true, gn, balances); err != nil {
 		Logger.Error("create magic block for wait", zap.Error(err))
 		return err
@@ -0,0 +1,356 @@
+package minersc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+
+	. "0chain.net/core/logging"
+	"go.uber.org/zap"
+)
+
+// IneligibleNodesKey is key of list of nodes excluded from the next magic
+// block for an equivocation.
+var IneligibleNodesKey = globalKeyHash("ineligible_nodes")
+
+// BurnAddress is address slashed tokens are burned to; there is no key of
+// the address, thus the tokens can't be spent.
+const BurnAddress = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// burn moves given tokens of the SC to the burn address
+func burn(value state.Balance, gn *GlobalNode,
+	balances cstate.StateContextI) (err error) {
+
+	if value == 0 {
+		return
+	}
+	err = balances.AddTransfer(state.NewTransfer(ADDRESS, BurnAddress, value))
+	if err != nil {
+		return fmt.Errorf("burning tokens: %v", err)
+	}
+	gn.Burned += value
+	return
+}
+
+// equivocationHeader is a block header signed by an offender, the signature
+// is signature of a block generator or a verification ticket signature of a
+// verifier; both sign the block hash
+type equivocationHeader struct {
+	util.BlockHeader
+	Signature string `json:"signature"`
+}
+
+// hash of the block computed from the header, the Hash of the header is
+// not trusted
+func (eh *equivocationHeader) hash() string {
+	return eh.ComputeHash()
+}
+
+// equivocationReport is proof of two conflicting blocks of the same
+// generator for the same round signed by the offender; if the offender is
+// the generator, then it's two different blocks, otherwise it's two
+// conflicting verification tickets
+type equivocationReport struct {
+	NodeID datastore.Key       `json:"node_id"`
+	First  *equivocationHeader `json:"first"`
+	Second *equivocationHeader `json:"second"`
+}
+
+func (er *equivocationReport) decode(b []byte) error {
+	return json.Unmarshal(b, er)
+}
+
+func (er *equivocationReport) validate() error {
+	if er.NodeID == "" {
+		return errors.New("missing node_id")
+	}
+	if er.First == nil || er.Second == nil {
+		return errors.New("missing block header")
+	}
+	if er.First.Round != er.Second.Round {
+		return errors.New("different rounds")
+	}
+	if er.First.MinerID != er.Second.MinerID {
+		return errors.New("different block generators")
+	}
+	if er.First.hash() == er.Second.hash() {
+		return errors.New("the same block")
+	}
+	return nil
+}
+
+// verify signatures of the headers by given public key
+func (er *equivocationReport) verify(
+	balances cstate.StateContextI, publicKey string) (err error) {
+
+	var scheme = balances.GetSignatureScheme()
+	if err = scheme.SetPublicKey(publicKey); err != nil {
+		return fmt.Errorf("setting node public key: %v", err)
+	}
+	for _, eh := range []*equivocationHeader{er.First, er.Second} {
+		var ok bool
+		if ok, err = scheme.Verify(eh.Signature, eh.hash()); err != nil {
+			return fmt.Errorf("verifying signature: %v", err)
+		}
+		if !ok {
+			return errors.New("invalid signature")
+		}
+	}
+	return
+}
+
+// equivocation is record of a reported equivocation, it prevents double
+// reports of the same round
+type equivocation struct {
+	NodeID     datastore.Key `json:"node_id"`
+	Round      int64         `json:"round"`
+	ReporterID datastore.Key `json:"reporter_id"`
+	Slashed    state.Balance `json:"slashed"`
+	Reward     state.Balance `json:"reward"`
+}
+
+func equivocationKey(nodeID datastore.Key, round int64) datastore.Key {
+	return globalKeyHash("equivocation:" + nodeID + ":" +
+		strconv.FormatInt(round, 10))
+}
+
+func (eq *equivocation) Encode() []byte {
+	var b, err = json.Marshal(eq)
+	if err != nil {
+		panic(err) // must never happen
+	}
+	return b
+}
+
+func (eq *equivocation) Decode(b []byte) error {
+	return json.Unmarshal(b, eq)
+}
+
+func isEquivocationReported(nodeID datastore.Key, round int64,
+	balances cstate.StateContextI) (ok bool, err error) {
+
+	_, err = balances.GetTrieNode(equivocationKey(nodeID, round))
+	if err == util.ErrValueNotPresent {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ineligibleNodes is set of nodes excluded from the next magic block,
+// node ID -> round of the equivocation
+type ineligibleNodes struct {
+	Nodes map[datastore.Key]int64 `json:"nodes"`
+}
+
+func (in *ineligibleNodes) Encode() []byte {
+	var b, err = json.Marshal(in)
+	if err != nil {
+		panic(err) // must never happen
+	}
+	return b
+}
+
+func (in *ineligibleNodes) Decode(b []byte) error {
+	return json.Unmarshal(b, in)
+}
+
+func (in *ineligibleNodes) save(balances cstate.StateContextI) (err error) {
+	_, err = balances.InsertTrieNode(IneligibleNodesKey, in)
+	return
+}
+
+func getIneligibleNodes(balances cstate.StateContextI) (
+	in *ineligibleNodes, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(IneligibleNodesKey)
+	if err != nil && err != util.ErrValueNotPresent {
+		return
+	}
+	in = &ineligibleNodes{Nodes: make(map[datastore.Key]int64)}
+	if err == util.ErrValueNotPresent {
+		return in, nil
+	}
+	if err = in.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	if in.Nodes == nil {
+		in.Nodes = make(map[datastore.Key]int64)
+	}
+	return
+}
+
+// slash given ratio of every active delegate pool of the node, the reporter
+// gets its share, rest of slashed tokens are burned (sent to the burn
+// address); not matured unbonding tokens of the node are slashed too
+func (msc *MinerSmartContract) slash(mn *MinerNode, reporterID string,
+	gn *GlobalNode, balances cstate.StateContextI) (
+	slashed, reward state.Balance, err error) {
+
+	var burned state.Balance
+	for _, pool := range mn.orderedActivePools() {
+		var (
+			value = state.Balance(float64(pool.Balance) * gn.EquivocationSlash)
+			share = state.Balance(float64(value) * gn.EquivocationReporterShare)
+		)
+		if value == 0 {
+			continue
+		}
+		if share > 0 {
+			var transfer *state.Transfer
+			transfer, _, err = pool.DrainPool(ADDRESS, reporterID, share, nil)
+			if err != nil {
+				return 0, 0, fmt.Errorf("draining delegate pool: %v", err)
+			}
+			if err = balances.AddTransfer(transfer); err != nil {
+				return 0, 0, fmt.Errorf("adding transfer: %v", err)
+			}
+		}
+		pool.Balance -= value - share
+		mn.TotalStaked -= int64(value)
+		burned += value - share
+		slashed += value
+		reward += share
+	}
+	if err = burn(burned, gn, balances); err != nil {
+		return 0, 0, err
+	}
+
+	var unbonding state.Balance
+	unbonding, err = msc.slashUnbonding(mn, gn, balances.GetBlock().Round,
+		balances)
+	if err != nil {
+		return 0, 0, fmt.Errorf("slashing unbonding tokens: %v", err)
+	}
+	slashed += unbonding
+	mn.Stat.Slashed += slashed
+	return
+}
+
+// reportEquivocation slashes delegate pools of a miner signed two different
+// blocks or verification tickets of two different blocks for the same round
+// and excludes the miner from the next magic block
+func (msc *MinerSmartContract) reportEquivocation(t *transaction.Transaction,
+	input []byte, gn *GlobalNode, balances cstate.StateContextI) (
+	resp string, err error) {
+
+	var er equivocationReport
+	if err = er.decode(input); err != nil {
+		return "", common.NewError("report_equivocation_failed",
+			"malformed request: "+err.Error())
+	}
+	if err = er.validate(); err != nil {
+		return "", common.NewError("report_equivocation_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var age = balances.GetBlock().Round - er.First.Round
+	if gn.EquivocationMaxAge > 0 && age > gn.EquivocationMaxAge {
+		return "", common.NewErrorf("report_equivocation_failed",
+			"equivocation is too old: %d rounds passed, max %d", age,
+			gn.EquivocationMaxAge)
+	}
+
+	var mn *MinerNode
+	if mn, err = getMinerNode(er.NodeID, balances); err != nil {
+		return "", common.NewError("report_equivocation_failed",
+			"can't get miner node: "+err.Error())
+	}
+
+	if err = er.verify(balances, mn.PublicKey); err != nil {
+		return "", common.NewError("report_equivocation_failed",
+			"invalid proof: "+err.Error())
+	}
+
+	var reported bool
+	reported, err = isEquivocationReported(mn.ID, er.First.Round, balances)
+	if err != nil {
+		return "", common.NewError("report_equivocation_failed",
+			"checking reported equivocations: "+err.Error())
+	}
+	if reported {
+		return "", common.NewError("report_equivocation_failed",
+			"equivocation already reported")
+	}
+
+	var eq = &equivocation{
+		NodeID:     mn.ID,
+		Round:      er.First.Round,
+		ReporterID: t.ClientID,
+	}
+	eq.Slashed, eq.Reward, err = msc.slash(mn, t.ClientID, gn, balances)
+	if err != nil {
+		return "", common.NewError("report_equivocation_failed",
+			"slashing: "+err.Error())
+	}
+
+	if err = mn.save(balances); err != nil {
+		return "", common.NewError("report_equivocation_failed", err.Error())
+	}
+	if err = gn.save(balances); err != nil {
+		return "", common.NewError("report_equivocation_failed", err.Error())
+	}
+	_, err = balances.InsertTrieNode(equivocationKey(eq.NodeID, eq.Round), eq)
+	if err != nil {
+		return "", common.NewError("report_equivocation_failed",
+			"saving equivocation: "+err.Error())
+	}
+
+	var in *ineligibleNodes
+	if in, err = getIneligibleNodes(balances); err != nil {
+		return "", common.NewError("report_equivocation_failed",
+			"getting ineligible nodes: "+err.Error())
+	}
+	in.Nodes[mn.ID] = eq.Round
+	if err = in.save(balances); err != nil {
+		return "", common.NewError("report_equivocation_failed",
+			"saving ineligible nodes: "+err.Error())
+	}
+
+	Logger.Info("report_equivocation: node slashed",
+		zap.String("node_id", mn.ID),
+		zap.Int64("round", eq.Round),
+		zap.String("reporter_id", eq.ReporterID),
+		zap.Int64("slashed", int64(eq.Slashed)),
+		zap.Int64("reward", int64(eq.Reward)))
+
+	return string(eq.Encode()), nil
+}
+
+// excludeIneligibleNodes removes nodes reported for an equivocation from
+// the next magic block nodes and resets the ineligible nodes list, thus the
+// nodes are excluded from one view change only
+func excludeIneligibleNodes(dkgMiners *DKGMinerNodes, sharders *MinerNodes,
+	balances cstate.StateContextI) (err error) {
+
+	var in *ineligibleNodes
+	if in, err = getIneligibleNodes(balances); err != nil {
+		return
+	}
+	if len(in.Nodes) == 0 {
+		return
+	}
+
+	for id := range in.Nodes {
+		delete(dkgMiners.SimpleNodes, id)
+	}
+
+	var eligible = make([]*MinerNode, 0, len(sharders.Nodes))
+	for _, sn := range sharders.Nodes {
+		if _, ok := in.Nodes[sn.ID]; !ok {
+			eligible = append(eligible, sn)
+		}
+	}
+	sharders.Nodes = eligible
+
+	in.Nodes = make(map[datastore.Key]int64)
+	return in.save(balances)
+}
@@ -0,0 +1,175 @@
+package minersc
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinerSmartContract_reportEquivocation(t *testing.T) {
+	var (
+		balances = newTestBalances()
+		msc      = newTestMinerSC()
+		now      = int64(10000)
+		reporter = newClient(0, balances)
+		err      error
+	)
+
+	var gn = setConfig(t, balances)
+	gn.EquivocationSlash = 0.1
+	gn.EquivocationReporterShare = 0.5
+	gn.EquivocationMaxAge = 150
+	mustSave(t, GlobalNodeKey, gn, balances)
+
+	var mn = newMiner(t, msc, now, 2, 100e10, balances)
+	for _, staker := range mn.stakers {
+		_, err = staker.callAddToDelegatePool(t, msc, now, 10e10,
+			mn.miner.id, balances)
+		require.NoError(t, err)
+	}
+
+	var node *MinerNode
+	node, err = getMinerNode(mn.miner.id, balances)
+	require.NoError(t, err)
+	msc.activatePending(node)
+	require.NoError(t, node.save(balances))
+
+	var roundHeader = func(signer *Client, round int64,
+		hash string) *equivocationHeader {
+
+		var eh = &equivocationHeader{BlockHeader: util.BlockHeader{
+			MinerID:         mn.miner.id,
+			PrevHash:        "prev_hash",
+			CreationDate:    common.Timestamp(now),
+			Round:           round,
+			RoundRandomSeed: 150,
+			MerkleTreeRoot:  hash,
+		}}
+		eh.Signature, err = signer.scheme.Sign(eh.hash())
+		require.NoError(t, err)
+		return eh
+	}
+
+	var header = func(signer *Client, hash string) *equivocationHeader {
+		return roundHeader(signer, 100, hash)
+	}
+
+	balances.block = block.Provider().(*block.Block)
+	balances.block.Round = 200
+
+	var report = func(er *equivocationReport) (err error) {
+		var tx = newTransaction(reporter.id, ADDRESS, 0, now)
+		balances.txn = tx
+		_, err = msc.reportEquivocation(tx, mustEncode(t, er), gn, balances)
+		return
+	}
+
+	var first, second = header(mn.miner, "root_1"), header(mn.miner, "root_2")
+
+	// the hash of a block is the same the block.ComputeHash computes
+	var bh = first.BlockHeader
+	bh.Hash = "not trusted"
+	assert.Equal(t, encryption.Hash(bh.HashData()), first.hash())
+
+	var requireErrMsg = func(err error, msg string) {
+		t.Helper()
+		require.Error(t, err)
+		assert.Equal(t, msg, err.Error())
+	}
+
+	requireErrMsg(report(&equivocationReport{
+		NodeID: mn.miner.id,
+		First:  first,
+		Second: first,
+	}), "report_equivocation_failed: invalid request: the same block")
+
+	var other = newClient(0, balances)
+	requireErrMsg(report(&equivocationReport{
+		NodeID: mn.miner.id,
+		First:  first,
+		Second: header(other, "root_2"),
+	}), "report_equivocation_failed: invalid proof: invalid signature")
+
+	// an honest verifier signs verification tickets of blocks of the
+	// generator for different rounds
+	var vr = newMiner(t, msc, now, 2, 100e10, balances)
+	for _, staker := range vr.stakers {
+		_, err = staker.callAddToDelegatePool(t, msc, now, 10e10,
+			vr.miner.id, balances)
+		require.NoError(t, err)
+	}
+	var verifier *MinerNode
+	verifier, err = getMinerNode(vr.miner.id, balances)
+	require.NoError(t, err)
+	msc.activatePending(verifier)
+	require.NoError(t, verifier.save(balances))
+
+	requireErrMsg(report(&equivocationReport{
+		NodeID: vr.miner.id,
+		First:  roundHeader(vr.miner, 100, "root_1"),
+		Second: roundHeader(vr.miner, 101, "root_2"),
+	}), "report_equivocation_failed: invalid request: different rounds")
+
+	// too old evidence
+	requireErrMsg(report(&equivocationReport{
+		NodeID: vr.miner.id,
+		First:  roundHeader(vr.miner, 40, "root_1"),
+		Second: roundHeader(vr.miner, 40, "root_2"),
+	}), "report_equivocation_failed: equivocation is too old: "+
+		"160 rounds passed, max 150")
+
+	verifier, err = getMinerNode(vr.miner.id, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 20e10, verifier.TotalStaked)
+	assert.Zero(t, verifier.Stat.Slashed)
+	assert.Zero(t, balances.balances[reporter.id])
+
+	require.NoError(t, report(&equivocationReport{
+		NodeID: mn.miner.id,
+		First:  first,
+		Second: second,
+	}))
+
+	// 10% of 2 x 10 tokens slashed, half of them goes to the reporter,
+	// and rest of them are burned
+	assert.EqualValues(t, 1e10, balances.balances[reporter.id])
+	assert.EqualValues(t, 1e10, balances.balances[BurnAddress])
+	gn, err = getGlobalNode(balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1e10, gn.Burned)
+
+	node, err = getMinerNode(mn.miner.id, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 18e10, node.TotalStaked)
+	assert.EqualValues(t, 2e10, node.Stat.Slashed)
+	for _, pool := range node.orderedActivePools() {
+		assert.EqualValues(t, 9e10, pool.Balance)
+	}
+
+	requireErrMsg(report(&equivocationReport{
+		NodeID: mn.miner.id,
+		First:  first,
+		Second: second,
+	}), "report_equivocation_failed: equivocation already reported")
+
+	// excluded from the next magic block once
+	var in *ineligibleNodes
+	in, err = getIneligibleNodes(balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, in.Nodes[mn.miner.id])
+
+	var dkg = NewDKGMinerNodes()
+	dkg.SimpleNodes[mn.miner.id] = node.SimpleNode
+	require.NoError(t, excludeIneligibleNodes(dkg, new(MinerNodes), balances))
+	assert.Len(t, dkg.SimpleNodes, 0)
+
+	in, err = getIneligibleNodes(balances)
+	require.NoError(t, err)
+	assert.Len(t, in.Nodes, 0)
+}
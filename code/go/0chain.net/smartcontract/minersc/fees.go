@@ -41,13 +41,11 @@ func (msc *MinerSmartContract) payInterests(mn *MinerNode, gn *GlobalNode,
 		if amount == 0 {
 			continue
 		}
-		var mint = state.NewMint(ADDRESS, pool.DelegateID, amount)
-		if err = balances.AddMint(mint); err != nil {
+		if _, err = msc.mintDelegate(mn, pool, amount, gn, balances); err != nil {
 			return common.NewErrorf("pay_fees/pay_interests",
 				"error adding mint for stake %v-%v: %v", mn.ID, pool.ID, err)
 		}
-		msc.addMint(gn, mint.Amount) //
-		pool.AddInterests(amount)    // stat
+		pool.AddInterests(amount) // stat
 	}
 
 	return
@@ -90,11 +88,26 @@ func (msc *MinerSmartContract) deletePoolFromUserNode(delegateID, nodeID,
 }
 
 func (msc *MinerSmartContract) emptyPool(mn *MinerNode,
-	pool *sci.DelegatePool, _ int64, balances cstate.StateContextI) (
-	resp string, err error) {
+	pool *sci.DelegatePool, round int64, gn *GlobalNode,
+	balances cstate.StateContextI) (resp string, err error) {
 
 	mn.TotalStaked -= int64(pool.Balance)
 
+	if err = deleteCompoundHistory(pool.ID, balances); err != nil {
+		return "", fmt.Errorf("deleting compound history: %v", err)
+	}
+
+	// staked tokens wait the unbonding period (pending never staked)
+	if pool.Status != PENDING && gn.UnbondingPeriod > 0 && pool.Balance > 0 {
+		err = msc.unbond(mn, pool, round+gn.UnbondingPeriod, balances)
+		if err != nil {
+			return "", fmt.Errorf("unbonding delegate pool: %v", err)
+		}
+		err = msc.deletePoolFromUserNode(pool.DelegateID, mn.ID, pool.ID,
+			balances)
+		return
+	}
+
 	// transfer, empty
 	var transfer *state.Transfer
 	transfer, resp, err = pool.EmptyPool(ADDRESS, pool.DelegateID, nil)
@@ -111,11 +124,11 @@ func (msc *MinerSmartContract) emptyPool(mn *MinerNode,
 
 // unlock deleted pools
 func (msc *MinerSmartContract) unlockDeleted(mn *MinerNode, round int64,
-	balances cstate.StateContextI) (err error) {
+	gn *GlobalNode, balances cstate.StateContextI) (err error) {
 
 	for id := range mn.Deleting {
 		var pool = mn.Active[id]
-		if _, err = msc.emptyPool(mn, pool, round, balances); err != nil {
+		if _, err = msc.emptyPool(mn, pool, round, gn, balances); err != nil {
 			return common.NewError("pay_fees/unlock_deleted", err.Error())
 		}
 		delete(mn.Active, id)
@@ -126,14 +139,14 @@ func (msc *MinerSmartContract) unlockDeleted(mn *MinerNode, round int64,
 }
 
 // unlock all delegate pools of offline node
-func (msc *MinerSmartContract) unlockOffline(mn *MinerNode,
-	balances cstate.StateContextI) (err error) {
+func (msc *MinerSmartContract) unlockOffline(mn *MinerNode, round int64,
+	gn *GlobalNode, balances cstate.StateContextI) (err error) {
 
 	mn.Deleting = make(map[string]*sci.DelegatePool) // reset
 
 	// unlock all pending
 	for id, pool := range mn.Pending {
-		if _, err = msc.emptyPool(mn, pool, 0, balances); err != nil {
+		if _, err = msc.emptyPool(mn, pool, round, gn, balances); err != nil {
 			return common.NewError("pay_fees/unlock_offline", err.Error())
 		}
 		delete(mn.Pending, id)
@@ -141,7 +154,7 @@ func (msc *MinerSmartContract) unlockOffline(mn *MinerNode,
 
 	// unlock all active
 	for id, pool := range mn.Active {
-		if _, err = msc.emptyPool(mn, pool, 0, balances); err != nil {
+		if _, err = msc.emptyPool(mn, pool, round, gn, balances); err != nil {
 			return common.NewError("pay_fees/unlock_offline", err.Error())
 		}
 		delete(mn.Active, id)
@@ -190,7 +203,7 @@ func (msc *MinerSmartContract) viewChangePoolsWork(gn *GlobalNode,
 		if err = msc.payInterests(mn, gn, balances); err != nil {
 			return
 		}
-		if err = msc.unlockDeleted(mn, round, balances); err != nil {
+		if err = msc.unlockDeleted(mn, round, gn, balances); err != nil {
 			return
 		}
 		msc.activatePending(mn)
@@ -212,7 +225,7 @@ func (msc *MinerSmartContract) viewChangePoolsWork(gn *GlobalNode,
 		if err = msc.payInterests(mn, gn, balances); err != nil {
 			return
 		}
-		if err = msc.unlockDeleted(mn, round, balances); err != nil {
+		if err = msc.unlockDeleted(mn, round, gn, balances); err != nil {
 			return
 		}
 		msc.activatePending(mn)
@@ -228,13 +241,13 @@ func (msc *MinerSmartContract) viewChangePoolsWork(gn *GlobalNode,
 
 	// unlockOffline
 	for _, mn := range minersOffline {
-		if err = msc.unlockOffline(mn, balances); err != nil {
+		if err = msc.unlockOffline(mn, round, gn, balances); err != nil {
 			return
 		}
 	}
 
 	for _, mn := range shardersOffline {
-		if err = msc.unlockOffline(mn, balances); err != nil {
+		if err = msc.unlockOffline(mn, round, gn, balances); err != nil {
 			return
 		}
 	}
@@ -476,15 +489,15 @@ func (msc *MinerSmartContract) mintStakeHolders(value state.Balance,
 			continue // avoid insufficient minting
 		}
 
-		var mint = state.NewMint(ADDRESS, pool.DelegateID, userMint)
-		if err = balances.AddMint(mint); err != nil {
-			resp += fmt.Sprintf("pay_fee/minting - adding mint: %v", err)
+		var mresp string
+		mresp, err = msc.mintDelegate(node, pool, userMint, gn, balances)
+		if err != nil {
+			resp += fmt.Sprintf("pay_fee/minting - %v", err)
 			continue
 		}
-		msc.addMint(gn, mint.Amount)
 		pool.AddRewards(userMint)
 
-		resp += string(mint.Encode())
+		resp += mresp
 	}
 
 	return resp, nil
@@ -520,13 +533,13 @@ func (msc *MinerSmartContract) payStakeHolders(value state.Balance,
 			continue // avoid insufficient transfer
 		}
 
-		var transfer = state.NewTransfer(ADDRESS, pool.DelegateID, userFee)
-		if err = balances.AddTransfer(transfer); err != nil {
-			return "", fmt.Errorf("adding transfer: %v", err)
+		var presp string
+		if presp, err = msc.payDelegate(node, pool, userFee, balances); err != nil {
+			return "", err
 		}
 
 		pool.AddRewards(userFee)
-		resp += string(transfer.Encode())
+		resp += presp
 	}
 
 	return resp, nil
@@ -0,0 +1,155 @@
+package minersc
+
+import (
+	"fmt"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/util"
+	"0chain.net/smartcontract"
+)
+
+// updateGlobals is SC function used by SC owner or by the governance SC
+// to update configurations of the SC; fields missing in given input are
+// kept as is, state of the SC can't be changed
+func (msc *MinerSmartContract) updateGlobals(t *transaction.Transaction,
+	inputData []byte, gn *GlobalNode, balances cstate.StateContextI) (
+	resp string, err error) {
+
+	if !smartcontract.IsConfigOwner(t.ClientID, owner) {
+		return "", common.NewError("update_globals",
+			"unauthorized access - only the owner can update the variables")
+	}
+
+	var (
+		viewChange     = gn.ViewChange
+		lastRound      = gn.LastRound
+		minted         = gn.Minted
+		burned         = gn.Burned
+		prevMagicBlock = gn.PrevMagicBlock
+	)
+	if err = gn.Decode(inputData); err != nil {
+		return "", common.NewErrorf("update_globals",
+			"decoding request: %v", err)
+	}
+	gn.ViewChange = viewChange
+	gn.LastRound = lastRound
+	gn.Minted = minted
+	gn.Burned = burned
+	gn.PrevMagicBlock = prevMagicBlock
+
+	if err = gn.validate(); err != nil {
+		return "", common.NewErrorf("update_globals",
+			"invalid configurations: %v", err)
+	}
+
+	if err = gn.save(balances); err != nil {
+		return "", common.NewErrorf("update_globals",
+			"saving global node: %v", err)
+	}
+
+	return string(gn.Encode()), nil
+}
+
+// ValidateGlobalsUpdate validates given update_globals input applied to
+// current configurations.
+func ValidateGlobalsUpdate(input []byte, balances cstate.StateContextI) (
+	err error) {
+
+	var gn *GlobalNode
+	if gn, err = getGlobalNode(balances); err != nil {
+		return fmt.Errorf("getting global node: %v", err)
+	}
+	if err = smartcontract.DecodeConfigUpdate(input, gn); err != nil {
+		return fmt.Errorf("decoding configurations: %v", err)
+	}
+	return gn.validate()
+}
+
+// LockDelegateStake prevents given client from deleting its active delegate
+// pools up to given round, inclusive. The governance SC locks stake of a
+// voter until the proposal closes, thus the same stake can't vote twice.
+func LockDelegateStake(clientID string, until int64,
+	balances cstate.StateContextI) (err error) {
+
+	var un = NewUserNode()
+	un.ID = clientID
+
+	var val util.Serializable
+	if val, err = balances.GetTrieNode(un.GetKey()); err != nil {
+		if err == util.ErrValueNotPresent {
+			return nil // no stake
+		}
+		return fmt.Errorf("getting user node: %v", err)
+	}
+	if err = un.Decode(val.Encode()); err != nil {
+		return fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	if un.LockedUntil >= until {
+		return
+	}
+	un.LockedUntil = until
+	return un.save(balances)
+}
+
+// GetDelegateStake returns sum of active stakes of given client in all
+// miners and sharders delegate pools.
+func GetDelegateStake(clientID string, balances cstate.StateContextI) (
+	stake state.Balance, err error) {
+
+	var un = NewUserNode()
+	un.ID = clientID
+
+	var val util.Serializable
+	if val, err = balances.GetTrieNode(un.GetKey()); err != nil {
+		if err == util.ErrValueNotPresent {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("getting user node: %v", err)
+	}
+	if err = un.Decode(val.Encode()); err != nil {
+		return 0, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+
+	for nodeID, poolIDs := range un.Pools {
+		var mn *MinerNode
+		if mn, err = getMinerNode(nodeID, balances); err != nil {
+			return 0, fmt.Errorf("getting node %s: %v", nodeID, err)
+		}
+		for _, id := range poolIDs {
+			if dp, ok := mn.Active[id]; ok && dp.Status == ACTIVE {
+				stake += dp.Balance
+			}
+		}
+	}
+	return
+}
+
+// GetTotalStake returns sum of active stakes of all miners and sharders.
+func GetTotalStake(balances cstate.StateContextI) (
+	total state.Balance, err error) {
+
+	var miners, sharders *MinerNodes
+	if miners, err = getMinersList(balances); err != nil {
+		return 0, fmt.Errorf("getting miners list: %v", err)
+	}
+	if sharders, err = getAllShardersList(balances); err != nil {
+		return 0, fmt.Errorf("getting sharders list: %v", err)
+	}
+
+	var nodes = append(miners.Nodes, sharders.Nodes...)
+	for _, node := range nodes {
+		var mn *MinerNode
+		if mn, err = getMinerNode(node.ID, balances); err != nil {
+			return 0, fmt.Errorf("getting node %s: %v", node.ID, err)
+		}
+		for _, dp := range mn.Active {
+			if dp.Status == ACTIVE {
+				total += dp.Balance
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,159 @@
+package minersc
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/smartcontract"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinerSmartContract_updateGlobals(t *testing.T) {
+	var (
+		balances = newTestBalances()
+		msc      = newTestMinerSC()
+		now      = int64(10000)
+		gn       = setConfig(t, balances)
+		err      error
+	)
+
+	gn.LastRound = 150
+	gn.Minted = 20e10
+	mustSave(t, GlobalNodeKey, gn, balances)
+
+	var update = func(clientID, input string) (err error) {
+		var tx = newTransaction(clientID, ADDRESS, 0, now)
+		balances.txn = tx
+		var gn *GlobalNode
+		if gn, err = getGlobalNode(balances); err != nil {
+			return
+		}
+		_, err = msc.updateGlobals(tx, []byte(input), gn, balances)
+		return
+	}
+
+	err = update(randString(32), `{"max_n":200}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unauthorized access")
+
+	err = update(smartcontract.GovernanceAddress, `{"max_n":1}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_n is less than min_n")
+
+	require.NoError(t, update(owner, `{"max_n":150}`))
+	require.NoError(t, update(smartcontract.GovernanceAddress,
+		`{"max_s":50,"minted":0,"last_round":0}`))
+
+	gn, err = getGlobalNode(balances)
+	require.NoError(t, err)
+	assert.Equal(t, 150, gn.MaxN)
+	assert.Equal(t, 50, gn.MaxS)
+	assert.Equal(t, 3, gn.MinN) // kept as is
+	assert.EqualValues(t, 150, gn.LastRound)
+	assert.EqualValues(t, 20e10, gn.Minted)
+}
+
+func TestGetDelegateStake(t *testing.T) {
+	var (
+		balances = newTestBalances()
+		msc      = newTestMinerSC()
+		now      = int64(10000)
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	var (
+		mn     = newMiner(t, msc, now, 2, 100e10, balances)
+		active = mn.stakers[0]
+	)
+	_, err = active.callAddToDelegatePool(t, msc, now, 10e10, mn.miner.id,
+		balances)
+	require.NoError(t, err)
+
+	var node *MinerNode
+	node, err = getMinerNode(mn.miner.id, balances)
+	require.NoError(t, err)
+	msc.activatePending(node)
+	require.NoError(t, node.save(balances))
+
+	// pending stake is not counted
+	_, err = mn.stakers[1].callAddToDelegatePool(t, msc, now, 5e10,
+		mn.miner.id, balances)
+	require.NoError(t, err)
+
+	var stake, total = getStakes(t, active.id, balances)
+	assert.EqualValues(t, 10e10, stake)
+	assert.EqualValues(t, 10e10, total)
+
+	stake, _ = getStakes(t, mn.stakers[1].id, balances)
+	assert.Zero(t, stake)
+	stake, _ = getStakes(t, randString(32), balances)
+	assert.Zero(t, stake)
+}
+
+func TestLockDelegateStake(t *testing.T) {
+	var (
+		balances = newTestBalances()
+		msc      = newTestMinerSC()
+		now      = int64(10000)
+		gn       = setConfig(t, balances)
+		err      error
+	)
+
+	var (
+		mn     = newMiner(t, msc, now, 1, 100e10, balances)
+		staker = mn.stakers[0]
+	)
+	_, err = staker.callAddToDelegatePool(t, msc, now, 10e10, mn.miner.id,
+		balances)
+	require.NoError(t, err)
+
+	var node *MinerNode
+	node, err = getMinerNode(mn.miner.id, balances)
+	require.NoError(t, err)
+	msc.activatePending(node)
+	require.NoError(t, node.save(balances))
+
+	var poolID string
+	for id := range node.Active {
+		poolID = id
+	}
+
+	// no stake, nothing to lock
+	require.NoError(t, LockDelegateStake(randString(32), 100, balances))
+
+	require.NoError(t, LockDelegateStake(staker.id, 100, balances))
+	require.NoError(t, LockDelegateStake(staker.id, 50, balances)) // kept
+
+	var del = func(round int64) (err error) {
+		balances.block = block.Provider().(*block.Block)
+		balances.block.Round = round
+		var tx = newTransaction(staker.id, ADDRESS, 0, now)
+		balances.txn = tx
+		_, err = msc.deleteFromDelegatePool(tx, mustEncode(t, &deletePool{
+			MinerID: mn.miner.id,
+			PoolID:  poolID,
+		}), gn, balances)
+		return
+	}
+
+	err = del(100)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"stake is locked by a governance vote until round 100")
+
+	require.NoError(t, del(101))
+}
+
+func getStakes(t *testing.T, clientID string, balances *testBalances) (
+	stake, total int64) {
+
+	var s, err = GetDelegateStake(clientID, balances)
+	require.NoError(t, err)
+	var ts, err2 = GetTotalStake(balances)
+	require.NoError(t, err2)
+	return int64(s), int64(ts)
+}
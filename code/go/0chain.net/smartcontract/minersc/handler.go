@@ -74,22 +74,34 @@ func (msc *MinerSmartContract) GetNodepoolHandler(ctx context.Context, params ur
 	return npi, nil
 }
 
+// GetMinerListHandler returns all miners, optionally filtered by metadata
+// by region, version, min_bandwidth query parameters
 func (msc *MinerSmartContract) GetMinerListHandler(ctx context.Context, params url.Values, balances cstate.StateContextI) (interface{}, error) {
+	nf, err := newNodesFilter(params)
+	if err != nil {
+		return nil, common.NewErrBadRequest("invalid filter", err.Error())
+	}
 	allMinersList, err := msc.GetMinersList(balances)
 	if err != nil {
 		return "", common.NewErrInternal("can't get miners list", err.Error())
 	}
-	return allMinersList, nil
+	return nf.filter(allMinersList), nil
 }
 
 const cantGetShardersListMsg = "can't get sharders list"
 
+// GetSharderListHandler returns all sharders, optionally filtered by
+// metadata by region, version, min_bandwidth, min_storage query parameters
 func (msc *MinerSmartContract) GetSharderListHandler(ctx context.Context, params url.Values, balances cstate.StateContextI) (interface{}, error) {
+	nf, err := newNodesFilter(params)
+	if err != nil {
+		return nil, common.NewErrBadRequest("invalid filter", err.Error())
+	}
 	allShardersList, err := getAllShardersList(balances)
 	if err != nil {
 		return "", common.NewErrInternal(cantGetShardersListMsg, err.Error())
 	}
-	return allShardersList, nil
+	return nf.filter(allShardersList), nil
 }
 
 func (msc *MinerSmartContract) GetSharderKeepListHandler(ctx context.Context, params url.Values, balances cstate.StateContextI) (interface{}, error) {
@@ -181,15 +193,21 @@ func (msc *MinerSmartContract) nodePoolStatHandler(ctx context.Context,
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetMinerNodeMsg)
 	}
 
-	if pool, ok := sn.Pending[poolID]; ok {
-		return pool, nil
-	} else if pool, ok = sn.Active[poolID]; ok {
-		return pool, nil
-	} else if pool, ok = sn.Deleting[poolID]; ok {
-		return pool, nil
+	var pool, ok = sn.Pending[poolID]
+	if !ok {
+		if pool, ok = sn.Active[poolID]; !ok {
+			if pool, ok = sn.Deleting[poolID]; !ok {
+				return nil, common.NewErrNoResource("can't find pool stats")
+			}
+		}
+	}
+
+	var ch *compoundHistory
+	if ch, err = getCompoundHistory(poolID, balances); err != nil {
+		return nil, common.NewErrInternal(err.Error())
 	}
 
-	return nil, common.NewErrNoResource("can't find pool stats")
+	return &nodePoolStat{DelegatePool: pool, CompoundHistory: ch.Stats}, nil
 }
 
 func (msc *MinerSmartContract) configsHandler(ctx context.Context,
@@ -44,6 +44,8 @@ func (msc *MinerSmartContract) initSC() {
 	// as is
 	msc.smartContractFunctions["wait"] = msc.wait
 	msc.smartContractFunctions["update_settings"] = msc.UpdateSettings
+	msc.smartContractFunctions["update_node_metadata"] = msc.updateNodeMetadata
+	msc.smartContractFunctions["update_globals"] = msc.updateGlobals
 	msc.smartContractFunctions["addToDelegatePool"] = msc.addToDelegatePool
 	msc.smartContractFunctions["deleteFromDelegatePool"] = msc.deleteFromDelegatePool
 }
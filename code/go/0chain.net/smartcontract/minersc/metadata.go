@@ -0,0 +1,224 @@
+package minersc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+// max lengths of node metadata strings
+const (
+	maxRegionLength  = 64
+	maxVersionLength = 64
+)
+
+// NodeMetadata is optional hardware, location and software information
+// declared by a miner or a sharder on registration, or updated later by the
+// node. It's signed by the node key, thus only the node can declare it.
+type NodeMetadata struct {
+	// Region is geographical region of the node, for example "eu-west".
+	Region string `json:"region"`
+	// Bandwidth of the node in Mbps.
+	Bandwidth int64 `json:"bandwidth"`
+	// Storage capacity of a sharder in bytes.
+	Storage int64 `json:"storage,omitempty"`
+	// Version of the node software.
+	Version string `json:"version"`
+	// Signature of the metadata hash by the node.
+	Signature string `json:"signature"`
+}
+
+// Hash of the metadata to sign.
+func (nm *NodeMetadata) Hash() string {
+	return encryption.Hash(nm.Region + ":" +
+		strconv.FormatInt(nm.Bandwidth, 10) + ":" +
+		strconv.FormatInt(nm.Storage, 10) + ":" + nm.Version)
+}
+
+func (nm *NodeMetadata) decode(b []byte) error {
+	return json.Unmarshal(b, nm)
+}
+
+func (nm *NodeMetadata) validate(nodeType NodeType) error {
+	switch {
+	case len(nm.Region) > maxRegionLength:
+		return errors.New("region is too long")
+	case len(nm.Version) > maxVersionLength:
+		return errors.New("version is too long")
+	case nm.Bandwidth < 0:
+		return errors.New("negative bandwidth")
+	case nm.Storage < 0:
+		return errors.New("negative storage")
+	case nm.Storage > 0 && nodeType != NodeTypeSharder:
+		return errors.New("storage can be declared by a sharder only")
+	case nm.Signature == "":
+		return errors.New("missing signature")
+	}
+	return nil
+}
+
+// verify the metadata signed by given node key
+func (nm *NodeMetadata) verify(balances cstate.StateContextI,
+	publicKey string) (err error) {
+
+	var scheme = balances.GetSignatureScheme()
+	if err = scheme.SetPublicKey(publicKey); err != nil {
+		return fmt.Errorf("setting node public key: %v", err)
+	}
+	var ok bool
+	if ok, err = scheme.Verify(nm.Signature, nm.Hash()); err != nil {
+		return fmt.Errorf("verifying signature: %v", err)
+	}
+	if !ok {
+		return errors.New("invalid signature")
+	}
+	return
+}
+
+// validateMetadata validates and verifies metadata of a node, if any; the
+// node type and public key should be set; the registration doesn't prove
+// the sender is the node, thus the metadata is verified by the node key
+func (mn *MinerNode) validateMetadata(balances cstate.StateContextI) (
+	err error) {
+
+	if mn.Metadata == nil {
+		return
+	}
+	if err = mn.Metadata.validate(mn.NodeType); err != nil {
+		return
+	}
+	return mn.Metadata.verify(balances, mn.PublicKey)
+}
+
+// region of the node, empty if unknown
+func (smn *SimpleNode) region() string {
+	if smn.Metadata == nil {
+		return ""
+	}
+	return smn.Metadata.Region
+}
+
+// nodesFilter filters miners and sharders lists by metadata
+type nodesFilter struct {
+	region       string
+	version      string
+	minBandwidth int64
+	minStorage   int64
+}
+
+func newNodesFilter(params url.Values) (nf *nodesFilter, err error) {
+	nf = new(nodesFilter)
+	nf.region = params.Get("region")
+	nf.version = params.Get("version")
+	if mb := params.Get("min_bandwidth"); mb != "" {
+		if nf.minBandwidth, err = strconv.ParseInt(mb, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid min_bandwidth: %v", err)
+		}
+	}
+	if ms := params.Get("min_storage"); ms != "" {
+		if nf.minStorage, err = strconv.ParseInt(ms, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid min_storage: %v", err)
+		}
+	}
+	return
+}
+
+func (nf *nodesFilter) isEmpty() bool {
+	return nf.region == "" && nf.version == "" && nf.minBandwidth == 0 &&
+		nf.minStorage == 0
+}
+
+func (nf *nodesFilter) match(mn *MinerNode) bool {
+	var md = mn.Metadata
+	if md == nil {
+		return false
+	}
+	return (nf.region == "" || md.Region == nf.region) &&
+		(nf.version == "" || md.Version == nf.version) &&
+		md.Bandwidth >= nf.minBandwidth &&
+		md.Storage >= nf.minStorage
+}
+
+// filter returns nodes matching the filter, or given list if the filter is
+// empty
+func (nf *nodesFilter) filter(nodes *MinerNodes) *MinerNodes {
+	if nf.isEmpty() {
+		return nodes
+	}
+	var filtered = &MinerNodes{Nodes: make([]*MinerNode, 0)}
+	for _, mn := range nodes.Nodes {
+		if nf.match(mn) {
+			filtered.Nodes = append(filtered.Nodes, mn)
+		}
+	}
+	return filtered
+}
+
+// updateNodeMetadata is SC function used by a registered miner or sharder
+// to replace its metadata; the transaction should be sent by the node
+func (msc *MinerSmartContract) updateNodeMetadata(t *transaction.Transaction,
+	input []byte, gn *GlobalNode, balances cstate.StateContextI) (
+	resp string, err error) {
+
+	var md = new(NodeMetadata)
+	if err = md.decode(input); err != nil {
+		return "", common.NewErrorf("update_node_metadata",
+			"decoding request: %v", err)
+	}
+
+	var mn *MinerNode
+	if mn, err = getMinerNode(t.ClientID, balances); err != nil {
+		return "", common.NewErrorf("update_node_metadata",
+			"can't get the node %s: %v", t.ClientID, err)
+	}
+
+	var (
+		all  *MinerNodes
+		save func(cstate.StateContextI, *MinerNodes) error
+	)
+	switch mn.NodeType {
+	case NodeTypeMiner:
+		all, err = getMinersList(balances)
+		save = updateMinersList
+	case NodeTypeSharder:
+		all, err = getAllShardersList(balances)
+		save = updateAllShardersList
+	default:
+		return "", common.NewErrorf("update_node_metadata",
+			"unexpected node type: %s", mn.NodeType.String())
+	}
+	if err != nil {
+		return "", common.NewErrorf("update_node_metadata",
+			"can't get nodes list: %v", err)
+	}
+
+	mn.Metadata = md
+	if err = mn.validateMetadata(balances); err != nil {
+		return "", common.NewErrorf("update_node_metadata",
+			"invalid metadata: %v", err)
+	}
+
+	for _, node := range all.Nodes {
+		if node.ID == mn.ID {
+			node.Metadata = md
+			break
+		}
+	}
+	if err = save(balances, all); err != nil {
+		return "", common.NewErrorf("update_node_metadata",
+			"saving nodes list: %v", err)
+	}
+	if err = mn.save(balances); err != nil {
+		return "", common.NewErrorf("update_node_metadata",
+			"saving node: %v", err)
+	}
+
+	return string(mn.Encode()), nil
+}
@@ -0,0 +1,143 @@
+package minersc
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signMetadata signs given metadata by given signer
+func signMetadata(t *testing.T, signer *Client,
+	md *NodeMetadata) *NodeMetadata {
+
+	var err error
+	md.Signature, err = signer.scheme.Sign(md.Hash())
+	require.NoError(t, err)
+	return md
+}
+
+// add_miner or add_sharder transaction data with metadata signed by given
+// signer
+func (c *Client) addNodeWithMetadataRequest(t *testing.T, signer *Client,
+	md *NodeMetadata) []byte {
+
+	var mn = NewMinerNode()
+	require.NoError(t, json.Unmarshal(c.addNodeRequest(t, c.id), mn))
+	mn.Metadata = signMetadata(t, signer, md)
+	return mustEncode(t, mn)
+}
+
+func TestMinerSmartContract_nodeMetadata(t *testing.T) {
+	var (
+		balances = newTestBalances()
+		msc      = newTestMinerSC()
+		now      = int64(10000)
+		gn       = setConfig(t, balances)
+		err      error
+	)
+
+	var (
+		miner   = newClient(0, balances)
+		sharder = newClient(0, balances)
+		other   = newClient(0, balances)
+	)
+
+	var addMiner = func(signer *Client, md *NodeMetadata) (err error) {
+		var tx = newTransaction(other.id, ADDRESS, 0, now)
+		balances.txn = tx
+		_, err = msc.AddMiner(tx, miner.addNodeWithMetadataRequest(t, signer,
+			md), gn, balances)
+		return
+	}
+
+	// anyone can send the registration, but only the node signs metadata
+	err = addMiner(other, &NodeMetadata{Region: "eu", Version: "1.0"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid metadata: invalid signature")
+
+	err = addMiner(miner, &NodeMetadata{Region: "eu", Storage: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "storage can be declared by a sharder only")
+
+	err = addMiner(miner, &NodeMetadata{Region: "eu", Bandwidth: 100,
+		Version: "1.0"})
+	require.NoError(t, err)
+
+	var tx = newTransaction(sharder.id, ADDRESS, 0, now)
+	balances.txn = tx
+	_, err = msc.AddSharder(tx, sharder.addNodeWithMetadataRequest(t, sharder,
+		&NodeMetadata{Region: "us", Bandwidth: 1000, Storage: 1e12,
+			Version: "1.1"}), gn, balances)
+	require.NoError(t, err)
+
+	var mn *MinerNode
+	mn, err = getMinerNode(miner.id, balances)
+	require.NoError(t, err)
+	require.NotNil(t, mn.Metadata)
+	assert.Equal(t, "eu", mn.Metadata.Region)
+
+	// filters
+
+	var miners = func(query url.Values) []*MinerNode {
+		var resp, err = msc.GetMinerListHandler(context.Background(), query,
+			balances)
+		require.NoError(t, err)
+		return resp.(*MinerNodes).Nodes
+	}
+	var sharders = func(query url.Values) []*MinerNode {
+		var resp, err = msc.GetSharderListHandler(context.Background(), query,
+			balances)
+		require.NoError(t, err)
+		return resp.(*MinerNodes).Nodes
+	}
+
+	assert.Len(t, miners(url.Values{}), 1)
+	assert.Len(t, miners(url.Values{"region": {"eu"}}), 1)
+	assert.Len(t, miners(url.Values{"region": {"us"}}), 0)
+	assert.Len(t, miners(url.Values{"min_bandwidth": {"101"}}), 0)
+	assert.Len(t, sharders(url.Values{"version": {"1.1"},
+		"min_storage": {"1000000000000"}}), 1)
+	assert.Len(t, sharders(url.Values{"min_storage": {"1000000000001"}}), 0)
+
+	_, err = msc.GetSharderListHandler(context.Background(),
+		url.Values{"min_storage": {"lot"}}, balances)
+	require.Error(t, err)
+
+	// update
+
+	var update = func(node, signer *Client, md *NodeMetadata) (err error) {
+		var tx = newTransaction(node.id, ADDRESS, 0, now)
+		balances.txn = tx
+		_, err = msc.updateNodeMetadata(tx,
+			mustEncode(t, signMetadata(t, signer, md)), gn, balances)
+		return
+	}
+
+	err = update(other, other, &NodeMetadata{Region: "eu"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't get the node")
+	err = update(miner, other, &NodeMetadata{Region: "us"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid metadata: invalid signature")
+	err = update(miner, miner, &NodeMetadata{Region: "us", Storage: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "storage can be declared by a sharder only")
+
+	require.NoError(t, update(miner, miner, &NodeMetadata{Region: "us",
+		Bandwidth: 200, Version: "1.1"}))
+	require.NoError(t, update(sharder, sharder, &NodeMetadata{Region: "eu",
+		Storage: 1e9}))
+
+	mn, err = getMinerNode(miner.id, balances)
+	require.NoError(t, err)
+	assert.Equal(t, "us", mn.Metadata.Region)
+	assert.Len(t, miners(url.Values{"region": {"us"},
+		"min_bandwidth": {"200"}}), 1)
+	assert.Len(t, miners(url.Values{"region": {"eu"}}), 0)
+	assert.Len(t, sharders(url.Values{"region": {"eu"}}), 1)
+	assert.Len(t, sharders(url.Values{"min_storage": {"1000000000000"}}), 0)
+}
@@ -118,6 +118,10 @@ func (msc *MinerSmartContract) AddMiner(t *transaction.Transaction,
 
 	newMiner.NodeType = NodeTypeMiner // set node type
 
+	if err = newMiner.validateMetadata(balances); err != nil {
+		return "", common.NewErrorf("add_miner", "invalid metadata: %v", err)
+	}
+
 	if err = quickFixDuplicateHosts(newMiner, allMiners.Nodes); err != nil {
 		return "", common.NewError("add_miner", err.Error())
 	}
@@ -46,9 +46,15 @@ func (msc *MinerSmartContract) InitSmartContractFunctions() {
 	msc.smartContractFunctions["wait"] = msc.wait
 
 	msc.smartContractFunctions["update_settings"] = msc.UpdateSettings
+	msc.smartContractFunctions["update_node_metadata"] = msc.updateNodeMetadata
+	msc.smartContractFunctions["update_globals"] = msc.updateGlobals
 
 	msc.smartContractFunctions["addToDelegatePool"] = msc.addToDelegatePool
 	msc.smartContractFunctions["deleteFromDelegatePool"] = msc.deleteFromDelegatePool
+	msc.smartContractFunctions["claim_unbonded"] = msc.claimUnbonded
+	msc.smartContractFunctions["set_auto_compound"] = msc.setAutoCompound
 
 	msc.smartContractFunctions["sharder_keep"] = msc.sharderKeep
+
+	msc.smartContractFunctions["report_equivocation"] = msc.reportEquivocation
 }
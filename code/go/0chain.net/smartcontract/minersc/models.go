@@ -109,7 +109,9 @@ func NewSimpleNodes() SimpleNodes {
 // not thread safe
 type SimpleNodes map[string]*SimpleNode
 
-func (sns SimpleNodes) reduce(limit int, xPercent float64, pmbrss int64, pmbnp *node.Pool) (maxNodes int) {
+func (sns SimpleNodes) reduce(limit int, xPercent, maxRegionRatio float64,
+	pmbrss int64, pmbnp *node.Pool) (maxNodes int) {
+
 	var pmbNodes, newNodes, selectedNodes []*SimpleNode
 
 	// separate previous mb miners and new miners from dkg miners list
@@ -150,11 +152,7 @@ func (sns SimpleNodes) reduce(limit int, xPercent float64, pmbrss int64, pmbnp *
 		return newNodes[i].TotalStaked > newNodes[j].TotalStaked
 	})
 
-	if len(newNodes) <= y {
-		// less than allowed nodes remaining
-		selectedNodes = append(selectedNodes, newNodes...)
-
-	} else if y > 0 {
+	if len(newNodes) > y && y > 0 {
 		// more than allowed nodes remaining
 
 		// find the range of nodes with equal stakes, start (s), end (e)
@@ -169,19 +167,22 @@ func (sns SimpleNodes) reduce(limit int, xPercent float64, pmbrss int64, pmbnp *
 			}
 		}
 
-		// select nodes that don't have equal stake
-		selectedNodes = append(selectedNodes, newNodes[:s]...)
-
-		// resolve equal stake condition by randomly selecting nodes with equal stake
-		newNodes = newNodes[s:e]
-		for _, j := range rand.New(rand.NewSource(pmbrss)).Perm(len(newNodes)) {
-			if len(selectedNodes) < maxNodes {
-				selectedNodes = append(selectedNodes, newNodes[j])
-			}
+		// resolve equal stake condition by randomly ordering nodes with
+		// equal stake
+		var (
+			equal   = newNodes[s:e]
+			ordered = make([]*SimpleNode, 0, len(newNodes))
+		)
+		ordered = append(ordered, newNodes[:s]...)
+		for _, j := range rand.New(rand.NewSource(pmbrss)).Perm(len(equal)) {
+			ordered = append(ordered, equal[j])
 		}
-
+		newNodes = append(ordered, newNodes[e:]...)
 	}
 
+	selectedNodes = selectByRegions(selectedNodes, newNodes, maxNodes,
+		maxRegionRatio)
+
 	// update map with selected nodes
 	for k := range sns {
 		delete(sns, k)
@@ -193,6 +194,44 @@ func (sns SimpleNodes) reduce(limit int, xPercent float64, pmbrss int64, pmbnp *
 	return maxNodes
 }
 
+// selectByRegions appends given ordered candidates to selected nodes up to
+// max nodes keeping number of nodes of the same region within the max region
+// ratio; candidates skipped by the ratio are selected only if there are not
+// enough other candidates; nodes of unknown region are limited as a region
+func selectByRegions(selected, candidates []*SimpleNode, maxNodes int,
+	maxRegionRatio float64) []*SimpleNode {
+
+	var skipped []*SimpleNode
+	if maxRegionRatio > 0 {
+		var (
+			limit   = int(math.Ceil(maxRegionRatio * float64(maxNodes)))
+			regions = make(map[string]int)
+			allowed = make([]*SimpleNode, 0, len(candidates))
+		)
+		for _, sn := range selected {
+			regions[sn.region()]++
+		}
+		for _, sn := range candidates {
+			var region = sn.region()
+			if regions[region] >= limit {
+				skipped = append(skipped, sn)
+				continue
+			}
+			regions[region]++
+			allowed = append(allowed, sn)
+		}
+		candidates = allowed
+	}
+
+	for _, sn := range append(candidates, skipped...) {
+		if len(selected) >= maxNodes {
+			break
+		}
+		selected = append(selected, sn)
+	}
+	return selected
+}
+
 //
 // global
 //
@@ -251,9 +290,27 @@ type GlobalNode struct {
 
 	// Minted tokens by SC.
 	Minted state.Balance `json:"minted"`
+	// Burned tokens by SC, slashed tokens are burned.
+	Burned state.Balance `json:"burned"`
 
 	// If viewchange is false then this will be used to pay interests and rewards to miner/sharders.
 	RewardRoundFrequency int64 `json:"reward_round_frequency"`
+
+	// EquivocationSlash is ratio of stakes slashed for an equivocation.
+	EquivocationSlash float64 `json:"equivocation_slash"`
+	// EquivocationReporterShare is ratio of slashed tokens given to the
+	// reporter, rest of slashed tokens are burned.
+	EquivocationReporterShare float64 `json:"equivocation_reporter_share"`
+	// EquivocationMaxAge is max number of rounds passed since the round of
+	// an equivocation it can be reported, zero disables the limit.
+	EquivocationMaxAge int64 `json:"equivocation_max_age"`
+	// MaxRegionRatio is max ratio of miners of the same region in a magic
+	// block, zero disables the limit.
+	MaxRegionRatio float64 `json:"max_region_ratio"`
+
+	// UnbondingPeriod is number of rounds unlocked stakes wait before
+	// they can be claimed.
+	UnbondingPeriod int64 `json:"unbonding_period"`
 }
 
 // The prevMagicBlock from the global node (saved on previous VC) or LFMB of
@@ -453,6 +510,51 @@ func (gn *GlobalNode) setLastRound(round int64) {
 	}
 }
 
+// validate bounds of the configurations
+func (gn *GlobalNode) validate() error {
+	if gn.MinN < 1 {
+		return fmt.Errorf("min_n is too small: %d", gn.MinN)
+	}
+	if gn.MaxN < gn.MinN {
+		return fmt.Errorf("max_n is less than min_n: %d < %d",
+			gn.MaxN, gn.MinN)
+	}
+
+	if gn.MinS < 1 {
+		return fmt.Errorf("min_s is too small: %d", gn.MinS)
+	}
+	if gn.MaxS < gn.MinS {
+		return fmt.Errorf("max_s is less than min_s: %d < %d",
+			gn.MaxS, gn.MinS)
+	}
+
+	if gn.MaxDelegates <= 0 {
+		return fmt.Errorf("max_delegates is too small: %d", gn.MaxDelegates)
+	}
+
+	if gn.UnbondingPeriod < 0 {
+		return fmt.Errorf("negative unbonding_period: %d",
+			gn.UnbondingPeriod)
+	}
+	if gn.EquivocationSlash < 0 || gn.EquivocationSlash > 1 {
+		return fmt.Errorf("equivocation_slash is out of [0; 1] range: %v",
+			gn.EquivocationSlash)
+	}
+	if gn.EquivocationReporterShare < 0 || gn.EquivocationReporterShare > 1 {
+		return fmt.Errorf("equivocation_reporter_share is out of "+
+			"[0; 1] range: %v", gn.EquivocationReporterShare)
+	}
+	if gn.EquivocationMaxAge < 0 {
+		return fmt.Errorf("negative equivocation_max_age: %d",
+			gn.EquivocationMaxAge)
+	}
+	if gn.MaxRegionRatio < 0 || gn.MaxRegionRatio > 1 {
+		return fmt.Errorf("max_region_ratio is out of [0; 1] range: %v",
+			gn.MaxRegionRatio)
+	}
+	return nil
+}
+
 func (gn *GlobalNode) save(balances cstate.StateContextI) (err error) {
 	if _, err = balances.InsertTrieNode(GlobalNodeKey, gn); err != nil {
 		return fmt.Errorf("saving global node: %v", err)
@@ -487,6 +589,9 @@ type MinerNode struct {
 	Pending     map[string]*sci.DelegatePool `json:"pending,omitempty"`
 	Active      map[string]*sci.DelegatePool `json:"active,omitempty"`
 	Deleting    map[string]*sci.DelegatePool `json:"deleting,omitempty"`
+	// Unbonding is delegates have tokens unbonding from the node,
+	// delegate ID -> latest maturity round.
+	Unbonding map[string]int64 `json:"unbonding,omitempty"`
 }
 
 func NewMinerNode() *MinerNode {
@@ -494,6 +599,7 @@ func NewMinerNode() *MinerNode {
 	mn.Pending = make(map[string]*sci.DelegatePool)
 	mn.Active = make(map[string]*sci.DelegatePool)
 	mn.Deleting = make(map[string]*sci.DelegatePool)
+	mn.Unbonding = make(map[string]int64)
 	return mn
 }
 
@@ -589,6 +695,13 @@ func (mn *MinerNode) Decode(input []byte) error {
 			return err
 		}
 	}
+	unbonding, ok := objMap["unbonding"]
+	if ok {
+		err = json.Unmarshal(unbonding, &mn.Unbonding)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -669,6 +782,8 @@ type Stat struct {
 	// for sharder (totals)
 	SharderRewards state.Balance `json:"sharder_rewards,omitempty"`
 	SharderFees    state.Balance `json:"sharder_fees,omitempty"`
+	// slashed stakes for equivocations (total)
+	Slashed state.Balance `json:"slashed,omitempty"`
 }
 
 type SimpleNode struct {
@@ -705,6 +820,9 @@ type SimpleNode struct {
 
 	// LastHealthCheck used to check for active node
 	LastHealthCheck common.Timestamp `json:"last_health_check"`
+
+	// Metadata is optional information declared by the node.
+	Metadata *NodeMetadata `json:"metadata,omitempty"`
 }
 
 func (smn *SimpleNode) Encode() []byte {
@@ -810,6 +928,8 @@ type delegatePoolStat struct {
 	Status       string        `json:"status"`        //
 	High         state.Balance `json:"high"`          // }
 	Low          state.Balance `json:"low"`           // }
+	AutoCompound bool          `json:"auto_compound"` //
+	Compounded   state.Balance `json:"compounded"`    //
 }
 
 func newDelegatePoolStat(dp *sci.DelegatePool) (dps *delegatePoolStat) {
@@ -821,6 +941,8 @@ func newDelegatePoolStat(dp *sci.DelegatePool) (dps *delegatePoolStat) {
 	dps.Status = dp.Status
 	dps.High = dp.High
 	dps.Low = dp.Low
+	dps.AutoCompound = dp.AutoCompound
+	dps.Compounded = dp.Compounded
 	return
 }
 
@@ -839,6 +961,9 @@ func newUserPools() (ups *userPools) {
 type UserNode struct {
 	ID    string                            `json:"id"`       // client ID
 	Pools map[datastore.Key][]datastore.Key `json:"pool_map"` // node_id -> [pool_id]
+	// LockedUntil is last round active pools of the user can't be deleted
+	// in, it's set by a governance vote.
+	LockedUntil int64 `json:"locked_until,omitempty"`
 }
 
 func NewUserNode() *UserNode {
@@ -884,6 +1009,8 @@ func (un *UserNode) GetHashBytes() []byte {
 type deletePool struct {
 	MinerID string `json:"id"`
 	PoolID  string `json:"pool_id"`
+	// AutoCompound is used by addToDelegatePool and set_auto_compound.
+	AutoCompound bool `json:"auto_compound,omitempty"`
 }
 
 func (dp *deletePool) Encode() []byte {
@@ -1046,7 +1173,8 @@ func (dkgmn *DKGMinerNodes) reduceNodes(
 				pmbnp = pmb.MagicBlock.Miners
 			}
 		}
-		simpleNodes.reduce(gn.MaxN, gn.XPercent, pmbrss, pmbnp)
+		simpleNodes.reduce(gn.MaxN, gn.XPercent, gn.MaxRegionRatio, pmbrss,
+			pmbnp)
 		dkgmn.SimpleNodes = simpleNodes
 	}
 
@@ -49,21 +49,21 @@ func TestSimpleNodesReduce(t *testing.T) {
 
 	// select up to 5 of the existing nodes
 	sn, np := createTestSimpleNodesAndNodePool()
-	sn.reduce(7, 0.7, pmbrss, np)
+	sn.reduce(7, 0.7, 0, pmbrss, np)
 	for _, n := range sn {
 		assert.Contains(t, []string{"2", "4", "6", "9", "0", "1", "3"}, n.ID)
 	}
 
 	// select up to 3 nodes from previous set and rest by desc stake
 	sn, np = createTestSimpleNodesAndNodePool()
-	sn.reduce(5, 0.6, pmbrss, np)
+	sn.reduce(5, 0.6, 0, pmbrss, np)
 	for _, n := range sn {
 		assert.Contains(t, []string{"2", "4", "6", "0", "1"}, n.ID)
 	}
 
 	// select up to 5 nodes from previous set and rest by desc stake
 	sn, np = createTestSimpleNodesAndNodePool()
-	sn.reduce(8, 0.6, pmbrss, np)
+	sn.reduce(8, 0.6, 0, pmbrss, np)
 	for _, n := range sn {
 		assert.Contains(t, []string{"2", "4", "6", "9", "0", "1", "3", "5"}, n.ID)
 	}
@@ -71,7 +71,7 @@ func TestSimpleNodesReduce(t *testing.T) {
 	// select up to 6 nodes form previous set (4), and rest by desc stake
 	// resolve equal stake (7:2, 8:2) using pmbrss
 	sn, np = createTestSimpleNodesAndNodePool()
-	sn.reduce(9, 0.6, pmbrss, np)
+	sn.reduce(9, 0.6, 0, pmbrss, np)
 	for _, n := range sn {
 		assert.Contains(t, []string{"2", "4", "6", "9", "0", "1", "3", "5", "8"}, n.ID)
 	}
@@ -79,13 +79,69 @@ func TestSimpleNodesReduce(t *testing.T) {
 	// select up to 6 nodes form previous set (4), and rest by desc stake
 	// resolve equal stake (7:2, 8:2) using pmbrss+2
 	sn, np = createTestSimpleNodesAndNodePool()
-	sn.reduce(9, 0.6, pmbrss+2, np)
+	sn.reduce(9, 0.6, 0, pmbrss+2, np)
 	for _, n := range sn {
 		assert.Contains(t, []string{"2", "4", "6", "9", "0", "1", "3", "5", "7"}, n.ID)
 	}
 
 }
 
+func TestSimpleNodesReduceRegions(t *testing.T) {
+	var pmbrss int64 = 123456789
+
+	var nodes = func(regions ...string) SimpleNodes {
+		if len(regions) == 0 {
+			regions = []string{"eu", "eu", "eu", "eu", "us", "us", "asia", ""}
+		}
+		var sn = NewSimpleNodes()
+		for i, region := range regions {
+			var id = string(rune('0' + i))
+			sn[id] = &SimpleNode{ID: id, TotalStaked: int64(100 - i)}
+			if region != "" {
+				sn[id].Metadata = &NodeMetadata{Region: region}
+			}
+		}
+		return sn
+	}
+
+	var ids = func(sn SimpleNodes) (list []string) {
+		for id := range sn {
+			list = append(list, id)
+		}
+		return
+	}
+
+	// no limit, by stake
+	var sn = nodes()
+	sn.reduce(4, 0, 0, pmbrss, nil)
+	assert.ElementsMatch(t, []string{"0", "1", "2", "3"}, ids(sn))
+
+	// up to 2 nodes of the same region
+	sn = nodes()
+	sn.reduce(4, 0, 0.5, pmbrss, nil)
+	assert.ElementsMatch(t, []string{"0", "1", "4", "5"}, ids(sn))
+
+	sn = nodes()
+	sn.reduce(5, 0, 0.2, pmbrss, nil)
+	assert.ElementsMatch(t, []string{"0", "4", "6", "7", "1"}, ids(sn))
+
+	// unknown region is limited as a region
+	sn = nodes("", "", "", "eu", "us")
+	sn.reduce(4, 0, 0.5, pmbrss, nil)
+	assert.ElementsMatch(t, []string{"0", "1", "3", "4"}, ids(sn))
+
+	// previous magic block nodes are kept regardless the limit
+	var np = node.NewPool(node.NodeTypeMiner)
+	for _, id := range []string{"2", "3"} {
+		var n = &node.Node{}
+		n.ID = id
+		np.AddNode(n)
+	}
+	sn = nodes()
+	sn.reduce(4, 0.5, 0.5, pmbrss, np)
+	assert.ElementsMatch(t, []string{"2", "3", "4", "5"}, ids(sn))
+}
+
 func TestQuickFixDuplicateHosts(t *testing.T) {
 	node := func(id, n2nhost, host string, port int) *MinerNode {
 		return &MinerNode{SimpleNode: &SimpleNode{ID: id, N2NHost: n2nhost, Host: host, Port: port}}
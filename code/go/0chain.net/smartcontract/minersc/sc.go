@@ -93,6 +93,7 @@ func (msc *MinerSmartContract) setSC(sc *sci.SmartContract, bcContext sci.BCCont
 	msc.SmartContract = sc
 	msc.SmartContract.RestHandlers["/getNodepool"] = msc.GetNodepoolHandler
 	msc.SmartContract.RestHandlers["/getUserPools"] = msc.GetUserPoolsHandler
+	msc.SmartContract.RestHandlers["/getUnbondingPools"] = msc.GetUnbondingPoolsHandler
 	msc.SmartContract.RestHandlers["/getMinerList"] = msc.GetMinerListHandler
 	msc.SmartContract.RestHandlers["/getSharderList"] = msc.GetSharderListHandler
 	msc.SmartContract.RestHandlers["/getSharderKeepList"] = msc.GetSharderKeepListHandler
@@ -112,7 +113,12 @@ func (msc *MinerSmartContract) setSC(sc *sci.SmartContract, bcContext sci.BCCont
 	msc.SmartContractExecutionStats["miner_health_check"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", msc.ID, "miner_health_check"), nil)
 	msc.SmartContractExecutionStats["sharder_health_check"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", msc.ID, "sharder_health_check"), nil)
 	msc.SmartContractExecutionStats["update_settings"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", msc.ID, "update_settings"), nil)
+	msc.SmartContractExecutionStats["update_node_metadata"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", msc.ID, "update_node_metadata"), nil)
+	msc.SmartContractExecutionStats["update_globals"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", msc.ID, "update_globals"), nil)
 	msc.SmartContractExecutionStats["payFees"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", msc.ID, "payFees"), nil)
+	msc.SmartContractExecutionStats["report_equivocation"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", msc.ID, "report_equivocation"), nil)
+	msc.SmartContractExecutionStats["claim_unbonded"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", msc.ID, "claim_unbonded"), nil)
+	msc.SmartContractExecutionStats["set_auto_compound"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", msc.ID, "set_auto_compound"), nil)
 	msc.SmartContractExecutionStats["feesPaid"] = metrics.GetOrRegisterCounter("feesPaid", nil)
 	msc.SmartContractExecutionStats["mintedTokens"] = metrics.GetOrRegisterCounter("mintedTokens", nil)
 }
@@ -214,28 +220,6 @@ func getGlobalNode(balances cstate.StateContextI) (
 	gn.MinS = conf.GetInt(pfx + "min_s")
 	gn.MaxDelegates = conf.GetInt(pfx + "max_delegates")
 	gn.RewardRoundFrequency = conf.GetInt64(pfx + "reward_round_frequency")
-
-	// check bounds
-	if gn.MinN < 1 {
-		return nil, fmt.Errorf("min_n is too small: %d", gn.MinN)
-	}
-	if gn.MaxN < gn.MinN {
-		return nil, fmt.Errorf("max_n is less than min_n: %d < %d",
-			gn.MaxN, gn.MinN)
-	}
-
-	if gn.MinS < 1 {
-		return nil, fmt.Errorf("min_s is too small: %d", gn.MinS)
-	}
-	if gn.MaxS < gn.MinS {
-		return nil, fmt.Errorf("max_s is less than min_s: %d < %d",
-			gn.MaxS, gn.MinS)
-	}
-
-	if gn.MaxDelegates <= 0 {
-		return nil, fmt.Errorf("max_delegates is too small: %d", gn.MaxDelegates)
-	}
-
 	gn.InterestRate = conf.GetFloat64(pfx + "interest_rate")
 	gn.RewardRate = conf.GetFloat64(pfx + "reward_rate")
 	gn.ShareRatio = conf.GetFloat64(pfx + "share_ratio")
@@ -245,6 +229,17 @@ func getGlobalNode(balances cstate.StateContextI) (
 	gn.RewardDeclineRate = conf.GetFloat64(pfx + "reward_decline_rate")
 	gn.InterestDeclineRate = conf.GetFloat64(pfx + "interest_decline_rate")
 	gn.MaxMint = state.Balance(conf.GetFloat64(pfx+"max_mint") * 1e10)
+	gn.EquivocationSlash = conf.GetFloat64(pfx + "equivocation_slash")
+	gn.EquivocationReporterShare = conf.GetFloat64(pfx +
+		"equivocation_reporter_share")
+	gn.EquivocationMaxAge = conf.GetInt64(pfx + "equivocation_max_age")
+
+	gn.UnbondingPeriod = conf.GetInt64(pfx + "unbonding_period")
+	gn.MaxRegionRatio = conf.GetFloat64(pfx + "max_region_ratio")
+
+	if err = gn.validate(); err != nil {
+		return nil, err
+	}
 
 	return gn, nil
 }
@@ -101,6 +101,10 @@ func (msc *MinerSmartContract) AddSharder(
 
 	newSharder.NodeType = NodeTypeSharder // set node type
 
+	if err = newSharder.validateMetadata(balances); err != nil {
+		return "", common.NewErrorf("add_sharder", "invalid metadata: %v", err)
+	}
+
 	if err = quickFixDuplicateHosts(newSharder, allSharders.Nodes); err != nil {
 		return "", common.NewError("add_sharder", err.Error())
 	}
@@ -0,0 +1,246 @@
+package minersc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	cstate "0chain.net/chaincore/chain/state"
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+)
+
+// unbondingPool is tokens of an unlocked delegate pool waiting for the
+// unbonding period, the tokens can be slashed until the maturity round
+type unbondingPool struct {
+	PoolID        datastore.Key `json:"pool_id"`
+	NodeID        datastore.Key `json:"node_id"`
+	Balance       state.Balance `json:"balance"`
+	MaturityRound int64         `json:"maturity_round"`
+}
+
+func (up *unbondingPool) isMature(round int64) bool {
+	return up.MaturityRound <= round
+}
+
+// unbondingPools is queue of unbonding pools of a delegate
+type unbondingPools struct {
+	DelegateID datastore.Key    `json:"delegate_id"`
+	Pools      []*unbondingPool `json:"pools"`
+}
+
+func unbondingPoolsKey(delegateID datastore.Key) datastore.Key {
+	return globalKeyHash("unbonding_pools:" + delegateID)
+}
+
+func (ups *unbondingPools) Encode() []byte {
+	var b, err = json.Marshal(ups)
+	if err != nil {
+		panic(err) // must never happen
+	}
+	return b
+}
+
+func (ups *unbondingPools) Decode(b []byte) error {
+	return json.Unmarshal(b, ups)
+}
+
+func (ups *unbondingPools) save(balances cstate.StateContextI) (err error) {
+	if len(ups.Pools) == 0 {
+		_, err = balances.DeleteTrieNode(unbondingPoolsKey(ups.DelegateID))
+		return
+	}
+	_, err = balances.InsertTrieNode(unbondingPoolsKey(ups.DelegateID), ups)
+	return
+}
+
+// add to the queue keeping it ordered by maturity round
+func (ups *unbondingPools) add(up *unbondingPool) {
+	var i = len(ups.Pools)
+	for i > 0 && ups.Pools[i-1].MaturityRound > up.MaturityRound {
+		i--
+	}
+	ups.Pools = append(ups.Pools, nil)
+	copy(ups.Pools[i+1:], ups.Pools[i:])
+	ups.Pools[i] = up
+}
+
+// take all matured pools off the queue
+func (ups *unbondingPools) takeMature(round int64) (
+	mature []*unbondingPool, total state.Balance) {
+
+	var i int
+	for _, up := range ups.Pools {
+		if up.isMature(round) {
+			mature, total = append(mature, up), total+up.Balance
+			continue
+		}
+		ups.Pools[i], i = up, i+1
+	}
+	ups.Pools = ups.Pools[:i]
+	return
+}
+
+// slash given ratio of not matured pools of given node
+func (ups *unbondingPools) slash(nodeID datastore.Key, ratio float64,
+	round int64) (slashed state.Balance, left bool) {
+
+	for _, up := range ups.Pools {
+		if up.NodeID != nodeID || up.isMature(round) {
+			continue
+		}
+		var value = state.Balance(float64(up.Balance) * ratio)
+		up.Balance -= value
+		slashed += value
+		left = true
+	}
+	return
+}
+
+func getUnbondingPools(delegateID datastore.Key,
+	balances cstate.StateContextI) (ups *unbondingPools, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(unbondingPoolsKey(delegateID))
+	if err != nil && err != util.ErrValueNotPresent {
+		return
+	}
+	ups = &unbondingPools{DelegateID: delegateID}
+	if err == util.ErrValueNotPresent {
+		return ups, nil
+	}
+	if err = ups.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return
+}
+
+// unbond moves tokens of the unlocked delegate pool to the unbonding
+// queue of the delegate; the tokens are kept by the SC until the maturity
+// round
+func (msc *MinerSmartContract) unbond(mn *MinerNode, pool *sci.DelegatePool,
+	maturity int64, balances cstate.StateContextI) (err error) {
+
+	var ups *unbondingPools
+	if ups, err = getUnbondingPools(pool.DelegateID, balances); err != nil {
+		return fmt.Errorf("getting unbonding pools: %v", err)
+	}
+	ups.add(&unbondingPool{
+		PoolID:        pool.ID,
+		NodeID:        mn.ID,
+		Balance:       pool.Balance,
+		MaturityRound: maturity,
+	})
+	if err = ups.save(balances); err != nil {
+		return fmt.Errorf("saving unbonding pools: %v", err)
+	}
+	pool.Balance = 0
+	mn.Unbonding[pool.DelegateID] = maturity
+	return
+}
+
+// slashUnbonding slashes the equivocation slash ratio of tokens unbonding
+// from the node, the tokens are burned
+func (msc *MinerSmartContract) slashUnbonding(mn *MinerNode, gn *GlobalNode,
+	round int64, balances cstate.StateContextI) (
+	slashed state.Balance, err error) {
+
+	for delegateID, maturity := range mn.Unbonding {
+		if maturity <= round {
+			delete(mn.Unbonding, delegateID) // all matured
+			continue
+		}
+		var ups *unbondingPools
+		if ups, err = getUnbondingPools(delegateID, balances); err != nil {
+			return 0, fmt.Errorf("getting unbonding pools: %v", err)
+		}
+		var value, left = ups.slash(mn.ID, gn.EquivocationSlash, round)
+		if !left {
+			delete(mn.Unbonding, delegateID) // claimed
+			continue
+		}
+		if err = ups.save(balances); err != nil {
+			return 0, fmt.Errorf("saving unbonding pools: %v", err)
+		}
+		slashed += value
+	}
+	if err = burn(slashed, gn, balances); err != nil {
+		return 0, err
+	}
+	return
+}
+
+// claimUnbonded transfers all tokens passed the unbonding period to the
+// delegate
+func (msc *MinerSmartContract) claimUnbonded(t *transaction.Transaction,
+	_ []byte, _ *GlobalNode, balances cstate.StateContextI) (
+	resp string, err error) {
+
+	var ups *unbondingPools
+	if ups, err = getUnbondingPools(t.ClientID, balances); err != nil {
+		return "", common.NewError("claim_unbonded_failed",
+			"getting unbonding pools: "+err.Error())
+	}
+
+	var _, total = ups.takeMature(balances.GetBlock().Round)
+	if total == 0 {
+		return "", common.NewError("claim_unbonded_failed",
+			"no tokens to claim")
+	}
+
+	var transfer = state.NewTransfer(ADDRESS, t.ClientID, total)
+	if err = balances.AddTransfer(transfer); err != nil {
+		return "", common.NewError("claim_unbonded_failed",
+			"adding transfer: "+err.Error())
+	}
+	if err = ups.save(balances); err != nil {
+		return "", common.NewError("claim_unbonded_failed",
+			"saving unbonding pools: "+err.Error())
+	}
+
+	return string(transfer.Encode()), nil
+}
+
+// unbondingPoolsStat is unbonding queue of a delegate with totals
+type unbondingPoolsStat struct {
+	Round     int64            `json:"round"`
+	Claimable state.Balance    `json:"claimable"`
+	Unbonding state.Balance    `json:"unbonding"`
+	Pools     []*unbondingPool `json:"pools"`
+}
+
+// GetUnbondingPoolsHandler returns unbonding queue of a delegate
+func (msc *MinerSmartContract) GetUnbondingPoolsHandler(ctx context.Context,
+	params url.Values, balances cstate.StateContextI) (
+	resp interface{}, err error) {
+
+	var (
+		clientID = params.Get("client_id")
+		ups      *unbondingPools
+	)
+	if ups, err = getUnbondingPools(clientID, balances); err != nil {
+		return nil, common.NewErrInternal("can't get unbonding pools",
+			err.Error())
+	}
+
+	var stat = &unbondingPoolsStat{
+		Round: balances.GetBlock().Round,
+		Pools: ups.Pools,
+	}
+	if stat.Pools == nil {
+		stat.Pools = []*unbondingPool{}
+	}
+	for _, up := range ups.Pools {
+		if up.isMature(stat.Round) {
+			stat.Claimable += up.Balance
+		} else {
+			stat.Unbonding += up.Balance
+		}
+	}
+	return stat, nil
+}
@@ -0,0 +1,120 @@
+package minersc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinerSmartContract_unbonding(t *testing.T) {
+	var (
+		balances = newTestBalances()
+		msc      = newTestMinerSC()
+		now      = int64(10000)
+		err      error
+	)
+
+	var gn = setConfig(t, balances)
+	gn.UnbondingPeriod = 50
+	gn.EquivocationSlash = 0.1
+	mustSave(t, GlobalNodeKey, gn, balances)
+
+	var (
+		mn     = newMiner(t, msc, now, 1, 100e10, balances)
+		staker = mn.stakers[0]
+	)
+	_, err = staker.callAddToDelegatePool(t, msc, now, 10e10, mn.miner.id,
+		balances)
+	require.NoError(t, err)
+
+	var node *MinerNode
+	node, err = getMinerNode(mn.miner.id, balances)
+	require.NoError(t, err)
+	msc.activatePending(node)
+	require.NoError(t, node.save(balances))
+
+	var poolID string
+	for id := range node.Active {
+		poolID = id
+	}
+
+	// delete, it's unbonding since next VC
+	var tx = newTransaction(staker.id, ADDRESS, 0, now)
+	balances.txn = tx
+	var resp string
+	resp, err = msc.deleteFromDelegatePool(tx, mustEncode(t, &deletePool{
+		MinerID: mn.miner.id,
+		PoolID:  poolID,
+	}), gn, balances)
+	require.NoError(t, err)
+	assert.Equal(t, `{"action": "pool will be unbonding next VC for 50 rounds"}`,
+		resp)
+
+	node, err = getMinerNode(mn.miner.id, balances)
+	require.NoError(t, err)
+	require.NoError(t, msc.unlockDeleted(node, 100, gn, balances))
+	require.NoError(t, node.save(balances))
+	assert.Zero(t, node.TotalStaked)
+	assert.EqualValues(t, 150, node.Unbonding[staker.id])
+	assert.EqualValues(t, 90e10, balances.balances[staker.id]) // not yet
+
+	var setRound = func(round int64) {
+		balances.block = block.Provider().(*block.Block)
+		balances.block.Round = round
+	}
+
+	var stat = func() *unbondingPoolsStat {
+		var resp, err = msc.GetUnbondingPoolsHandler(context.Background(),
+			url.Values{"client_id": []string{staker.id}}, balances)
+		require.NoError(t, err)
+		return resp.(*unbondingPoolsStat)
+	}
+
+	var claim = func() (err error) {
+		var tx = newTransaction(staker.id, ADDRESS, 0, now)
+		balances.txn = tx
+		_, err = msc.claimUnbonded(tx, nil, gn, balances)
+		return
+	}
+
+	setRound(120)
+	var st = stat()
+	require.Len(t, st.Pools, 1)
+	assert.EqualValues(t, 150, st.Pools[0].MaturityRound)
+	assert.EqualValues(t, 10e10, st.Unbonding)
+	assert.Zero(t, st.Claimable)
+
+	var err2 = claim()
+	require.Error(t, err2)
+	assert.Equal(t, "claim_unbonded_failed: no tokens to claim", err2.Error())
+
+	// unbonding tokens are slashable
+	var slashed state.Balance
+	slashed, err = msc.slashUnbonding(node, gn, 120, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1e10, slashed)
+	assert.EqualValues(t, 1e10, balances.balances[BurnAddress])
+	assert.EqualValues(t, 1e10, gn.Burned)
+
+	setRound(150)
+	st = stat()
+	assert.EqualValues(t, 9e10, st.Claimable)
+	assert.Zero(t, st.Unbonding)
+
+	require.NoError(t, claim())
+	assert.EqualValues(t, 99e10, balances.balances[staker.id])
+	require.Error(t, claim()) // claimed already
+
+	// matured are not slashable and removed from the node
+	slashed, err = msc.slashUnbonding(node, gn, 150, balances)
+	require.NoError(t, err)
+	assert.Zero(t, slashed)
+	assert.EqualValues(t, 1e10, gn.Burned)
+	assert.Len(t, node.Unbonding, 0)
+}
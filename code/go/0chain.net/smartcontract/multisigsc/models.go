@@ -1,8 +1,10 @@
 package multisigsc
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"strconv"
 
 	"0chain.net/chaincore/state"
 	"0chain.net/core/common"
@@ -16,6 +18,7 @@ const (
 	MaxSigners   = 20
 	MinSigners   = 2
 	MaxFieldSize = 256
+	MaxInputSize = 8 * 1024 // Max size of input of a smart contract call.
 )
 
 type Wallet struct {
@@ -148,12 +151,55 @@ func (w Wallet) isVoteAuthorized(signingClientID string, v Vote) bool {
 		return false
 	}
 
-	err := w.makeSignedTransferForVote(publicKey, v).VerifySignature(false)
+	if v.Call == nil && v.Update == nil {
+		err := w.makeSignedTransferForVote(publicKey, v).VerifySignature(false)
+		return err == nil
+	}
+
+	hash := actionHash(v.Transfer, v.Call, v.Update)
+	return w.verifySignature(publicKey, v.Signature, hash) == nil
+}
+
+// Hash signed by the signers of a proposal. A plain transfer is signed as a
+// signed transfer to be validated by the blockchain, a smart contract call or
+// a wallet update is signed along with the transfer, so the signers approve
+// exactly what is executed.
+func actionHash(t state.Transfer, c *scCall, u *walletUpdate) string {
+	hash := encryption.Hash(t.Encode())
+	switch {
+	case c != nil:
+		data, _ := json.Marshal(c)
+		return encryption.Hash(hash + ":call:" + encryption.Hash(data))
+	case u != nil:
+		data, _ := json.Marshal(u)
+		return encryption.Hash(hash + ":update:" + encryption.Hash(data))
+	}
+	return hash
+}
+
+func (w Wallet) verifySignature(publicKey, sig, hash string) error {
+	scheme := encryption.GetSignatureScheme(w.SignatureScheme)
+	if err := scheme.SetPublicKey(publicKey); err != nil {
+		return common.NewError("invalid_public_key", "invalid public key")
+	}
+	ok, err := scheme.Verify(sig, hash)
 	if err != nil {
-		return false
+		return err
+	}
+	if !ok {
+		return common.NewError("invalid_signature", "invalid signature")
 	}
+	return nil
+}
 
-	return true
+// Verify the threshold signature of a smart contract call or a wallet update
+// proposal; transfers are verified by the blockchain.
+func (w Wallet) verifyProposalSignature(p proposal) error {
+	if !isPublicKeyForClientID(w.PublicKey, p.Transfer.ClientID) {
+		return common.NewError("wrong_public_key", "public key does not match client id")
+	}
+	return w.verifySignature(w.PublicKey, p.ClientSignature,
+		actionHash(p.Transfer, p.Call, p.Update))
 }
 
 func (w Wallet) makeSignedTransferForVote(signingPublicKey string, v Vote) state.SignedTransfer {
@@ -248,21 +294,251 @@ func (w Wallet) constructTransferSignature(p proposal) (string, error) {
 		}
 	}
 
-	// All of the SignerSignatures are signatures on the transfer, which means
-	// this reconstructed signature will be, too.
+	// All of the SignerSignatures are signatures on the transfer, or on the
+	// call or update with the transfer, which means this reconstructed
+	// signature will be, too.
 	return rec.Reconstruct()
 }
 
+// Smart contract call made on behalf of a multi-sig wallet.
+type scCall struct {
+	Address      string          `json:"address"`
+	FunctionName string          `json:"function_name"`
+	Input        json.RawMessage `json:"input"`
+}
+
+func (c *scCall) equal(o *scCall) bool {
+	if c == nil || o == nil {
+		return c == o
+	}
+	return c.Address == o.Address && c.FunctionName == o.FunctionName &&
+		bytes.Equal(c.Input, o.Input)
+}
+
+// Signer of a multi-sig wallet: threshold ID and public key of the signer's
+// key share.
+type signer struct {
+	ThresholdID string `json:"threshold_id"`
+	PublicKey   string `json:"public_key"`
+}
+
+// Change of signers of a multi-sig wallet. An added signer should hold a share
+// of the wallet's key. The threshold signature can only be reconstructed from
+// shares of a polynomial of degree NumRequired-1, so a change of the threshold
+// must carry the whole set of signers with the wallet key re-shared for the new
+// threshold, along with each new share's signature of reshareHash proving that
+// the shares reconstruct the wallet's signature.
+type walletUpdate struct {
+	AddSigner    *signer `json:"add_signer,omitempty"`
+	RemoveSigner string  `json:"remove_signer,omitempty"` // Threshold ID.
+	NumRequired  int     `json:"num_required,omitempty"`
+
+	// Re-shared signers replacing the current ones and their signatures.
+	Signers          []*signer `json:"signers,omitempty"`
+	SignerSignatures []string  `json:"signer_signatures,omitempty"`
+}
+
+func (u *walletUpdate) equal(o *walletUpdate) bool {
+	if u == nil || o == nil {
+		return u == o
+	}
+	if (u.AddSigner == nil) != (o.AddSigner == nil) ||
+		(u.AddSigner != nil && *u.AddSigner != *o.AddSigner) {
+		return false
+	}
+	if len(u.Signers) != len(o.Signers) ||
+		len(u.SignerSignatures) != len(o.SignerSignatures) {
+		return false
+	}
+	for i := range u.Signers {
+		if u.Signers[i] == nil || o.Signers[i] == nil {
+			if u.Signers[i] != o.Signers[i] {
+				return false
+			}
+			continue
+		}
+		if *u.Signers[i] != *o.Signers[i] {
+			return false
+		}
+	}
+	for i := range u.SignerSignatures {
+		if u.SignerSignatures[i] != o.SignerSignatures[i] {
+			return false
+		}
+	}
+	return u.RemoveSigner == o.RemoveSigner && u.NumRequired == o.NumRequired
+}
+
+func (u *walletUpdate) isEmpty() bool {
+	return u.AddSigner == nil && u.RemoveSigner == "" && u.NumRequired == 0 &&
+		len(u.Signers) == 0
+}
+
+func (u *walletUpdate) isReshare() bool {
+	return len(u.Signers) > 0
+}
+
+// Returns the wallet with the update applied. The wallet is not validated.
+func (u *walletUpdate) apply(w Wallet) (Wallet, error) {
+	if u.isReshare() {
+		if u.AddSigner != nil || u.RemoveSigner != "" {
+			return Wallet{}, common.NewError("err_update_reshare", "re-shared signers can't be combined with adding or removing a signer")
+		}
+		ids := make([]string, 0, len(u.Signers))
+		keys := make([]string, 0, len(u.Signers))
+		for _, s := range u.Signers {
+			if s == nil {
+				return Wallet{}, common.NewError("err_update_reshare", "missing re-shared signer")
+			}
+			ids = append(ids, s.ThresholdID)
+			keys = append(keys, s.PublicKey)
+		}
+		if u.NumRequired != 0 {
+			w.NumRequired = u.NumRequired
+		}
+		w.SignerThresholdIDs, w.SignerPublicKeys = ids, keys
+		return w, nil
+	}
+
+	ids := append([]string{}, w.SignerThresholdIDs...)
+	keys := append([]string{}, w.SignerPublicKeys...)
+
+	if u.RemoveSigner != "" {
+		found := false
+		for i, id := range ids {
+			if id == u.RemoveSigner {
+				ids = append(ids[:i], ids[i+1:]...)
+				keys = append(keys[:i], keys[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return Wallet{}, common.NewError("err_update_no_signer", "no signer with threshold id "+u.RemoveSigner)
+		}
+	}
+	if u.AddSigner != nil {
+		ids = append(ids, u.AddSigner.ThresholdID)
+		keys = append(keys, u.AddSigner.PublicKey)
+	}
+	if u.NumRequired != 0 {
+		w.NumRequired = u.NumRequired
+	}
+
+	w.SignerThresholdIDs, w.SignerPublicKeys = ids, keys
+	return w, nil
+}
+
+// Hash signed by each re-shared signer's key share. It binds the shares to the
+// wallet and the new threshold.
+func (u *walletUpdate) reshareHash(clientID string, numRequired int) string {
+	data, _ := json.Marshal(u.Signers)
+	return encryption.Hash(clientID + ":reshare:" + strconv.Itoa(numRequired) +
+		":" + encryption.Hash(data))
+}
+
+// Verify that the re-shared signers of the updated wallet hold shares of the
+// wallet's key for the updated threshold: every signature share must be valid,
+// and the wallet's signature must be reconstructed from the first
+// NumRequired-1 shares with any one of the others. Then all of the shares lie
+// on the same polynomial of degree NumRequired-1 whose y-intercept is the
+// wallet's key.
+func (u *walletUpdate) verifyReshare(w, updated Wallet) error {
+	n := len(u.Signers)
+	t := updated.NumRequired
+	if len(u.SignerSignatures) != n {
+		return common.NewError("err_update_reshare", "number of re-shared signers and signatures do not match")
+	}
+	if t < MinSigners || t > n {
+		return common.NewError("err_update_reshare", "invalid number of required signers")
+	}
+
+	hash := u.reshareHash(w.ClientID, t)
+	shares := make([]encryption.ThresholdSignatureScheme, 0, n)
+	for i, s := range u.Signers {
+		err := w.verifySignature(s.PublicKey, u.SignerSignatures[i], hash)
+		if err != nil {
+			return common.NewError("err_update_reshare", "invalid signature of signer "+s.ThresholdID+": "+err.Error())
+		}
+		tss := encryption.GetThresholdSignatureScheme(w.SignatureScheme)
+		if err := tss.SetPublicKey(s.PublicKey); err != nil {
+			return err
+		}
+		if err := tss.SetID(s.ThresholdID); err != nil {
+			return err
+		}
+		shares = append(shares, tss)
+	}
+
+	for j := t - 1; j < n; j++ {
+		rec := encryption.GetReconstructSignatureScheme(w.SignatureScheme, t, n)
+		for i := 0; i < t-1; i++ {
+			if err := rec.Add(shares[i], u.SignerSignatures[i]); err != nil {
+				return err
+			}
+		}
+		if err := rec.Add(shares[j], u.SignerSignatures[j]); err != nil {
+			return err
+		}
+		sig, err := rec.Reconstruct()
+		if err != nil {
+			return err
+		}
+		if err := w.verifySignature(w.PublicKey, sig, hash); err != nil {
+			return common.NewError("err_update_reshare", "signer "+u.Signers[j].ThresholdID+" doesn't hold a share of the wallet key")
+		}
+	}
+	return nil
+}
+
 type Vote struct {
 	ProposalID string `json:"proposal_id"`
 
 	// Client ID in transfer is that of the multi-sig wallet, not the signer.
+	// For a smart contract call the transfer is the value of the call sent to
+	// the smart contract, for a wallet update it's a zero transfer to the
+	// multi-sig smart contract.
 	Transfer state.Transfer `json:"transfer"`
 
+	// Optional smart contract call or wallet update proposed instead of a
+	// plain transfer.
+	Call   *scCall       `json:"call,omitempty"`
+	Update *walletUpdate `json:"update,omitempty"`
+
+	// Signature of the transfer, or of the call or update with the transfer
+	// (see actionHash).
 	Signature string `json:"signature"`
 }
 
 func (v Vote) notTooBig() bool {
+	if v.Call != nil && (len(v.Call.Address) > MaxFieldSize ||
+		len(v.Call.FunctionName) > MaxFieldSize ||
+		len(v.Call.Input) > MaxInputSize) {
+		return false
+	}
+	if v.Update != nil && (len(v.Update.RemoveSigner) > MaxFieldSize ||
+		v.Update.AddSigner != nil &&
+			(len(v.Update.AddSigner.ThresholdID) > MaxFieldSize ||
+				len(v.Update.AddSigner.PublicKey) > MaxFieldSize)) {
+		return false
+	}
+	if v.Update != nil && (len(v.Update.Signers) > MaxSigners ||
+		len(v.Update.SignerSignatures) > MaxSigners) {
+		return false
+	}
+	if v.Update != nil {
+		for _, s := range v.Update.Signers {
+			if s != nil && (len(s.ThresholdID) > MaxFieldSize ||
+				len(s.PublicKey) > MaxFieldSize) {
+				return false
+			}
+		}
+		for _, sig := range v.Update.SignerSignatures {
+			if len(sig) > MaxFieldSize {
+				return false
+			}
+		}
+	}
 	return len(v.ProposalID) <= MaxFieldSize &&
 		len(v.Transfer.ClientID) <= MaxFieldSize &&
 		len(v.Transfer.ToClientID) <= MaxFieldSize &&
@@ -270,9 +546,41 @@ func (v Vote) notTooBig() bool {
 }
 
 func (v Vote) hasValidAmount() bool {
+	switch {
+	case v.Call != nil:
+		return v.Transfer.Amount >= 0
+	case v.Update != nil:
+		return v.Transfer.Amount == 0
+	}
 	return v.Transfer.Amount > 0
 }
 
+// Checks that a smart contract call or a wallet update is well formed.
+func (v Vote) isValidAction() error {
+	switch {
+	case v.Call != nil && v.Update != nil:
+		return common.NewError("err_vote_invalid_action", "a vote can't propose both a call and an update")
+	case v.Call != nil:
+		if v.Call.Address == "" || v.Call.FunctionName == "" {
+			return common.NewError("err_vote_invalid_call", "missing smart contract address or function name")
+		}
+		if v.Call.Address == Address {
+			return common.NewError("err_vote_invalid_call", "can't call the multi-sig smart contract")
+		}
+		if v.Transfer.ToClientID != v.Call.Address {
+			return common.NewError("err_vote_invalid_call", "transfer should be to the called smart contract")
+		}
+	case v.Update != nil:
+		if v.Update.isEmpty() {
+			return common.NewError("err_vote_invalid_update", "empty wallet update")
+		}
+		if v.Transfer.ToClientID != Address {
+			return common.NewError("err_vote_invalid_update", "transfer should be to the multi-sig smart contract")
+		}
+	}
+	return nil
+}
+
 func (v Vote) hasSignature() bool {
 	return v.Signature != ""
 }
@@ -285,7 +593,8 @@ func (v Vote) getProposalRef() proposalRef {
 }
 
 func (v Vote) isCompatibleWithProposal(p proposal) bool {
-	return v.Transfer == p.Transfer
+	return v.Transfer == p.Transfer && v.Call.equal(p.Call) &&
+		v.Update.equal(p.Update)
 }
 
 // Uniquely identifies a proposal. Can be used to refer to one.
@@ -304,7 +613,8 @@ func (pr *proposalRef) Decode(input []byte) error {
 	return err
 }
 
-// Proposal to transfer tokens out of the multi-sig wallet. Built up from T
+// Proposal to transfer tokens out of the multi-sig wallet, to call a smart
+// contract on behalf of the wallet or to update the wallet. Built up from T
 // different votes.
 type proposal struct {
 	// Proposal ID is unique only within a single multi-sig wallet. Globally, a
@@ -318,6 +628,9 @@ type proposal struct {
 
 	Transfer state.Transfer `json:"transfer"`
 
+	Call   *scCall       `json:"call,omitempty"`
+	Update *walletUpdate `json:"update,omitempty"`
+
 	// Pertinent data from votes.
 	SignerThresholdIDs []string `json:"signer_threshold_ids"`
 	SignerSignatures   []string `json:"signer_signatures"`
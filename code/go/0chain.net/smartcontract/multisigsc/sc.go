@@ -155,6 +155,9 @@ func (ms MultiSigSmartContract) vote(currentTxnHash, signingClientID string, now
 	if !v.hasSignature() {
 		return "", common.NewError("err_vote_no_signature", " must sign vote")
 	}
+	if err := v.isValidAction(); err != nil {
+		return "", err
+	}
 
 	// Every vote is associated with a proposal. If an appropriate proposal does
 	// not exist yet, create one.
@@ -231,11 +234,28 @@ func (ms MultiSigSmartContract) vote(currentTxnHash, signingClientID string, now
 
 	p.ClientSignature = thresholdSignature
 
-	// Request the transfer. The blockchain will validate the signature and
-	// execute the transfer soon. If the signature is found to be invalid,
-	// this vote transaction will fail.
-	signedTransfer := w.makeSignedTransferForProposal(p)
-	balances.AddSignedTransfer(&signedTransfer)
+	var msg string
+	switch {
+	case p.Call != nil:
+		resp, err := ms.executeCall(currentTxnHash, now, w, p, balances)
+		if err != nil {
+			return "", err
+		}
+		msg = "success 0: call executed with signature " + p.ClientSignature + ": " + resp
+	case p.Update != nil:
+		err = ms.updateWallet(w, p, balances)
+		if err != nil {
+			return "", err
+		}
+		msg = "success 0: wallet updated with signature " + p.ClientSignature
+	default:
+		// Request the transfer. The blockchain will validate the signature and
+		// execute the transfer soon. If the signature is found to be invalid,
+		// this vote transaction will fail.
+		signedTransfer := w.makeSignedTransferForProposal(p)
+		balances.AddSignedTransfer(&signedTransfer)
+		msg = "success 0: transfer executed with signature " + p.ClientSignature
+	}
 
 	// Save the proposal again.
 	p.ExecutedInTxnHash = currentTxnHash
@@ -246,10 +266,88 @@ func (ms MultiSigSmartContract) vote(currentTxnHash, signingClientID string, now
 		return "", err
 	}
 
-	msg := "success 0: transfer executed with signature " + p.ClientSignature
 	return msg, nil
 }
 
+// Execute the smart contract call of a proposal on behalf of the wallet. The
+// call is made with a nested state context, so the called smart contract sees
+// the wallet as the client and may transfer up to the value of the proposal
+// from it. If the call fails, this vote transaction fails.
+func (ms MultiSigSmartContract) executeCall(currentTxnHash string, now common.Timestamp, w Wallet, p proposal, balances state.StateContextI) (string, error) {
+	// Unlike transfers, calls are not validated by the blockchain later.
+	err := w.verifyProposalSignature(p)
+	if err != nil {
+		return "", common.NewError("err_vote_call", " invalid threshold signature: "+err.Error())
+	}
+
+	nested, ok := balances.(state.NestedStateContextI)
+	if !ok {
+		return "", common.NewError("err_vote_call", " smart contract calls are not supported")
+	}
+
+	sc := smartcontract.GetSmartContract(p.Call.Address)
+	if sc == nil {
+		return "", common.NewError("err_vote_call", " invalid smart contract address")
+	}
+
+	t := &transaction.Transaction{
+		ClientID:     w.ClientID,
+		PublicKey:    w.PublicKey,
+		ToClientID:   p.Call.Address,
+		Value:        int64(p.Transfer.Amount),
+		CreationDate: now,
+	}
+	t.Hash = currentTxnHash
+
+	callBalances := nested.NestedStateContext(t)
+	resp, err := smartcontract.ExecuteWithStats(sc, t, p.Call.FunctionName, p.Call.Input, callBalances)
+	if err != nil {
+		return "", common.NewError("err_vote_call", " "+p.Call.FunctionName+" failed: "+err.Error())
+	}
+
+	err = nested.CommitNested(callBalances)
+	if err != nil {
+		return "", common.NewError("err_vote_call", " "+err.Error())
+	}
+
+	return resp, nil
+}
+
+// Apply the signers or threshold change of a proposal to the wallet.
+func (ms MultiSigSmartContract) updateWallet(w Wallet, p proposal, balances state.StateContextI) error {
+	err := w.verifyProposalSignature(p)
+	if err != nil {
+		return common.NewError("err_vote_update", " invalid threshold signature: "+err.Error())
+	}
+
+	updated, err := p.Update.apply(w)
+	if err != nil {
+		return err
+	}
+
+	// Signature shares of the signers can't be used with another threshold, so
+	// it can only be changed along with the key shares.
+	if p.Update.isReshare() {
+		err = p.Update.verifyReshare(w, updated)
+		if err != nil {
+			return common.NewError("err_vote_update", " invalid re-shared signers: "+err.Error())
+		}
+	} else if updated.NumRequired != w.NumRequired {
+		return common.NewError("err_vote_update", " changing the number of required signers requires re-sharing the wallet key")
+	}
+
+	isValid, err := updated.valid(w.ClientID)
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return common.NewError("err_vote_update", " invalid wallet update")
+	}
+
+	// I/O error if any.
+	return ms.putWallet(updated, balances)
+}
+
 // Prune the oldest proposal if it has expired.
 func (ms MultiSigSmartContract) pruneExpirationQueue(now common.Timestamp, balances state.StateContextI) error {
 	q, err := ms.getOrCreateExpirationQueue(balances)
@@ -392,6 +490,8 @@ func (ms MultiSigSmartContract) createProposal(now common.Timestamp, v Vote, bal
 		Prev: q.Tail,
 
 		Transfer: v.Transfer,
+		Call:     v.Call,
+		Update:   v.Update,
 
 		SignerThresholdIDs: []string{},
 		SignerSignatures:   []string{},
@@ -0,0 +1,229 @@
+package multisigsc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"0chain.net/chaincore/block"
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	logging.InitLogging("testing")
+}
+
+type testMultiSigWallet struct {
+	Wallet
+	groupKey  encryption.SignatureScheme
+	signers   []encryption.ThresholdSignatureScheme
+	signerIDs []string // client IDs of the signers
+}
+
+func clientIDForKey(t *testing.T, publicKey string) string {
+	b, err := hex.DecodeString(publicKey)
+	require.NoError(t, err)
+	return encryption.Hash(b)
+}
+
+func newTestMultiSigWallet(t *testing.T, numRequired, numSigners int) (
+	tw *testMultiSigWallet) {
+
+	const scheme = "bls0chain"
+	groupKey := encryption.GetSignatureScheme(scheme)
+	require.NoError(t, groupKey.GenerateKeys())
+	signers, err := encryption.GenerateThresholdKeyShares(scheme,
+		numRequired, numSigners, groupKey)
+	require.NoError(t, err)
+
+	tw = &testMultiSigWallet{groupKey: groupKey, signers: signers}
+	tw.ClientID = clientIDForKey(t, groupKey.GetPublicKey())
+	tw.SignatureScheme = scheme
+	tw.PublicKey = groupKey.GetPublicKey()
+	tw.NumRequired = numRequired
+	for _, s := range signers {
+		tw.SignerThresholdIDs = append(tw.SignerThresholdIDs, s.GetID())
+		tw.SignerPublicKeys = append(tw.SignerPublicKeys, s.GetPublicKey())
+		tw.signerIDs = append(tw.signerIDs, clientIDForKey(t, s.GetPublicKey()))
+	}
+	return
+}
+
+func (tw *testMultiSigWallet) vote(t *testing.T, i int, proposalID string,
+	transfer state.Transfer, update *walletUpdate, hash string) []byte {
+
+	sig, err := tw.signers[i].Sign(hash)
+	require.NoError(t, err)
+	input, err := json.Marshal(&Vote{
+		ProposalID: proposalID,
+		Transfer:   transfer,
+		Update:     update,
+		Signature:  sig,
+	})
+	require.NoError(t, err)
+	return input
+}
+
+// Shares of the key among numSigners signers with the given threshold.
+func (tw *testMultiSigWallet) reshareKey(t *testing.T,
+	key encryption.SignatureScheme, numRequired, numSigners int) []encryption.ThresholdSignatureScheme {
+
+	shares, err := encryption.GenerateThresholdKeyShares(tw.SignatureScheme,
+		numRequired, numSigners, key)
+	require.NoError(t, err)
+	return shares
+}
+
+// Wallet update replacing the signers with the shares, signed by the shares.
+func (tw *testMultiSigWallet) reshare(t *testing.T,
+	shares []encryption.ThresholdSignatureScheme, numRequired int) *walletUpdate {
+
+	u := &walletUpdate{NumRequired: numRequired}
+	for _, s := range shares {
+		u.Signers = append(u.Signers, &signer{
+			ThresholdID: s.GetID(),
+			PublicKey:   s.GetPublicKey(),
+		})
+	}
+	hash := u.reshareHash(tw.ClientID, numRequired)
+	for _, s := range shares {
+		sig, err := s.Sign(hash)
+		require.NoError(t, err)
+		u.SignerSignatures = append(u.SignerSignatures, sig)
+	}
+	return u
+}
+
+func newTestBalances() cstate.StateContextI {
+	db := util.NewLevelNodeDB(util.NewMemoryNodeDB(), util.NewMemoryNodeDB(), false)
+	b := &block.Block{}
+	b.CreationDate = common.Now()
+	return cstate.NewStateContext(b, util.NewMerklePatriciaTrie(db, 1),
+		&state.Deserializer{}, &transaction.Transaction{}, nil, nil, nil, nil)
+}
+
+func TestActionHash(t *testing.T) {
+	var (
+		tr     = state.Transfer{ClientID: "wallet", ToClientID: "sc", Amount: 1}
+		call   = &scCall{Address: "sc", FunctionName: "f", Input: []byte(`{}`)}
+		update = &walletUpdate{RemoveSigner: "id"}
+	)
+	assert.Equal(t, encryption.Hash(tr.Encode()), actionHash(tr, nil, nil))
+	hashes := map[string]bool{
+		actionHash(tr, nil, nil):    true,
+		actionHash(tr, call, nil):   true,
+		actionHash(tr, nil, update): true,
+		actionHash(tr, &scCall{Address: "sc", FunctionName: "f",
+			Input: []byte(`{"a":1}`)}, nil): true,
+		actionHash(tr, nil, &walletUpdate{RemoveSigner: "other"}): true,
+	}
+	assert.Len(t, hashes, 5)
+}
+
+func TestMultiSigSmartContract_updateWallet(t *testing.T) {
+	var (
+		ms       MultiSigSmartContract
+		balances = newTestBalances()
+		tw       = newTestMultiSigWallet(t, 2, 3)
+		now      = balances.GetBlock().CreationDate
+		transfer = state.Transfer{ClientID: tw.ClientID, ToClientID: Address}
+	)
+	require.NoError(t, ms.putWallet(tw.Wallet, balances))
+
+	// the signature should cover the update
+	remove := &walletUpdate{RemoveSigner: tw.SignerThresholdIDs[2]}
+	_, err := ms.vote("txn1", tw.signerIDs[0], now, tw.vote(t, 0, "p1",
+		transfer, remove, encryption.Hash(transfer.Encode())), balances)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "err_vote_auth")
+
+	// the threshold can't be changed without re-sharing the key
+	threshold := &walletUpdate{NumRequired: 3}
+	hash := actionHash(transfer, nil, threshold)
+	_, err = ms.vote("txn2", tw.signerIDs[0], now,
+		tw.vote(t, 0, "p2", transfer, threshold, hash), balances)
+	require.NoError(t, err)
+	_, err = ms.vote("txn3", tw.signerIDs[1], now,
+		tw.vote(t, 1, "p2", transfer, threshold, hash), balances)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "re-sharing")
+
+	hash = actionHash(transfer, nil, remove)
+	_, err = ms.vote("txn4", tw.signerIDs[0], now,
+		tw.vote(t, 0, "p3", transfer, remove, hash), balances)
+	require.NoError(t, err)
+	_, err = ms.vote("txn5", tw.signerIDs[1], now,
+		tw.vote(t, 1, "p3", transfer, remove, hash), balances)
+	require.NoError(t, err)
+
+	w, err := ms.getWallet(tw.ClientID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, tw.SignerThresholdIDs[:2], w.SignerThresholdIDs)
+	assert.Equal(t, 2, w.NumRequired)
+}
+
+func TestMultiSigSmartContract_reshareWallet(t *testing.T) {
+	var (
+		ms       MultiSigSmartContract
+		balances = newTestBalances()
+		tw       = newTestMultiSigWallet(t, 2, 3)
+		now      = balances.GetBlock().CreationDate
+		transfer = state.Transfer{ClientID: tw.ClientID, ToClientID: Address}
+	)
+	require.NoError(t, ms.putWallet(tw.Wallet, balances))
+
+	// shares of another key
+	otherKey := encryption.GetSignatureScheme(tw.SignatureScheme)
+	require.NoError(t, otherKey.GenerateKeys())
+	otherShares := tw.reshareKey(t, otherKey, 2, 3)
+	other := tw.reshare(t, otherShares, 2)
+	hash := actionHash(transfer, nil, other)
+	_, err := ms.vote("txn1", tw.signerIDs[0], now,
+		tw.vote(t, 0, "p1", transfer, other, hash), balances)
+	require.NoError(t, err)
+	_, err = ms.vote("txn2", tw.signerIDs[1], now,
+		tw.vote(t, 1, "p1", transfer, other, hash), balances)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid re-shared signers")
+
+	// a share of another key among the shares of the wallet key
+	shares := tw.reshareKey(t, tw.groupKey, 2, 3)
+	shares[2] = otherShares[2]
+	mixed := tw.reshare(t, shares, 2)
+	hash = actionHash(transfer, nil, mixed)
+	_, err = ms.vote("txn3", tw.signerIDs[0], now,
+		tw.vote(t, 0, "p2", transfer, mixed, hash), balances)
+	require.NoError(t, err)
+	_, err = ms.vote("txn4", tw.signerIDs[1], now,
+		tw.vote(t, 1, "p2", transfer, mixed, hash), balances)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't hold a share of the wallet key")
+
+	// re-shared wallet key
+	reshare := tw.reshare(t, tw.reshareKey(t, tw.groupKey, 3, 3), 3)
+	hash = actionHash(transfer, nil, reshare)
+	_, err = ms.vote("txn5", tw.signerIDs[0], now,
+		tw.vote(t, 0, "p3", transfer, reshare, hash), balances)
+	require.NoError(t, err)
+	_, err = ms.vote("txn6", tw.signerIDs[1], now,
+		tw.vote(t, 1, "p3", transfer, reshare, hash), balances)
+	require.NoError(t, err)
+
+	w, err := ms.getWallet(tw.ClientID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, 3, w.NumRequired)
+	require.Len(t, w.SignerPublicKeys, 3)
+	for i, s := range reshare.Signers {
+		assert.Equal(t, s.ThresholdID, w.SignerThresholdIDs[i])
+		assert.Equal(t, s.PublicKey, w.SignerPublicKeys[i])
+	}
+}
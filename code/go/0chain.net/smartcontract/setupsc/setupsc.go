@@ -7,6 +7,7 @@ import (
 	sci "0chain.net/chaincore/smartcontractinterface"
 	"0chain.net/core/viper"
 	"0chain.net/smartcontract/faucetsc"
+	"0chain.net/smartcontract/governancesc"
 	"0chain.net/smartcontract/interestpoolsc"
 	"0chain.net/smartcontract/minersc"
 	"0chain.net/smartcontract/multisigsc"
@@ -25,6 +26,7 @@ const (
 	Multisig
 	Miner
 	Vesting
+	Governance
 )
 
 var (
@@ -36,16 +38,18 @@ var (
 		"multisig",
 		"miner",
 		"vesting",
+		"governance",
 	}
 
 	SCCode = map[string]SCName{
-		"faucet":   Faucet,
-		"storage":  Storage,
-		"zrc20":    Zrc20,
-		"interest": Interest,
-		"multisig": Multisig,
-		"miner":    Miner,
-		"vesting":  Vesting,
+		"faucet":     Faucet,
+		"storage":    Storage,
+		"zrc20":      Zrc20,
+		"interest":   Interest,
+		"multisig":   Multisig,
+		"miner":      Miner,
+		"vesting":    Vesting,
+		"governance": Governance,
 	}
 )
 
@@ -79,6 +83,8 @@ func newSmartContract(name string) sci.SmartContractInterface {
 		return minersc.NewMinerSmartContract()
 	case Vesting:
 		return vestingsc.NewVestingSmartContract()
+	case Governance:
+		return governancesc.NewGovernanceSmartContract()
 	default:
 		return nil
 	}
@@ -176,6 +176,9 @@ type newAllocationRequest struct {
 	WritePriceRange            PriceRange       `json:"write_price_range"`
 	MaxChallengeCompletionTime time.Duration    `json:"max_challenge_completion_time"`
 	DiversifyBlobbers          bool             `json:"diversify_blobbers"`
+	MinReputation              float64          `json:"min_reputation"`
+	SortByReputation           bool             `json:"sort_by_reputation"`
+	StorageClass               string           `json:"storage_class"`
 }
 
 // storageAllocation from the request
@@ -193,6 +196,9 @@ func (nar *newAllocationRequest) storageAllocation() (sa *StorageAllocation) {
 	sa.WritePriceRange = nar.WritePriceRange
 	sa.MaxChallengeCompletionTime = nar.MaxChallengeCompletionTime
 	sa.DiverseBlobbers = nar.DiversifyBlobbers
+	sa.MinReputation = nar.MinReputation
+	sa.SortByReputation = nar.SortByReputation
+	sa.StorageClass = nar.StorageClass
 	return
 }
 
@@ -399,6 +405,16 @@ func (sc *StorageSmartContract) newAllocationRequestInternal(
 		return "", common.NewErrorf("allocation_creation_failed", "%v", err)
 	}
 
+	err = sc.addAllocationEvent(t, sa.ID, &AllocationEvent{
+		Type:       allocEventCreated,
+		Amount:     state.Balance(t.Value),
+		Size:       sa.Size,
+		Expiration: sa.Expiration,
+	}, balances)
+	if err != nil {
+		return "", common.NewError("allocation_creation_failed", err.Error())
+	}
+
 	return resp, err
 }
 
@@ -427,7 +443,9 @@ func (sc *StorageSmartContract) selectBlobbers(
 	var bSize = (sa.Size + int64(size-1)) / int64(size)
 	var list = sa.filterBlobbers(allBlobbersList.Nodes.copy(), creationDate,
 		bSize, filterHealthyBlobbers(creationDate),
-		sc.filterBlobbersByFreeSpace(creationDate, bSize, balances))
+		sc.filterBlobbersByFreeSpace(creationDate, bSize, balances),
+		sc.filterBlobbersByReputation(sa.MinReputation, balances),
+		filterBlobbersByStorageClass(sa.StorageClass, conf))
 
 	if len(list) < size {
 		return nil, 0, errors.New("Not enough blobbers to honor the allocation")
@@ -457,6 +475,19 @@ func (sc *StorageSmartContract) selectBlobbers(
 				}
 			}
 			blobberNodes = append(blobberNodes, sa.diversifyBlobbers(list, size-len(blobberNodes))...)
+		} else if sa.SortByReputation {
+			if err = sc.sortBlobbersByReputation(list, balances); err != nil {
+				return nil, 0, err
+			}
+			// take blobbers with the best reputation
+			for _, b := range list {
+				if len(blobberNodes) >= size {
+					break
+				}
+				if !checkExists(b, blobberNodes) {
+					blobberNodes = append(blobberNodes, b)
+				}
+			}
 		} else {
 			blobberNodes = randomizeNodes(list, blobberNodes, size, randomSeed)
 		}
@@ -978,6 +1009,12 @@ func (sc *StorageSmartContract) updateAllocationRequestInternal(
 			"can't get existing allocation: "+err.Error())
 	}
 
+	if !mintTokens && !alloc.hasPermission(t.ClientID, permManagePools) {
+		return "", common.NewError("allocation_updating_failed",
+			"only owner or co-owner with manage_pools permission can "+
+				"update the allocation")
+	}
+
 	if err = request.validate(conf, alloc); err != nil {
 		return "", common.NewError("allocation_updating_failed", err.Error())
 	}
@@ -1001,9 +1038,24 @@ func (sc *StorageSmartContract) updateAllocationRequestInternal(
 	// update allocation transaction hash
 	alloc.Tx = t.Hash
 
+	var updated = &AllocationEvent{
+		Type:       allocEventUpdated,
+		Amount:     state.Balance(t.Value),
+		Size:       request.Size,
+		Expiration: request.Expiration,
+	}
+
 	// close allocation now
 	if newExpiration <= t.CreationDate {
-		return sc.closeAllocation(t, alloc, balances)
+		if resp, err = sc.closeAllocation(t, alloc, balances); err != nil {
+			return "", err
+		}
+		err = sc.addAllocationEvent(t, alloc.ID, updated, balances)
+		if err != nil {
+			return "", common.NewError("allocation_closing_failed",
+				err.Error())
+		}
+		return resp, nil
 	}
 
 	// an allocation can't be shorter than configured in SC
@@ -1044,6 +1096,17 @@ func (sc *StorageSmartContract) updateAllocationRequestInternal(
 		return "", common.NewErrorf("allocation_reducing_failed", "%v", err)
 	}
 
+	if request.Expiration != 0 {
+		if err = sc.updateAutoRenewList(alloc, balances); err != nil {
+			return "", common.NewError("allocation_updating_failed",
+				err.Error())
+		}
+	}
+
+	if err = sc.addAllocationEvent(t, alloc.ID, updated, balances); err != nil {
+		return "", common.NewError("allocation_updating_failed", err.Error())
+	}
+
 	return string(alloc.Encode()), nil
 }
 
@@ -1269,6 +1332,13 @@ func (sc *StorageSmartContract) cancelAllocationRequest(
 			"saving allocation: "+err.Error())
 	}
 
+	err = sc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+		Type: allocEventCanceled,
+	}, balances)
+	if err != nil {
+		return "", common.NewError("alloc_cancel_failed", err.Error())
+	}
+
 	return "canceled", nil
 }
 
@@ -1343,6 +1413,13 @@ func (sc *StorageSmartContract) finalizeAllocation(
 			"saving allocation: "+err.Error())
 	}
 
+	err = sc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+		Type: allocEventFinalized,
+	}, balances)
+	if err != nil {
+		return "", common.NewError("fini_alloc_failed", err.Error())
+	}
+
 	return "finalized", nil
 }
 
@@ -1533,28 +1610,11 @@ func (sc *StorageSmartContract) curatorTransferAllocation(
 			"only curators can transfer allocations; "+txn.ClientID+" is not a curator")
 	}
 
-	if err := sc.removeUserAllocation(alloc.Owner, alloc, balances); err != nil {
-		return "", common.NewError("curator_transfer_allocation_failed", err.Error())
-	}
-
-	alloc.Owner = tai.NewOwnerId
-	alloc.OwnerPublicKey = tai.NewOwnerPublicKey
-
-	if err := sc.addUserAllocation(alloc.Owner, alloc, balances); err != nil {
-		return "", common.NewError("curator_transfer_allocation_failed", err.Error())
-	}
-
-	if !alloc.hasWritePool(sc, tai.NewOwnerId, balances) {
-		if err = sc.createEmptyWritePool(txn, alloc, balances); err != nil {
-			return "", common.NewError("curator_transfer_allocation_failed",
-				"error creating write pool: "+err.Error())
-		}
-	}
-
-	_, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
+	err = sc.transferAllocation(txn, alloc, tai.NewOwnerId,
+		tai.NewOwnerPublicKey, balances)
 	if err != nil {
-		return "", common.NewErrorf("curator_transfer_allocation_failed",
-			"saving new allocation: %v", err)
+		return "", common.NewError("curator_transfer_allocation_failed",
+			err.Error())
 	}
 
 	// txn.Hash is the id of the new token pool
@@ -0,0 +1,271 @@
+package storagesc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+)
+
+// allocation event types
+const (
+	allocEventCreated         = "created"
+	allocEventUpdated         = "updated"
+	allocEventBlobberAdded    = "blobber_added"
+	allocEventFinalized       = "finalized"
+	allocEventCanceled        = "canceled"
+	allocEventChallengePass   = "challenge_passed"
+	allocEventChallengeFail   = "challenge_failed"
+	allocEventWritePoolLock   = "write_pool_lock"
+	allocEventWritePoolUnlock = "write_pool_unlock"
+	allocEventReadPoolLock    = "read_pool_lock"
+	allocEventReadPoolUnlock  = "read_pool_unlock"
+	allocEventRollback        = "rollback"
+	allocEventTransferred     = "transferred"
+	allocEventRenewed         = "renewed"
+	allocEventRenewalFailed   = "renewal_failed"
+)
+
+const (
+	// maxAllocationEvents is number of last events of an allocation kept
+	// in the event log, older events are removed
+	maxAllocationEvents = 200
+	// allocationEventsPageSize is number of events of the log stored in
+	// one MPT node
+	allocationEventsPageSize = 20
+	// default and max number of events returned by the REST handler
+	defaultAllocationEventsLimit = 20
+	maxAllocationEventsLimit     = 100
+)
+
+func allocationEventsKey(scKey, allocID string) datastore.Key {
+	return datastore.Key(scKey + ":allocationevents:" + allocID)
+}
+
+func allocationEventsPageKey(scKey, allocID string,
+	page int64) datastore.Key {
+
+	return datastore.Key(scKey + ":allocationevents:" + allocID + ":" +
+		strconv.FormatInt(page, 10))
+}
+
+// allocationEventPage returns page and index in the page of event with
+// given sequence number
+func allocationEventPage(seq int64) (page int64, i int) {
+	return (seq - 1) / allocationEventsPageSize,
+		int((seq - 1) % allocationEventsPageSize)
+}
+
+// AllocationEvent is an event of an allocation history.
+type AllocationEvent struct {
+	// Seq is sequence number of the event, starting from 1.
+	Seq       int64            `json:"seq"`
+	Type      string           `json:"type"`
+	Tx        string           `json:"tx"`
+	ClientID  string           `json:"client_id"`
+	Timestamp common.Timestamp `json:"timestamp"`
+	// Optional event details.
+	BlobberID     string           `json:"blobber_id,omitempty"`
+	PrevBlobberID string           `json:"prev_blobber_id,omitempty"`
+	PrevOwnerID   string           `json:"prev_owner_id,omitempty"`
+	PoolID        string           `json:"pool_id,omitempty"`
+	Amount        state.Balance    `json:"amount,omitempty"`
+	Size          int64            `json:"size,omitempty"`
+	Expiration    common.Timestamp `json:"expiration,omitempty"`
+	Reason        string           `json:"reason,omitempty"`
+}
+
+// allocationEvents is bounded event log of an allocation; the events are
+// stored in pages apart from the log, the Events are set for REST responses
+// only
+type allocationEvents struct {
+	AllocationID string `json:"allocation_id"`
+	// Total number of events of the allocation, including removed ones.
+	Total  int64              `json:"total"`
+	Events []*AllocationEvent `json:"events"`
+}
+
+func newAllocationEvents(allocID string) (ae *allocationEvents) {
+	ae = new(allocationEvents)
+	ae.AllocationID = allocID
+	return
+}
+
+// Encode to []byte
+func (ae *allocationEvents) Encode() (b []byte) {
+	var err error
+	if b, err = json.Marshal(ae); err != nil {
+		panic(err) // must never happens
+	}
+	return
+}
+
+// Decode from []byte
+func (ae *allocationEvents) Decode(input []byte) error {
+	return json.Unmarshal(input, ae)
+}
+
+// allocationEventsPage is page of the event log of an allocation
+type allocationEventsPage struct {
+	Events []*AllocationEvent `json:"events"`
+}
+
+// Encode to []byte
+func (aep *allocationEventsPage) Encode() (b []byte) {
+	var err error
+	if b, err = json.Marshal(aep); err != nil {
+		panic(err) // must never happens
+	}
+	return
+}
+
+// Decode from []byte
+func (aep *allocationEventsPage) Decode(input []byte) error {
+	return json.Unmarshal(input, aep)
+}
+
+func (ae *allocationEvents) getPage(sscKey string, page int64,
+	balances chainstate.StateContextI) (aep *allocationEventsPage, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(allocationEventsPageKey(sscKey,
+		ae.AllocationID, page))
+	if err != nil {
+		return
+	}
+	aep = new(allocationEventsPage)
+	if err = aep.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return
+}
+
+// add event removing page of the oldest events if limit reached
+func (ae *allocationEvents) add(sscKey string, ev *AllocationEvent,
+	balances chainstate.StateContextI) (err error) {
+
+	ae.Total++
+	ev.Seq = ae.Total
+
+	var (
+		page, i = allocationEventPage(ae.Total)
+		aep     = new(allocationEventsPage)
+	)
+	if i > 0 {
+		if aep, err = ae.getPage(sscKey, page, balances); err != nil {
+			return
+		}
+		if len(aep.Events) != i {
+			return errors.New("corrupted allocation events page")
+		}
+	}
+	aep.Events = append(aep.Events, ev)
+	_, err = balances.InsertTrieNode(allocationEventsPageKey(sscKey,
+		ae.AllocationID, page), aep)
+	if err != nil {
+		return
+	}
+
+	var oldest = ae.Total - maxAllocationEvents
+	if oldest <= 0 {
+		return
+	}
+	if page, i = allocationEventPage(oldest); i == allocationEventsPageSize-1 {
+		_, err = balances.DeleteTrieNode(allocationEventsPageKey(sscKey,
+			ae.AllocationID, page))
+		if err == util.ErrValueNotPresent {
+			err = nil
+		}
+	}
+	return
+}
+
+// page of events with sequence number greater than given offset
+func (ae *allocationEvents) page(sscKey string, offset int64, limit int,
+	balances chainstate.StateContextI) (page []*AllocationEvent, err error) {
+
+	var seq = offset + 1
+	if first := ae.Total - maxAllocationEvents + 1; seq < first {
+		seq = first
+	}
+	if seq < 1 {
+		seq = 1
+	}
+
+	var pages = make(map[int64]*allocationEventsPage)
+	page = make([]*AllocationEvent, 0, limit)
+	for ; seq <= ae.Total && len(page) < limit; seq++ {
+		var (
+			p, i    = allocationEventPage(seq)
+			aep, ok = pages[p]
+		)
+		if !ok {
+			if aep, err = ae.getPage(sscKey, p, balances); err != nil {
+				return nil, err
+			}
+			pages[p] = aep
+		}
+		if i >= len(aep.Events) {
+			return nil, errors.New("corrupted allocation events page")
+		}
+		page = append(page, aep.Events[i])
+	}
+	return
+}
+
+func (ae *allocationEvents) save(sscKey string,
+	balances chainstate.StateContextI) (err error) {
+
+	_, err = balances.InsertTrieNode(allocationEventsKey(sscKey,
+		ae.AllocationID), ae)
+	return
+}
+
+// getAllocationEvents of given allocation
+func (sc *StorageSmartContract) getAllocationEvents(allocID string,
+	balances chainstate.StateContextI) (ae *allocationEvents, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(allocationEventsKey(sc.ID, allocID))
+	if err != nil {
+		return
+	}
+	ae = newAllocationEvents(allocID)
+	if err = ae.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return
+}
+
+// addAllocationEvent appends event of given transaction to the allocation
+// event log
+func (sc *StorageSmartContract) addAllocationEvent(
+	t *transaction.Transaction, allocID string, ev *AllocationEvent,
+	balances chainstate.StateContextI) (err error) {
+
+	var ae *allocationEvents
+	ae, err = sc.getAllocationEvents(allocID, balances)
+	if err == util.ErrValueNotPresent {
+		ae, err = newAllocationEvents(allocID), nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't get allocation events: %v", err)
+	}
+
+	ev.Tx, ev.ClientID, ev.Timestamp = t.Hash, t.ClientID, t.CreationDate
+	if err = ae.add(sc.ID, ev, balances); err != nil {
+		return fmt.Errorf("can't add allocation event: %v", err)
+	}
+
+	if err = ae.save(sc.ID, balances); err != nil {
+		return fmt.Errorf("can't save allocation events: %v", err)
+	}
+	return
+}
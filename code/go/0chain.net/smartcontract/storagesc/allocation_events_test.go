@@ -0,0 +1,126 @@
+package storagesc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"0chain.net/core/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lastAllocationEvent of the event log of given allocation
+func lastAllocationEvent(t testing.TB, ssc *StorageSmartContract,
+	allocID string, balances *testBalances) *AllocationEvent {
+
+	var ae, err = ssc.getAllocationEvents(allocID, balances)
+	require.NoError(t, err)
+	var page []*AllocationEvent
+	page, err = ae.page(ssc.ID, ae.Total-1, 1, balances)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	return page[0]
+}
+
+func Test_allocationEvents_add(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		ae       = newAllocationEvents("alloc")
+	)
+	for i := 0; i < maxAllocationEvents+allocationEventsPageSize+10; i++ {
+		require.NoError(t, ae.add(ssc.ID,
+			&AllocationEvent{Type: allocEventUpdated}, balances))
+	}
+	assert.EqualValues(t, maxAllocationEvents+allocationEventsPageSize+10,
+		ae.Total)
+
+	// pages of removed events are removed
+	var _, err = ae.getPage(ssc.ID, 0, balances)
+	require.Equal(t, util.ErrValueNotPresent, err)
+	_, err = ae.getPage(ssc.ID, 1, balances)
+	require.NoError(t, err)
+
+	var page []*AllocationEvent
+	page, err = ae.page(ssc.ID, 0, 5, balances)
+	require.NoError(t, err)
+	require.Len(t, page, 5)
+	assert.EqualValues(t, allocationEventsPageSize+11, page[0].Seq)
+
+	page, err = ae.page(ssc.ID, ae.Total-5, 10, balances)
+	require.NoError(t, err)
+	require.Len(t, page, 5)
+	assert.EqualValues(t, ae.Total-4, page[0].Seq)
+	assert.EqualValues(t, ae.Total, page[4].Seq)
+
+	// across pages
+	page, err = ae.page(ssc.ID, ae.Total-allocationEventsPageSize-5, 10,
+		balances)
+	require.NoError(t, err)
+	require.Len(t, page, 10)
+	for i, ev := range page {
+		assert.EqualValues(t, ae.Total-allocationEventsPageSize-4+int64(i),
+			ev.Seq)
+	}
+
+	page, err = ae.page(ssc.ID, ae.Total, 10, balances)
+	require.NoError(t, err)
+	assert.Len(t, page, 0)
+}
+
+func TestStorageSmartContract_AllocationEventsHandler(t *testing.T) {
+	const tp, exp = 100, int64(100 + time.Hour/time.Second)
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		ctx      = context.Background()
+		params   = make(url.Values)
+		resp     interface{}
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	_, err = ssc.AllocationEventsHandler(ctx, params, balances)
+	requireErrMsg(t, err, "invalid_request: missing 'allocation' URL "+
+		"query parameter")
+
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+	params.Set("allocation", allocID)
+
+	params.Set("limit", "-1")
+	_, err = ssc.AllocationEventsHandler(ctx, params, balances)
+	requireErrMsg(t, err, "invalid_request: invalid 'limit' URL query "+
+		"parameter")
+	params.Del("limit")
+
+	// created
+	resp, err = ssc.AllocationEventsHandler(ctx, params, balances)
+	require.NoError(t, err)
+	var ae = resp.(*allocationEvents)
+	assert.EqualValues(t, 1, ae.Total)
+	require.Len(t, ae.Events, 1)
+	assert.Equal(t, allocEventCreated, ae.Events[0].Type)
+	assert.Equal(t, client.id, ae.Events[0].ClientID)
+
+	// updated
+	var uar updateAllocationRequest
+	uar.ID = allocID
+	uar.Expiration = toSeconds(time.Hour)
+	_, err = uar.callUpdateAllocReq(t, client.id, 0, tp+10, ssc, balances)
+	require.NoError(t, err)
+
+	params.Set("offset", "1")
+	resp, err = ssc.AllocationEventsHandler(ctx, params, balances)
+	require.NoError(t, err)
+	ae = resp.(*allocationEvents)
+	assert.EqualValues(t, 2, ae.Total)
+	require.Len(t, ae.Events, 1)
+	assert.Equal(t, allocEventUpdated, ae.Events[0].Type)
+	assert.Equal(t, uar.Expiration, ae.Events[0].Expiration)
+}
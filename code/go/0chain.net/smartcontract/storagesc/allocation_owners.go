@@ -0,0 +1,399 @@
+package storagesc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+// ownerPermissions of a co-owner of an allocation
+type ownerPermissions struct {
+	// Write is permission to sign write markers of the allocation.
+	Write bool `json:"write"`
+	// Read is permission to read the allocation paying from own read pool
+	// without an auth ticket.
+	Read bool `json:"read"`
+	// ManagePools is permission to lock tokens to the allocation write
+	// pool, to update and to finalize the allocation.
+	ManagePools bool `json:"manage_pools"`
+}
+
+// isClientPublicKey returns true if given public key is the key of given
+// client
+func isClientPublicKey(publicKey, clientID string) bool {
+	var pkb, err = hex.DecodeString(publicKey)
+	return err == nil && encryption.Hash(pkb) == clientID
+}
+
+func (op ownerPermissions) any() bool {
+	return op.Write || op.Read || op.ManagePools
+}
+
+// permission kinds
+type ownerPermission int
+
+const (
+	permWrite ownerPermission = iota
+	permRead
+	permManagePools
+)
+
+func (op ownerPermissions) has(perm ownerPermission) bool {
+	switch perm {
+	case permWrite:
+		return op.Write
+	case permRead:
+		return op.Read
+	case permManagePools:
+		return op.ManagePools
+	}
+	return false
+}
+
+// AllocationCoOwner is a client shares an allocation with its owner.
+type AllocationCoOwner struct {
+	ID          string           `json:"id"`
+	PublicKey   string           `json:"public_key"`
+	Permissions ownerPermissions `json:"permissions"`
+}
+
+// AllocationTransferOffer is offer of allocation owner to transfer the
+// allocation to a new owner; the transfer happens when the new owner
+// accepts the offer.
+type AllocationTransferOffer struct {
+	NewOwnerID        string           `json:"new_owner_id"`
+	NewOwnerPublicKey string           `json:"new_owner_public_key"`
+	Timestamp         common.Timestamp `json:"timestamp"`
+}
+
+func (sa *StorageAllocation) getCoOwner(id string) (co *AllocationCoOwner,
+	i int) {
+
+	for i, co = range sa.CoOwners {
+		if co.ID == id {
+			return
+		}
+	}
+	return nil, -1
+}
+
+// hasPermission returns true if given client is the allocation owner, or
+// is a co-owner with given permission
+func (sa *StorageAllocation) hasPermission(id string,
+	perm ownerPermission) bool {
+
+	if sa.Owner == id {
+		return true
+	}
+	var co, _ = sa.getCoOwner(id)
+	return co != nil && co.Permissions.has(perm)
+}
+
+// writerPublicKey returns public key of given client can sign write markers
+// of the allocation
+func (sa *StorageAllocation) writerPublicKey(id string) (pk string,
+	ok bool) {
+
+	if sa.Owner == id {
+		return sa.OwnerPublicKey, true
+	}
+	var co, _ = sa.getCoOwner(id)
+	if co == nil || !co.Permissions.Write {
+		return "", false
+	}
+	return co.PublicKey, true
+}
+
+//
+// co-owners
+//
+
+type coOwnerInput struct {
+	AllocationID string `json:"allocation_id"`
+	AllocationCoOwner
+}
+
+func (coi *coOwnerInput) decode(input []byte) error {
+	return json.Unmarshal(input, coi)
+}
+
+// addCoOwner adds a co-owner, or updates permissions of existing one;
+// only allocation owner can add co-owners
+func (sc *StorageSmartContract) addCoOwner(t *transaction.Transaction,
+	input []byte, balances chainstate.StateContextI) (resp string, err error) {
+
+	var coi coOwnerInput
+	if err = coi.decode(input); err != nil {
+		return "", common.NewError("add_co_owner_failed",
+			"invalid request: "+err.Error())
+	}
+
+	if coi.ID == "" || coi.PublicKey == "" {
+		return "", common.NewError("add_co_owner_failed",
+			"invalid request: missing co-owner id or public key")
+	}
+
+	if !coi.Permissions.any() {
+		return "", common.NewError("add_co_owner_failed",
+			"invalid request: no permissions given")
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(coi.AllocationID, balances); err != nil {
+		return "", common.NewError("add_co_owner_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	if alloc.Owner != t.ClientID {
+		return "", common.NewError("add_co_owner_failed",
+			"only owner can add a co-owner")
+	}
+
+	if coi.ID == alloc.Owner {
+		return "", common.NewError("add_co_owner_failed",
+			"owner can't be a co-owner")
+	}
+
+	var co = coi.AllocationCoOwner
+	if _, i := alloc.getCoOwner(co.ID); i >= 0 {
+		alloc.CoOwners[i] = &co // update
+	} else {
+		alloc.CoOwners = append(alloc.CoOwners, &co)
+	}
+
+	_, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
+	if err != nil {
+		return "", common.NewError("add_co_owner_failed",
+			"saving allocation: "+err.Error())
+	}
+
+	return string(alloc.Encode()), nil
+}
+
+// removeCoOwner removes a co-owner of an allocation; the owner can remove
+// any co-owner, and a co-owner can remove itself
+func (sc *StorageSmartContract) removeCoOwner(t *transaction.Transaction,
+	input []byte, balances chainstate.StateContextI) (resp string, err error) {
+
+	var coi coOwnerInput
+	if err = coi.decode(input); err != nil {
+		return "", common.NewError("remove_co_owner_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(coi.AllocationID, balances); err != nil {
+		return "", common.NewError("remove_co_owner_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	if alloc.Owner != t.ClientID && coi.ID != t.ClientID {
+		return "", common.NewError("remove_co_owner_failed",
+			"only owner can remove a co-owner")
+	}
+
+	var _, i = alloc.getCoOwner(coi.ID)
+	if i < 0 {
+		return "", common.NewError("remove_co_owner_failed",
+			"no such co-owner: "+coi.ID)
+	}
+	alloc.CoOwners = append(alloc.CoOwners[:i], alloc.CoOwners[i+1:]...)
+
+	_, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
+	if err != nil {
+		return "", common.NewError("remove_co_owner_failed",
+			"saving allocation: "+err.Error())
+	}
+
+	return string(alloc.Encode()), nil
+}
+
+//
+// ownership transfer
+//
+
+// transferAllocation sets new owner of the allocation; a pending transfer
+// offer and co-owners of previous owner are removed
+func (sc *StorageSmartContract) transferAllocation(
+	txn *transaction.Transaction, alloc *StorageAllocation,
+	newOwnerID, newOwnerPublicKey string,
+	balances chainstate.StateContextI) (err error) {
+
+	if err = sc.removeUserAllocation(alloc.Owner, alloc, balances); err != nil {
+		return
+	}
+
+	alloc.Owner = newOwnerID
+	alloc.OwnerPublicKey = newOwnerPublicKey
+	alloc.TransferOffer = nil
+	alloc.CoOwners = nil
+
+	if err = sc.addUserAllocation(alloc.Owner, alloc, balances); err != nil {
+		return
+	}
+
+	if !alloc.hasWritePool(sc, newOwnerID, balances) {
+		if err = sc.createEmptyWritePool(txn, alloc, balances); err != nil {
+			return fmt.Errorf("error creating write pool: %v", err)
+		}
+	}
+
+	_, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
+	if err != nil {
+		return fmt.Errorf("saving new allocation: %v", err)
+	}
+	return
+}
+
+type transferOfferInput struct {
+	AllocationID      string `json:"allocation_id"`
+	NewOwnerID        string `json:"new_owner_id"`
+	NewOwnerPublicKey string `json:"new_owner_public_key"`
+}
+
+func (toi *transferOfferInput) decode(input []byte) error {
+	return json.Unmarshal(input, toi)
+}
+
+func (toi *transferOfferInput) validate() error {
+	if toi.NewOwnerID == "" && toi.NewOwnerPublicKey != "" {
+		return errors.New("missing new owner id")
+	}
+	if toi.NewOwnerID != "" && toi.NewOwnerPublicKey == "" {
+		return errors.New("missing new owner public key")
+	}
+	if toi.NewOwnerID != "" &&
+		!isClientPublicKey(toi.NewOwnerPublicKey, toi.NewOwnerID) {
+		return errors.New("new owner public key doesn't match new owner id")
+	}
+	return nil
+}
+
+// offerAllocationTransfer is the first step of owner-initiated allocation
+// transfer; the offer replaces previous one; empty new owner revokes the
+// offer
+func (sc *StorageSmartContract) offerAllocationTransfer(
+	t *transaction.Transaction, input []byte,
+	balances chainstate.StateContextI) (resp string, err error) {
+
+	var toi transferOfferInput
+	if err = toi.decode(input); err != nil {
+		return "", common.NewError("offer_allocation_transfer_failed",
+			"invalid request: "+err.Error())
+	}
+
+	if err = toi.validate(); err != nil {
+		return "", common.NewError("offer_allocation_transfer_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(toi.AllocationID, balances); err != nil {
+		return "", common.NewError("offer_allocation_transfer_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	if alloc.Owner != t.ClientID {
+		return "", common.NewError("offer_allocation_transfer_failed",
+			"only owner can transfer an allocation")
+	}
+
+	if alloc.Finalized || alloc.Canceled {
+		return "", common.NewError("offer_allocation_transfer_failed",
+			"allocation is finalized or canceled")
+	}
+
+	if toi.NewOwnerID == alloc.Owner {
+		return "", common.NewError("offer_allocation_transfer_failed",
+			"already the owner")
+	}
+
+	if toi.NewOwnerID == "" {
+		alloc.TransferOffer = nil // revoke
+	} else {
+		alloc.TransferOffer = &AllocationTransferOffer{
+			NewOwnerID:        toi.NewOwnerID,
+			NewOwnerPublicKey: toi.NewOwnerPublicKey,
+			Timestamp:         t.CreationDate,
+		}
+	}
+
+	_, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
+	if err != nil {
+		return "", common.NewError("offer_allocation_transfer_failed",
+			"saving allocation: "+err.Error())
+	}
+
+	return string(alloc.Encode()), nil
+}
+
+type acceptTransferInput struct {
+	AllocationID string `json:"allocation_id"`
+}
+
+func (ati *acceptTransferInput) decode(input []byte) error {
+	return json.Unmarshal(input, ati)
+}
+
+// acceptAllocationTransfer is the second step of owner-initiated
+// allocation transfer, the new owner accepts the offer; co-owners of
+// previous owner are removed; the public key of the offer should be the
+// key of the new owner, since it verifies write markers of the owner
+func (sc *StorageSmartContract) acceptAllocationTransfer(
+	t *transaction.Transaction, input []byte,
+	balances chainstate.StateContextI) (resp string, err error) {
+
+	var ati acceptTransferInput
+	if err = ati.decode(input); err != nil {
+		return "", common.NewError("accept_allocation_transfer_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(ati.AllocationID, balances); err != nil {
+		return "", common.NewError("accept_allocation_transfer_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	var offer = alloc.TransferOffer
+	if offer == nil || offer.NewOwnerID != t.ClientID {
+		return "", common.NewError("accept_allocation_transfer_failed",
+			"no transfer offer for the client")
+	}
+
+	if alloc.Finalized || alloc.Canceled {
+		return "", common.NewError("accept_allocation_transfer_failed",
+			"allocation is finalized or canceled")
+	}
+
+	if !isClientPublicKey(offer.NewOwnerPublicKey, t.ClientID) {
+		return "", common.NewError("accept_allocation_transfer_failed",
+			"public key of the offer is not the key of the client")
+	}
+
+	var prevOwner = alloc.Owner
+
+	err = sc.transferAllocation(t, alloc, offer.NewOwnerID,
+		offer.NewOwnerPublicKey, balances)
+	if err != nil {
+		return "", common.NewError("accept_allocation_transfer_failed",
+			err.Error())
+	}
+
+	err = sc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+		Type:        allocEventTransferred,
+		PrevOwnerID: prevOwner,
+	}, balances)
+	if err != nil {
+		return "", common.NewError("accept_allocation_transfer_failed",
+			err.Error())
+	}
+
+	return string(alloc.Encode()), nil
+}
@@ -0,0 +1,293 @@
+package storagesc
+
+import (
+	"testing"
+	"time"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageAllocation_hasPermission(t *testing.T) {
+	var sa = StorageAllocation{
+		Owner:          "owner",
+		OwnerPublicKey: "owner_pk",
+		CoOwners: []*AllocationCoOwner{
+			{ID: "writer", PublicKey: "writer_pk",
+				Permissions: ownerPermissions{Write: true}},
+			{ID: "reader", PublicKey: "reader_pk",
+				Permissions: ownerPermissions{Read: true}},
+		},
+	}
+
+	for _, perm := range []ownerPermission{permWrite, permRead,
+		permManagePools} {
+
+		assert.True(t, sa.hasPermission("owner", perm))
+		assert.False(t, sa.hasPermission("unknown", perm))
+	}
+	assert.True(t, sa.hasPermission("writer", permWrite))
+	assert.False(t, sa.hasPermission("writer", permRead))
+	assert.True(t, sa.hasPermission("reader", permRead))
+	assert.False(t, sa.hasPermission("reader", permManagePools))
+
+	var pk, ok = sa.writerPublicKey("writer")
+	require.True(t, ok)
+	assert.Equal(t, "writer_pk", pk)
+	pk, ok = sa.writerPublicKey("owner")
+	require.True(t, ok)
+	assert.Equal(t, "owner_pk", pk)
+	_, ok = sa.writerPublicKey("reader")
+	assert.False(t, ok)
+}
+
+func TestStorageSmartContract_coOwners(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		coOwner  = newClient(100*x10, balances)
+		tp, exp  = int64(100), int64(toSeconds(time.Hour))
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var coOwnerRequest = func(clientID string, remove bool,
+		perms ownerPermissions) (err error) {
+
+		tp += 100
+		var tx = newTransaction(clientID, ssc.ID, 0, tp)
+		balances.setTransaction(t, tx)
+		var input = mustEncode(t, &coOwnerInput{
+			AllocationID: allocID,
+			AllocationCoOwner: AllocationCoOwner{
+				ID:          coOwner.id,
+				PublicKey:   coOwner.pk,
+				Permissions: perms,
+			},
+		})
+		if remove {
+			_, err = ssc.removeCoOwner(tx, input, balances)
+		} else {
+			_, err = ssc.addCoOwner(tx, input, balances)
+		}
+		return
+	}
+
+	requireErrMsg(t, coOwnerRequest(coOwner.id, false,
+		ownerPermissions{Write: true}),
+		"add_co_owner_failed: only owner can add a co-owner")
+	requireErrMsg(t, coOwnerRequest(client.id, false, ownerPermissions{}),
+		"add_co_owner_failed: invalid request: no permissions given")
+	require.NoError(t, coOwnerRequest(client.id, false,
+		ownerPermissions{Write: true}))
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	require.Len(t, alloc.CoOwners, 1)
+
+	// no manage_pools permission
+	tp += 100
+	var tx = newTransaction(coOwner.id, ssc.ID, 2*x10, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.writePoolLock(tx, mustEncode(t, &lockRequest{
+		Duration:     20 * time.Minute,
+		AllocationID: allocID,
+	}), balances)
+	requireErrMsg(t, err, "write_pool_lock_failed: only owner or co-owner "+
+		"with manage_pools permission can lock tokens for the allocation")
+
+	tp += 100
+	tx = newTransaction(coOwner.id, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.updateAllocationRequest(tx,
+		mustEncode(t, &updateAllocationRequest{
+			ID:         allocID,
+			OwnerID:    client.id,
+			Expiration: 100,
+		}), balances)
+	requireErrMsg(t, err, "allocation_updating_failed: only owner or "+
+		"co-owner with manage_pools permission can update the allocation")
+
+	assert.False(t, alloc.IsValidFinalizer(coOwner.id))
+
+	// write marker signed by the co-owner
+	var (
+		blobberID = alloc.BlobberDetails[0].BlobberID
+		cc        = &BlobberCloseConnection{
+			AllocationRoot: "root-1",
+			WriteMarker: &WriteMarker{
+				AllocationRoot: "root-1",
+				AllocationID:   allocID,
+				Size:           10 * 1024 * 1024,
+				BlobberID:      blobberID,
+				Timestamp:      common.Timestamp(tp),
+				ClientID:       coOwner.id,
+			},
+		}
+	)
+	cc.WriteMarker.Signature, err = coOwner.scheme.Sign(
+		encryption.Hash(cc.WriteMarker.GetHashData()))
+	require.NoError(t, err)
+
+	tp += 100
+	tx = newTransaction(blobberID, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.commitBlobberConnection(tx, mustEncode(t, cc), balances)
+	require.NoError(t, err)
+
+	// upgrade permissions
+	require.NoError(t, coOwnerRequest(client.id, false,
+		ownerPermissions{ManagePools: true}))
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	require.Len(t, alloc.CoOwners, 1)
+	assert.True(t, alloc.IsValidFinalizer(coOwner.id))
+
+	tp += 100
+	tx = newTransaction(coOwner.id, ssc.ID, 2*x10, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.writePoolLock(tx, mustEncode(t, &lockRequest{
+		Duration:     20 * time.Minute,
+		AllocationID: allocID,
+	}), balances)
+	require.NoError(t, err)
+
+	// co-owner leaves
+	require.NoError(t, coOwnerRequest(coOwner.id, true, ownerPermissions{}))
+	requireErrMsg(t, coOwnerRequest(client.id, true, ownerPermissions{}),
+		"remove_co_owner_failed: no such co-owner: "+coOwner.id)
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.Len(t, alloc.CoOwners, 0)
+}
+
+func TestStorageSmartContract_allocationTransfer(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		newOwner = newClient(100*x10, balances)
+		coOwner  = newClient(100*x10, balances)
+		curator  = newClient(100*x10, balances)
+		tp, exp  = int64(100), int64(toSeconds(time.Hour))
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var offer = func(clientID, newOwnerID, newOwnerPK string) (err error) {
+		tp += 100
+		var tx = newTransaction(clientID, ssc.ID, 0, tp)
+		balances.setTransaction(t, tx)
+		_, err = ssc.offerAllocationTransfer(tx,
+			mustEncode(t, &transferOfferInput{
+				AllocationID:      allocID,
+				NewOwnerID:        newOwnerID,
+				NewOwnerPublicKey: newOwnerPK,
+			}), balances)
+		return
+	}
+
+	var accept = func(clientID string) (err error) {
+		tp += 100
+		var tx = newTransaction(clientID, ssc.ID, 0, tp)
+		balances.setTransaction(t, tx)
+		_, err = ssc.acceptAllocationTransfer(tx,
+			mustEncode(t, &acceptTransferInput{AllocationID: allocID}),
+			balances)
+		return
+	}
+
+	requireErrMsg(t, offer(newOwner.id, newOwner.id, newOwner.pk),
+		"offer_allocation_transfer_failed: only owner can transfer "+
+			"an allocation")
+	requireErrMsg(t, offer(client.id, newOwner.id, ""),
+		"offer_allocation_transfer_failed: invalid request: missing new "+
+			"owner public key")
+	// the old owner can't keep signing write markers with its own key
+	requireErrMsg(t, offer(client.id, newOwner.id, client.pk),
+		"offer_allocation_transfer_failed: invalid request: new owner "+
+			"public key doesn't match new owner id")
+	requireErrMsg(t, accept(newOwner.id),
+		"accept_allocation_transfer_failed: no transfer offer for the client")
+
+	// offer and revoke
+	require.NoError(t, offer(client.id, newOwner.id, newOwner.pk))
+	require.NoError(t, offer(client.id, "", ""))
+	requireErrMsg(t, accept(newOwner.id),
+		"accept_allocation_transfer_failed: no transfer offer for the client")
+
+	var addCoOwner = func(clientID string) (err error) {
+		tp += 100
+		var tx = newTransaction(clientID, ssc.ID, 0, tp)
+		balances.setTransaction(t, tx)
+		_, err = ssc.addCoOwner(tx, mustEncode(t, &coOwnerInput{
+			AllocationID: allocID,
+			AllocationCoOwner: AllocationCoOwner{
+				ID:          coOwner.id,
+				PublicKey:   coOwner.pk,
+				Permissions: ownerPermissions{Write: true},
+			},
+		}), balances)
+		return
+	}
+
+	require.NoError(t, offer(client.id, newOwner.id, newOwner.pk))
+	require.NoError(t, addCoOwner(client.id))
+	requireErrMsg(t, accept(client.id),
+		"accept_allocation_transfer_failed: no transfer offer for the client")
+	require.NoError(t, accept(newOwner.id))
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, newOwner.id, alloc.Owner)
+	assert.Equal(t, newOwner.pk, alloc.OwnerPublicKey)
+	assert.Nil(t, alloc.TransferOffer)
+	assert.Len(t, alloc.CoOwners, 0)
+
+	var wp *writePool
+	wp, err = ssc.getWritePool(newOwner.id, balances)
+	require.NoError(t, err)
+	assert.NotNil(t, wp)
+
+	var last = lastAllocationEvent(t, ssc, allocID, balances)
+	assert.Equal(t, allocEventTransferred, last.Type)
+	assert.Equal(t, client.id, last.PrevOwnerID)
+
+	// curator transfer removes co-owners and pending offer the same way
+	require.NoError(t, addCoOwner(newOwner.id))
+	require.NoError(t, offer(newOwner.id, client.id, client.pk))
+	tp += 100
+	var tx = newTransaction(newOwner.id, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	require.NoError(t, ssc.addCurator(tx, mustEncode(t, &addCuratorInput{
+		CuratorId:    curator.id,
+		AllocationId: allocID,
+	}), balances))
+	tp += 100
+	tx = newTransaction(curator.id, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.curatorTransferAllocation(tx,
+		mustEncode(t, &transferAllocationInput{
+			AllocationId:      allocID,
+			NewOwnerId:        client.id,
+			NewOwnerPublicKey: client.pk,
+		}), balances)
+	require.NoError(t, err)
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, client.id, alloc.Owner)
+	assert.Nil(t, alloc.TransferOffer)
+	assert.Len(t, alloc.CoOwners, 0)
+}
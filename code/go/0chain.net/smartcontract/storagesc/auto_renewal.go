@@ -0,0 +1,765 @@
+package storagesc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/tokenpool"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	. "0chain.net/core/logging"
+	"0chain.net/core/util"
+
+	"go.uber.org/zap"
+)
+
+// AUTO_RENEW_ALLOCATIONS_KEY is key of list of allocations with auto-renewal
+// enabled ordered by expiration
+var AUTO_RENEW_ALLOCATIONS_KEY = datastore.Key(ADDRESS +
+	encryption.Hash("auto_renew_allocations"))
+
+// AllocationAutoRenewal is opt-in auto-renewal settings of an allocation.
+// The allocation is extended by the Duration when its expiration approaches,
+// paying from the allocation renewal pool.
+type AllocationAutoRenewal struct {
+	Enabled  bool          `json:"enabled"`
+	Duration time.Duration `json:"duration"`
+	// Renewals is number of successful renewals.
+	Renewals int64 `json:"renewals"`
+	// LastRenewal is time of last successful renewal.
+	LastRenewal common.Timestamp `json:"last_renewal"`
+	// Failures is number of failed renewals in a row.
+	Failures int `json:"failures"`
+}
+
+//
+// auto-renewal list
+//
+
+// autoRenewItem is an allocation of the auto-renewal list with its
+// expiration the list is ordered by
+type autoRenewItem struct {
+	AllocationID string           `json:"allocation_id"`
+	Expiration   common.Timestamp `json:"expiration"`
+}
+
+// autoRenewList is list of allocations with auto-renewal enabled ordered by
+// expiration, thus the renewals examine allocations expiring within the
+// auto-renew window only
+type autoRenewList struct {
+	List []*autoRenewItem `json:"list"`
+}
+
+func (arl *autoRenewList) Encode() (b []byte) {
+	var err error
+	if b, err = json.Marshal(arl); err != nil {
+		panic(err) // must never happens
+	}
+	return
+}
+
+func (arl *autoRenewList) Decode(b []byte) error {
+	return json.Unmarshal(b, arl)
+}
+
+func (arl *autoRenewList) getIndex(allocID string) (i int, ok bool) {
+	for i, it := range arl.List {
+		if it.AllocationID == allocID {
+			return i, true
+		}
+	}
+	return
+}
+
+func (arl *autoRenewList) has(allocID string) (ok bool) {
+	_, ok = arl.getIndex(allocID)
+	return
+}
+
+func (arl *autoRenewList) remove(allocID string) (ok bool) {
+	var i int
+	if i, ok = arl.getIndex(allocID); ok {
+		arl.List = append(arl.List[:i], arl.List[i+1:]...)
+	}
+	return
+}
+
+// add or move the allocation to given expiration
+func (arl *autoRenewList) add(allocID string, exp common.Timestamp) {
+	arl.remove(allocID)
+	var i = sort.Search(len(arl.List), func(i int) bool {
+		var it = arl.List[i]
+		return it.Expiration > exp ||
+			it.Expiration == exp && it.AllocationID > allocID
+	})
+	arl.List = append(arl.List, nil)
+	copy(arl.List[i+1:], arl.List[i:])
+	arl.List[i] = &autoRenewItem{AllocationID: allocID, Expiration: exp}
+}
+
+// due returns the allocations expiring before given time
+func (arl *autoRenewList) due(before common.Timestamp) (due []*autoRenewItem) {
+	for _, it := range arl.List {
+		if it.Expiration > before {
+			break
+		}
+		due = append(due, it)
+	}
+	return
+}
+
+func (arl *autoRenewList) save(balances chainstate.StateContextI) (err error) {
+	_, err = balances.InsertTrieNode(AUTO_RENEW_ALLOCATIONS_KEY, arl)
+	return
+}
+
+//
+// renewal pool
+//
+
+// renewal pool is tokens locked by allocation owner for auto-renewals of
+// the allocation
+type renewalPool struct {
+	*tokenpool.ZcnPool `json:"pool"`
+}
+
+func newRenewalPool() *renewalPool {
+	return &renewalPool{
+		ZcnPool: &tokenpool.ZcnPool{},
+	}
+}
+
+func renewalPoolKey(scKey, allocationID string) datastore.Key {
+	return datastore.Key(scKey + ":renewalpool:" + allocationID)
+}
+
+func (rp *renewalPool) Encode() (b []byte) {
+	var err error
+	if b, err = json.Marshal(rp); err != nil {
+		panic(err) // must never happens
+	}
+	return
+}
+
+func (rp *renewalPool) Decode(input []byte) (err error) {
+
+	type renewalPoolJSON struct {
+		Pool json.RawMessage `json:"pool"`
+	}
+
+	var renewalPoolVal renewalPoolJSON
+	if err = json.Unmarshal(input, &renewalPoolVal); err != nil {
+		return
+	}
+
+	if len(renewalPoolVal.Pool) == 0 {
+		return // no data given
+	}
+
+	err = rp.ZcnPool.Decode(renewalPoolVal.Pool)
+	return
+}
+
+// save the renewal pool
+func (rp *renewalPool) save(sscKey, allocationID string,
+	balances chainstate.StateContextI) (err error) {
+
+	_, err = balances.InsertTrieNode(renewalPoolKey(sscKey, allocationID), rp)
+	return
+}
+
+// getRenewalPool of an allocation
+func (sc *StorageSmartContract) getRenewalPool(allocationID datastore.Key,
+	balances chainstate.StateContextI) (rp *renewalPool, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(renewalPoolKey(sc.ID, allocationID))
+	if err != nil {
+		return
+	}
+	rp = newRenewalPool()
+	if err = rp.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return
+}
+
+// getAutoRenewAllocationsList returns list of allocations with auto-renewal
+// enabled
+func (sc *StorageSmartContract) getAutoRenewAllocationsList(
+	balances chainstate.StateContextI) (list *autoRenewList, err error) {
+
+	list = new(autoRenewList)
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(AUTO_RENEW_ALLOCATIONS_KEY)
+	if err == util.ErrValueNotPresent {
+		return list, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = list.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return
+}
+
+//
+// SC functions
+//
+
+type autoRenewalRequest struct {
+	AllocationID string        `json:"allocation_id"`
+	Enabled      bool          `json:"enabled"`
+	Duration     time.Duration `json:"duration"`
+}
+
+func (arr *autoRenewalRequest) decode(b []byte) error {
+	return json.Unmarshal(b, arr)
+}
+
+// setAutoRenewal enables, disables or changes auto-renewal of an allocation;
+// the owner and co-owners with manage_pools permission can do it
+func (sc *StorageSmartContract) setAutoRenewal(t *transaction.Transaction,
+	input []byte, balances chainstate.StateContextI) (resp string, err error) {
+
+	var req autoRenewalRequest
+	if err = req.decode(input); err != nil {
+		return "", common.NewError("set_auto_renewal_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var conf *scConfig
+	if conf, err = sc.getConfig(balances, true); err != nil {
+		return "", common.NewError("set_auto_renewal_failed",
+			"can't get SC configurations: "+err.Error())
+	}
+
+	if req.Enabled {
+		if conf.AutoRenewWindow <= 0 {
+			return "", common.NewError("set_auto_renewal_failed",
+				"auto-renewals disabled by SC")
+		}
+		if req.Duration < conf.MinAllocDuration {
+			return "", common.NewError("set_auto_renewal_failed",
+				"renewal duration is less than min allocation duration")
+		}
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(req.AllocationID, balances); err != nil {
+		return "", common.NewError("set_auto_renewal_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	if !alloc.hasPermission(t.ClientID, permManagePools) {
+		return "", common.NewError("set_auto_renewal_failed",
+			"only owner or co-owner with manage_pools permission can "+
+				"change auto-renewal of the allocation")
+	}
+
+	if alloc.Finalized || alloc.Canceled {
+		return "", common.NewError("set_auto_renewal_failed",
+			"allocation is finalized or canceled")
+	}
+
+	if alloc.AutoRenewal == nil {
+		alloc.AutoRenewal = new(AllocationAutoRenewal)
+	}
+	alloc.AutoRenewal.Enabled = req.Enabled
+	if req.Enabled {
+		alloc.AutoRenewal.Duration = req.Duration
+		alloc.AutoRenewal.Failures = 0
+	}
+
+	var list *autoRenewList
+	if list, err = sc.getAutoRenewAllocationsList(balances); err != nil {
+		return "", common.NewError("set_auto_renewal_failed",
+			"can't get auto-renewal allocations list: "+err.Error())
+	}
+	if req.Enabled {
+		list.add(alloc.ID, alloc.Expiration)
+	} else {
+		list.remove(alloc.ID)
+	}
+	if err = list.save(balances); err != nil {
+		return "", common.NewError("set_auto_renewal_failed",
+			"saving auto-renewal allocations list: "+err.Error())
+	}
+
+	_, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
+	if err != nil {
+		return "", common.NewError("set_auto_renewal_failed",
+			"saving allocation: "+err.Error())
+	}
+
+	return string(alloc.Encode()), nil
+}
+
+type renewalPoolRequest struct {
+	AllocationID string `json:"allocation_id"`
+}
+
+func (rpr *renewalPoolRequest) decode(b []byte) error {
+	return json.Unmarshal(b, rpr)
+}
+
+// renewalPoolLock locks tokens of the transaction in renewal pool of an
+// allocation; the owner and co-owners with manage_pools permission can lock
+func (sc *StorageSmartContract) renewalPoolLock(t *transaction.Transaction,
+	input []byte, balances chainstate.StateContextI) (resp string, err error) {
+
+	var req renewalPoolRequest
+	if err = req.decode(input); err != nil {
+		return "", common.NewError("renewal_pool_lock_failed",
+			"invalid request: "+err.Error())
+	}
+
+	if t.Value <= 0 {
+		return "", common.NewError("renewal_pool_lock_failed",
+			"no tokens to lock")
+	}
+
+	if err = checkFill(t, balances); err != nil {
+		return "", common.NewError("renewal_pool_lock_failed", err.Error())
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(req.AllocationID, balances); err != nil {
+		return "", common.NewError("renewal_pool_lock_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	if !alloc.hasPermission(t.ClientID, permManagePools) {
+		return "", common.NewError("renewal_pool_lock_failed",
+			"only owner or co-owner with manage_pools permission can "+
+				"lock tokens for the allocation")
+	}
+
+	if alloc.Finalized || alloc.Canceled {
+		return "", common.NewError("renewal_pool_lock_failed",
+			"allocation is finalized or canceled")
+	}
+
+	var rp *renewalPool
+	switch rp, err = sc.getRenewalPool(alloc.ID, balances); err {
+	case nil:
+	case util.ErrValueNotPresent:
+		rp = newRenewalPool()
+		rp.ID = renewalPoolKey(sc.ID, alloc.ID)
+	default:
+		return "", common.NewError("renewal_pool_lock_failed",
+			"can't get renewal pool: "+err.Error())
+	}
+
+	var transfer *state.Transfer
+	if transfer, resp, err = rp.FillPool(t); err != nil {
+		return "", common.NewError("renewal_pool_lock_failed", err.Error())
+	}
+	if err = balances.AddTransfer(transfer); err != nil {
+		return "", common.NewError("renewal_pool_lock_failed", err.Error())
+	}
+
+	if err = rp.save(sc.ID, alloc.ID, balances); err != nil {
+		return "", common.NewError("renewal_pool_lock_failed",
+			"saving renewal pool: "+err.Error())
+	}
+
+	return
+}
+
+// renewalPoolUnlock returns all tokens of renewal pool of an allocation to
+// the allocation owner
+func (sc *StorageSmartContract) renewalPoolUnlock(t *transaction.Transaction,
+	input []byte, balances chainstate.StateContextI) (resp string, err error) {
+
+	var req renewalPoolRequest
+	if err = req.decode(input); err != nil {
+		return "", common.NewError("renewal_pool_unlock_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(req.AllocationID, balances); err != nil {
+		return "", common.NewError("renewal_pool_unlock_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	if alloc.Owner != t.ClientID {
+		return "", common.NewError("renewal_pool_unlock_failed",
+			"only owner can unlock tokens of the renewal pool")
+	}
+
+	var rp *renewalPool
+	if rp, err = sc.getRenewalPool(alloc.ID, balances); err != nil {
+		return "", common.NewError("renewal_pool_unlock_failed",
+			"can't get renewal pool: "+err.Error())
+	}
+
+	var transfer *state.Transfer
+	transfer, resp, err = rp.EmptyPool(sc.ID, t.ClientID, nil)
+	if err != nil {
+		return "", common.NewError("renewal_pool_unlock_failed", err.Error())
+	}
+	if err = balances.AddTransfer(transfer); err != nil {
+		return "", common.NewError("renewal_pool_unlock_failed", err.Error())
+	}
+
+	if err = rp.save(sc.ID, alloc.ID, balances); err != nil {
+		return "", common.NewError("renewal_pool_unlock_failed",
+			"saving renewal pool: "+err.Error())
+	}
+
+	return
+}
+
+//
+// renewals
+//
+
+// renewalChallengePoolChanges returns tokens should be moved to challenge
+// pool by blobbers of the allocation to extend it at the blobbers' current
+// terms, the same way the extendAllocation does
+func (sa *StorageAllocation) renewalChallengePoolChanges(
+	blobbers []*StorageNode, ext, now common.Timestamp) (
+	changes []state.Balance) {
+
+	var (
+		ea     = *sa
+		oterms = make([]Terms, 0, len(sa.BlobberDetails))
+	)
+	ea.Expiration += ext
+	ea.BlobberDetails = make([]*BlobberAllocation, 0,
+		len(sa.BlobberDetails))
+	for i, d := range sa.BlobberDetails {
+		var nd = *d
+		oterms = append(oterms, d.Terms)
+		nd.Terms = weightedAverage(&d.Terms, &blobbers[i].Terms, now,
+			sa.Expiration, ea.Expiration, d.Size, 0)
+		ea.BlobberDetails = append(ea.BlobberDetails, &nd)
+	}
+	return ea.challengePoolChanges(sa.Expiration-now, ea.Expiration-now,
+		oterms)
+}
+
+// checkRenewal returns error if given allocation can't be renewed by the
+// blobbers
+func (sa *StorageAllocation) checkRenewal(blobbers []*StorageNode,
+	ext common.Timestamp) error {
+
+	for _, b := range blobbers {
+		if b.Capacity == 0 || b.Capacity < b.Used {
+			return fmt.Errorf("blobber %s has not enough capacity", b.ID)
+		}
+		if ext > toSeconds(b.Terms.MaxOfferDuration) {
+			return fmt.Errorf("blobber %s doesn't allow so long offers",
+				b.ID)
+		}
+	}
+	return nil
+}
+
+// renewAllocation extends given allocation by its auto-renewal duration
+// paying from its renewal pool; an allocation can't be renewed gets its
+// auto-renewal disabled and a failure event; the failed is true in this case
+func (sc *StorageSmartContract) renewAllocation(t *transaction.Transaction,
+	alloc *StorageAllocation, all *StorageNodes,
+	balances chainstate.StateContextI) (failed bool, err error) {
+
+	var (
+		now = t.CreationDate
+		ext = toSeconds(alloc.AutoRenewal.Duration)
+
+		blobbers []*StorageNode
+		rp       *renewalPool
+		changes  []state.Balance
+		cost     state.Balance
+	)
+
+	if blobbers, err = sc.getAllocationBlobbers(alloc, balances); err != nil {
+		return
+	}
+
+	var fail = func(reason error) (bool, error) {
+		alloc.AutoRenewal.Enabled = false
+		_, err := balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
+		if err != nil {
+			return true, err
+		}
+		return true, sc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+			Type:       allocEventRenewalFailed,
+			Expiration: alloc.Expiration,
+			Reason:     reason.Error(),
+		}, balances)
+	}
+
+	if err = alloc.checkRenewal(blobbers, ext); err != nil {
+		return fail(err)
+	}
+
+	changes = alloc.renewalChallengePoolChanges(blobbers, ext, now)
+	for _, ch := range changes {
+		if ch > 0 {
+			cost += ch
+		}
+	}
+
+	switch rp, err = sc.getRenewalPool(alloc.ID, balances); err {
+	case nil:
+	case util.ErrValueNotPresent:
+		return fail(errors.New("no renewal pool"))
+	default:
+		return
+	}
+	if rp.Balance < cost {
+		return fail(fmt.Errorf("not enough tokens in renewal pool: %d < %d",
+			rp.Balance, cost))
+	}
+
+	// move the tokens to owner's write pool for the allocation
+	if cost > 0 {
+		var wp *writePool
+		if wp, err = sc.getWritePool(alloc.Owner, balances); err != nil {
+			return
+		}
+		var ap = new(allocationPool)
+		ap.ID = encryption.Hash(t.Hash + ":" + alloc.ID)
+		ap.AllocationID = alloc.ID
+		ap.ExpireAt = alloc.Until() + ext
+		for i, ch := range changes {
+			if ch > 0 {
+				ap.Blobbers.add(&blobberPool{
+					BlobberID: alloc.BlobberDetails[i].BlobberID,
+					Balance:   ch,
+				})
+			}
+		}
+		if _, _, err = rp.TransferTo(ap, cost, nil); err != nil {
+			return
+		}
+		wp.Pools.add(ap)
+		alloc.addWritePoolOwner(alloc.Owner)
+		if err = wp.save(sc.ID, alloc.Owner, balances); err != nil {
+			return
+		}
+		if err = rp.save(sc.ID, alloc.ID, balances); err != nil {
+			return
+		}
+	}
+
+	// extend using the blobbers' current terms
+	var (
+		rt  = new(transaction.Transaction)
+		uar = &updateAllocationRequest{
+			ID:         alloc.ID,
+			OwnerID:    alloc.Owner,
+			Expiration: ext,
+		}
+	)
+	rt.Hash = t.Hash
+	rt.ClientID = t.ClientID
+	rt.ToClientID = t.ToClientID
+	rt.CreationDate = now
+
+	if err = sc.extendAllocation(rt, alloc, blobbers, uar, false, balances); err != nil {
+		return
+	}
+
+	alloc.AutoRenewal.Renewals++
+	alloc.AutoRenewal.LastRenewal = now
+	alloc.AutoRenewal.Failures = 0
+
+	if err = sc.saveUpdatedAllocation(all, alloc, blobbers, balances); err != nil {
+		return
+	}
+
+	err = sc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+		Type:       allocEventRenewed,
+		Amount:     cost,
+		Expiration: alloc.Expiration,
+	}, balances)
+	return
+}
+
+// tryRenewAllocation renews given allocation in a nested state context
+// if the balances supports it; the changes of the renewal are committed
+// only if it's succeeded, otherwise they are discarded
+func (sc *StorageSmartContract) tryRenewAllocation(t *transaction.Transaction,
+	alloc *StorageAllocation, all *StorageNodes,
+	balances chainstate.StateContextI) (failed bool, err error) {
+
+	var nested, ok = balances.(chainstate.NestedStateContextI)
+	if !ok {
+		return sc.renewAllocation(t, alloc, all, balances)
+	}
+
+	var renewal = nested.NestedStateContext(t)
+	if failed, err = sc.renewAllocation(t, alloc, all, renewal); err != nil {
+		return
+	}
+	if err = nested.CommitNested(renewal); err != nil {
+		return false, fmt.Errorf("committing renewal: %v", err)
+	}
+	return
+}
+
+// renewalFailed counts a renewal of the allocation rolled back, auto-renewal
+// of the allocation is disabled after the max number of failures in a row;
+// the disabled is true in this case
+func (sc *StorageSmartContract) renewalFailed(t *transaction.Transaction,
+	allocID string, reason error, conf *scConfig,
+	balances chainstate.StateContextI) (disabled bool, err error) {
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(allocID, balances); err != nil {
+		return
+	}
+	alloc.AutoRenewal.Failures++
+	disabled = conf.MaxAutoRenewFailures > 0 &&
+		alloc.AutoRenewal.Failures >= conf.MaxAutoRenewFailures
+	if disabled {
+		alloc.AutoRenewal.Enabled = false
+	}
+	if _, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc); err != nil {
+		return
+	}
+	if !disabled {
+		return
+	}
+	err = sc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+		Type:       allocEventRenewalFailed,
+		Expiration: alloc.Expiration,
+		Reason:     reason.Error(),
+	}, balances)
+	return
+}
+
+// updateAutoRenewList moves an allocation with auto-renewal enabled to its
+// current expiration in the auto-renewal list
+func (sc *StorageSmartContract) updateAutoRenewList(alloc *StorageAllocation,
+	balances chainstate.StateContextI) (err error) {
+
+	if alloc.AutoRenewal == nil || !alloc.AutoRenewal.Enabled {
+		return
+	}
+	var list *autoRenewList
+	if list, err = sc.getAutoRenewAllocationsList(balances); err != nil {
+		return fmt.Errorf("can't get auto-renewal allocations list: %v", err)
+	}
+	list.add(alloc.ID, alloc.Expiration)
+	if err = list.save(balances); err != nil {
+		return fmt.Errorf("saving auto-renewal allocations list: %v", err)
+	}
+	return
+}
+
+// autoRenewAllocations renews allocations with auto-renewal enabled and
+// expiration approaching; it's triggered by the generate_challenges
+func (sc *StorageSmartContract) autoRenewAllocations(
+	t *transaction.Transaction, balances chainstate.StateContextI) (
+	err error) {
+
+	var conf *scConfig
+	if conf, err = sc.getConfig(balances, true); err != nil {
+		return fmt.Errorf("can't get SC configurations: %v", err)
+	}
+
+	if conf.AutoRenewWindow <= 0 {
+		return // disabled
+	}
+
+	var list *autoRenewList
+	if list, err = sc.getAutoRenewAllocationsList(balances); err != nil {
+		return fmt.Errorf("can't get auto-renewal allocations list: %v", err)
+	}
+
+	var (
+		now     = t.CreationDate
+		before  = now + toSeconds(conf.AutoRenewWindow)
+		due     = list.due(before)
+		all     *StorageNodes
+		renewed int
+		changed bool
+	)
+
+	for _, it := range due {
+		if conf.MaxAutoRenewals > 0 && renewed >= conf.MaxAutoRenewals {
+			break
+		}
+
+		var (
+			id    = it.AllocationID
+			alloc *StorageAllocation
+		)
+		switch alloc, err = sc.getAllocation(id, balances); err {
+		case nil:
+		case util.ErrValueNotPresent:
+			changed = list.remove(id) || changed
+			continue
+		default:
+			return fmt.Errorf("can't get allocation %s: %v", id, err)
+		}
+
+		if alloc.AutoRenewal == nil || !alloc.AutoRenewal.Enabled ||
+			alloc.Finalized || alloc.Canceled || alloc.Expiration < now {
+
+			changed = list.remove(id) || changed
+			continue
+		}
+
+		if alloc.Expiration != it.Expiration {
+			// updated since added to the list
+			list.add(id, alloc.Expiration)
+			changed = true
+			if alloc.Expiration > before {
+				continue // not yet
+			}
+		}
+
+		if all == nil {
+			if all, err = sc.getBlobbersList(balances); err != nil {
+				return fmt.Errorf("can't get blobbers list: %v", err)
+			}
+		}
+
+		var failed bool
+		if failed, err = sc.tryRenewAllocation(t, alloc, all, balances); err != nil {
+			// the renewal is rolled back and is retried next time
+			Logger.Error("auto-renewal: renewing allocation",
+				zap.String("allocation", id), zap.Error(err))
+			all = nil // the list could be changed by the renewal
+			failed, err = sc.renewalFailed(t, id, err, conf, balances)
+			if err != nil {
+				return fmt.Errorf("counting renewal failure of %s: %v", id,
+					err)
+			}
+			if failed {
+				changed = list.remove(id) || changed
+			}
+			continue
+		}
+		if failed {
+			list.remove(id)
+		} else {
+			list.add(id, alloc.Expiration)
+		}
+		changed = true
+		renewed++
+	}
+
+	if changed {
+		if err = list.save(balances); err != nil {
+			return fmt.Errorf("saving auto-renewal allocations list: %v", err)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,194 @@
+package storagesc
+
+import (
+	"testing"
+	"time"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageSmartContract_autoRenewAllocations(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		tp, exp  = int64(100), int64(toSeconds(time.Hour))
+		err      error
+	)
+
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var conf = setConfig(t, balances)
+	conf.AutoRenewWindow = 10 * time.Minute
+	conf.MaxAutoRenewals = 10
+	conf.MaxAutoRenewFailures = 2
+	mustSave(t, scConfigKey(ADDRESS), conf, balances)
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+
+	// write some data to make renewal cost something
+	var blobberID = alloc.BlobberDetails[0].BlobberID
+	tp += 100
+	var cc = &BlobberCloseConnection{
+		AllocationRoot: "root-1",
+		WriteMarker: &WriteMarker{
+			AllocationRoot: "root-1",
+			AllocationID:   allocID,
+			Size:           100 * 1024 * 1024,
+			BlobberID:      blobberID,
+			Timestamp:      common.Timestamp(tp),
+			ClientID:       client.id,
+		},
+	}
+	cc.WriteMarker.Signature, err = client.scheme.Sign(
+		encryption.Hash(cc.WriteMarker.GetHashData()))
+	require.NoError(t, err)
+	var tx = newTransaction(blobberID, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.commitBlobberConnection(tx, mustEncode(t, cc), balances)
+	require.NoError(t, err)
+
+	var setAutoRenewal = func(enabled bool, dur time.Duration) (err error) {
+		tp += 100
+		var tx = newTransaction(client.id, ssc.ID, 0, tp)
+		balances.setTransaction(t, tx)
+		_, err = ssc.setAutoRenewal(tx, mustEncode(t, &autoRenewalRequest{
+			AllocationID: allocID,
+			Enabled:      enabled,
+			Duration:     dur,
+		}), balances)
+		return
+	}
+
+	var renew = func(at int64) {
+		var tx = newTransaction(client.id, ssc.ID, 0, at)
+		balances.setTransaction(t, tx)
+		require.NoError(t, ssc.autoRenewAllocations(tx, balances))
+	}
+
+	var lastEvent = func() *AllocationEvent {
+		return lastAllocationEvent(t, ssc, allocID, balances)
+	}
+
+	requireErrMsg(t, setAutoRenewal(true, time.Second),
+		"set_auto_renewal_failed: renewal duration is less than min "+
+			"allocation duration")
+	require.NoError(t, setAutoRenewal(true, 30*time.Minute))
+
+	// no renewal pool
+	renew(exp - 60)
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, exp, alloc.Expiration)
+	assert.False(t, alloc.AutoRenewal.Enabled)
+	assert.Equal(t, allocEventRenewalFailed, lastEvent().Type)
+	assert.Equal(t, "no renewal pool", lastEvent().Reason)
+	assert.EqualValues(t, exp, lastEvent().Expiration)
+
+	var list *autoRenewList
+	list, err = ssc.getAutoRenewAllocationsList(balances)
+	require.NoError(t, err)
+	assert.False(t, list.has(allocID))
+
+	// fill the renewal pool and enable again
+	tp += 100
+	tx = newTransaction(client.id, ssc.ID, 10*x10, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.renewalPoolLock(tx, mustEncode(t, &renewalPoolRequest{
+		AllocationID: allocID,
+	}), balances)
+	require.NoError(t, err)
+	require.NoError(t, setAutoRenewal(true, 30*time.Minute))
+
+	// too early
+	renew(exp - int64(toSeconds(20*time.Minute)))
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, exp, alloc.Expiration)
+
+	renew(exp - 60)
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, exp+int64(toSeconds(30*time.Minute)),
+		alloc.Expiration)
+	assert.True(t, alloc.AutoRenewal.Enabled)
+	assert.EqualValues(t, 1, alloc.AutoRenewal.Renewals)
+
+	// moved to the new expiration
+	list, err = ssc.getAutoRenewAllocationsList(balances)
+	require.NoError(t, err)
+	require.Len(t, list.List, 1)
+	assert.Equal(t, alloc.Expiration, list.List[0].Expiration)
+	assert.Len(t, list.due(alloc.Expiration-1), 0)
+
+	var ev = lastEvent()
+	assert.Equal(t, allocEventRenewed, ev.Type)
+	assert.NotZero(t, ev.Amount)
+	assert.EqualValues(t, alloc.Expiration, ev.Expiration)
+
+	var rp *renewalPool
+	rp, err = ssc.getRenewalPool(allocID, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10*x10-ev.Amount, rp.Balance)
+
+	// a renewal error doesn't stop the auto-renewal, the allocation is
+	// renewed next time, but only max_auto_renew_failures times in a row
+	var blobber *StorageNode
+	blobber, err = ssc.getBlobber(blobberID, balances)
+	require.NoError(t, err)
+	_, err = balances.DeleteTrieNode(blobber.GetKey(ssc.ID))
+	require.NoError(t, err)
+	exp = int64(alloc.Expiration)
+	renew(exp - 60)
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, exp, alloc.Expiration)
+	assert.True(t, alloc.AutoRenewal.Enabled)
+	assert.Equal(t, 1, alloc.AutoRenewal.Failures)
+	list, err = ssc.getAutoRenewAllocationsList(balances)
+	require.NoError(t, err)
+	assert.True(t, list.has(allocID))
+
+	renew(exp - 30)
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, exp, alloc.Expiration)
+	assert.False(t, alloc.AutoRenewal.Enabled)
+	assert.Equal(t, allocEventRenewalFailed, lastEvent().Type)
+	list, err = ssc.getAutoRenewAllocationsList(balances)
+	require.NoError(t, err)
+	assert.False(t, list.has(allocID))
+	mustSave(t, blobber.GetKey(ssc.ID), blobber, balances)
+
+	// enabled again, the failures are reset
+	require.NoError(t, setAutoRenewal(true, 30*time.Minute))
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.Zero(t, alloc.AutoRenewal.Failures)
+
+	// unlock
+	tp += 100
+	tx = newTransaction(blobberID, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.renewalPoolUnlock(tx, mustEncode(t, &renewalPoolRequest{
+		AllocationID: allocID,
+	}), balances)
+	requireErrMsg(t, err, "renewal_pool_unlock_failed: only owner can "+
+		"unlock tokens of the renewal pool")
+
+	tx = newTransaction(client.id, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.renewalPoolUnlock(tx, mustEncode(t, &renewalPoolRequest{
+		AllocationID: allocID,
+	}), balances)
+	require.NoError(t, err)
+	rp, err = ssc.getRenewalPool(allocID, balances)
+	require.NoError(t, err)
+	assert.Zero(t, rp.Balance)
+}
@@ -21,6 +21,7 @@ type testBalances struct {
 	txn       *transaction.Transaction
 	transfers []*state.Transfer
 	tree      map[datastore.Key]util.Serializable
+	block     *block.Block
 
 	mpts      *mptStore // use for benchmarks
 	skipMerge bool      // don't merge for now
@@ -54,7 +55,7 @@ func (tb *testBalances) setTransaction(t testing.TB,
 }
 
 // stubs
-func (tb *testBalances) GetBlock() *block.Block                   { return nil }
+func (tb *testBalances) GetBlock() *block.Block                   { return tb.block }
 func (tb *testBalances) GetState() util.MerklePatriciaTrieI       { return nil }
 func (tb *testBalances) GetTransaction() *transaction.Transaction { return nil }
 func (tb *testBalances) GetBlockSharders(b *block.Block) []string { return nil }
@@ -338,8 +338,32 @@ func (sc *StorageSmartContract) commitBlobberRead(t *transaction.Transaction,
 			"decoding input: %v", err)
 	}
 
+	var rr *readRedeem
+	if rr, err = sc.prepareReadRedeem(t, commitRead, balances); err != nil {
+		return
+	}
+
+	return sc.redeemRead(t, rr, balances)
+}
+
+// readRedeem is verified read marker ready to be redeemed
+type readRedeem struct {
+	commitRead *ReadConnection
+	alloc      *StorageAllocation
+	details    *BlobberAllocation
+	numReads   int64
+	value      state.Balance
+	userID     string
+}
+
+// prepareReadRedeem verifies given read marker against latest redeemed one
+// and related allocation; it doesn't change the state
+func (sc *StorageSmartContract) prepareReadRedeem(t *transaction.Transaction,
+	commitRead *ReadConnection, balances cstate.StateContextI) (
+	rr *readRedeem, err error) {
+
 	if commitRead.ReadMarker == nil {
-		return "", common.NewError("commit_blobber_read",
+		return nil, common.NewError("commit_blobber_read",
 			"malformed request: missing read_marker")
 	}
 
@@ -353,7 +377,7 @@ func (sc *StorageSmartContract) commitBlobberRead(t *transaction.Transaction,
 		commitRead.GetKey(sc.ID))
 
 	if err != nil && err != util.ErrValueNotPresent {
-		return "", common.NewErrorf("commit_blobber_read",
+		return nil, common.NewErrorf("commit_blobber_read",
 			"can't get latest blobber client read: %v", err)
 	}
 
@@ -366,23 +390,22 @@ func (sc *StorageSmartContract) commitBlobberRead(t *transaction.Transaction,
 
 	err = commitRead.ReadMarker.Verify(lastCommittedRM.ReadMarker)
 	if err != nil {
-		return "", common.NewErrorf("commit_blobber_read",
+		return nil, common.NewErrorf("commit_blobber_read",
 			"can't verify read marker: %v", err)
 	}
 
-	// move tokens to blobber's stake pool from client's read pool
 	var alloc *StorageAllocation
 	alloc, err = sc.getAllocation(commitRead.ReadMarker.AllocationID, balances)
 	if err != nil {
-		return "", common.NewErrorf("commit_blobber_read",
+		return nil, common.NewErrorf("commit_blobber_read",
 			"can't get related allocation: %v", err)
 	}
 
 	if commitRead.ReadMarker.Timestamp < alloc.StartTime {
-		return "", common.NewError("commit_blobber_read",
+		return nil, common.NewError("commit_blobber_read",
 			"early reading, allocation not started yet")
 	} else if commitRead.ReadMarker.Timestamp > alloc.Until() {
-		return "", common.NewError("commit_blobber_read",
+		return nil, common.NewError("commit_blobber_read",
 			"late reading, allocation expired")
 	}
 
@@ -395,26 +418,45 @@ func (sc *StorageSmartContract) commitBlobberRead(t *transaction.Transaction,
 	}
 
 	if details == nil {
-		return "", common.NewError("commit_blobber_read",
+		return nil, common.NewError("commit_blobber_read",
 			"blobber doesn't belong to allocation")
 	}
 
 	const CHUNK_SIZE = 64 * KB
 
 	// one read is one 64 KB block
-	var (
-		numReads = commitRead.ReadMarker.ReadCounter - lastKnownCtr
-		sizeRead = sizeInGB(numReads * CHUNK_SIZE)
-		value    = state.Balance(float64(details.Terms.ReadPrice) * sizeRead)
-		userID   = commitRead.ReadMarker.PayerID
-	)
+	rr = &readRedeem{
+		commitRead: commitRead,
+		alloc:      alloc,
+		details:    details,
+		numReads:   commitRead.ReadMarker.ReadCounter - lastKnownCtr,
+		userID:     commitRead.ReadMarker.PayerID,
+	}
+	rr.value = state.Balance(float64(details.Terms.ReadPrice) *
+		sizeInGB(rr.numReads*CHUNK_SIZE))
 
 	// if 3rd party pays
 	err = commitRead.ReadMarker.verifyAuthTicket(alloc, t.CreationDate)
 	if err != nil {
-		return "", common.NewError("commit_blobber_read", err.Error())
+		return nil, common.NewError("commit_blobber_read", err.Error())
 	}
 
+	return
+}
+
+// redeemRead moves tokens to blobber's stake pool from client's read pool
+// and saves the read marker as the latest one
+func (sc *StorageSmartContract) redeemRead(t *transaction.Transaction,
+	rr *readRedeem, balances cstate.StateContextI) (resp string, err error) {
+
+	var (
+		commitRead = rr.commitRead
+		alloc      = rr.alloc
+		details    = rr.details
+		value      = rr.value
+		userID     = rr.userID
+	)
+
 	// move tokens from read pool to blobber
 	var rp *readPool
 	if rp, err = sc.getReadPool(userID, balances); err != nil {
@@ -462,7 +504,7 @@ func (sc *StorageSmartContract) commitBlobberRead(t *transaction.Transaction,
 	if err != nil {
 		return "", common.NewError("saving read marker", err.Error())
 	}
-	sc.newRead(balances, numReads)
+	sc.newRead(balances, rr.numReads)
 
 	return // ok, the response and nil
 }
@@ -573,9 +615,12 @@ func (sc *StorageSmartContract) commitBlobberConnection(
 			"can't get allocation: "+err.Error())
 	}
 
-	if alloc.Owner != commitConnection.WriteMarker.ClientID {
+	writerPublicKey, ok := alloc.writerPublicKey(
+		commitConnection.WriteMarker.ClientID)
+	if !ok {
 		return "", common.NewError("commit_connection_failed", "write marker has"+
-			" to be by the same client as owner of the allocation")
+			" to be by the same client as owner of the allocation, or by"+
+			" co-owner with write permission")
 	}
 
 	details, ok := alloc.BlobberMap[t.ClientID]
@@ -586,7 +631,7 @@ func (sc *StorageSmartContract) commitBlobberConnection(
 
 	detailsBytes, err := json.Marshal(details)
 
-	if !commitConnection.WriteMarker.VerifySignature(alloc.OwnerPublicKey) {
+	if !commitConnection.WriteMarker.VerifySignature(writerPublicKey) {
 		return "", common.NewError("commit_connection_failed",
 			"Invalid signature for write marker")
 	}
@@ -613,6 +658,10 @@ func (sc *StorageSmartContract) commitBlobberConnection(
 
 	details.AllocationRoot = commitConnection.AllocationRoot
 	details.LastWriteMarker = commitConnection.WriteMarker
+	err = details.addWriteMarker(sc.ID, commitConnection.WriteMarker, balances)
+	if err != nil {
+		return "", common.NewError("commit_connection_failed", err.Error())
+	}
 	details.Stats.UsedSize += commitConnection.WriteMarker.Size
 	details.Stats.NumWrites++
 
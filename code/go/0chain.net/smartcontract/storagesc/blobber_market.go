@@ -0,0 +1,257 @@
+package storagesc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"time"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+	"0chain.net/smartcontract"
+)
+
+// earthRadius in kilometers
+const earthRadius = 6371.0
+
+// distance in kilometers between two locations (great-circle distance)
+func (sng StorageNodeGeolocation) distance(
+	to StorageNodeGeolocation) (km float64) {
+
+	return 2 * earthRadius * geoDistance(sng, to)
+}
+
+// blobbersQuery is query of blobbers can hold an allocation with given
+// parameters; zero values of optional fields disable related filters
+type blobbersQuery struct {
+	Size         int64         `json:"size"`
+	Duration     time.Duration `json:"duration"`
+	DataShards   int           `json:"data_shards"`
+	ParityShards int           `json:"parity_shards"`
+	// ReadPriceRange and WritePriceRange, zero max price means max allowed
+	// by SC.
+	ReadPriceRange  PriceRange `json:"read_price_range"`
+	WritePriceRange PriceRange `json:"write_price_range"`
+	// Geolocation and Radius (in kilometers) to keep blobbers located
+	// nearby only.
+	Geolocation *StorageNodeGeolocation `json:"geolocation,omitempty"`
+	Radius      float64                 `json:"radius,omitempty"`
+	// MinStake of blobber's stake pool.
+	MinStake state.Balance `json:"min_stake"`
+	// MaxChallengeCompletionTime, zero means max allowed by SC.
+	MaxChallengeCompletionTime time.Duration `json:"max_challenge_completion_time"`
+	// Limit of candidates returned, zero means all.
+	Limit int `json:"limit"`
+}
+
+func (bq *blobbersQuery) decode(b []byte) error {
+	return json.Unmarshal(b, bq)
+}
+
+func (bq *blobbersQuery) validate() error {
+	if bq.Size <= 0 {
+		return errors.New("invalid size")
+	}
+	if bq.Duration <= 0 {
+		return errors.New("invalid duration")
+	}
+	if bq.DataShards < 0 || bq.ParityShards < 0 {
+		return errors.New("negative number of shards")
+	}
+	if bq.ReadPriceRange.Min < 0 || bq.WritePriceRange.Min < 0 {
+		return errors.New("negative min price")
+	}
+	if bq.Geolocation != nil {
+		if err := bq.Geolocation.validate(); err != nil {
+			return err
+		}
+		if bq.Radius <= 0 {
+			return errors.New("missing radius for the geolocation")
+		}
+	}
+	if bq.MinStake < 0 {
+		return errors.New("negative min_stake")
+	}
+	if bq.Limit < 0 {
+		return errors.New("negative limit")
+	}
+	return nil
+}
+
+// setDefaults sets SC configured values of omitted fields
+func (bq *blobbersQuery) setDefaults(conf *scConfig) {
+	if bq.DataShards == 0 {
+		bq.DataShards = 1
+	}
+	if bq.ReadPriceRange.Max == 0 {
+		bq.ReadPriceRange.Max = conf.MaxReadPrice
+	}
+	if bq.WritePriceRange.Max == 0 {
+		bq.WritePriceRange.Max = conf.MaxWritePrice
+	}
+	if bq.MaxChallengeCompletionTime == 0 {
+		bq.MaxChallengeCompletionTime = conf.MaxChallengeCompletionTime
+	}
+}
+
+// storageAllocation used to filter blobbers the same way the
+// new_allocation_request does
+func (bq *blobbersQuery) storageAllocation(now common.Timestamp,
+	conf *scConfig) (sa *StorageAllocation) {
+
+	sa = new(StorageAllocation)
+	sa.Size = bq.Size
+	sa.DataShards = bq.DataShards
+	sa.ParityShards = bq.ParityShards
+	sa.Expiration = now + toSeconds(bq.Duration)
+	sa.ReadPriceRange = bq.ReadPriceRange
+	sa.WritePriceRange = bq.WritePriceRange
+	sa.MaxChallengeCompletionTime = bq.MaxChallengeCompletionTime
+	sa.TimeUnit = conf.TimeUnit
+	return
+}
+
+// blobberCandidate is a blobber matches a blobbers query
+type blobberCandidate struct {
+	Blobber *StorageNode `json:"blobber"`
+	// Stake of the blobber's stake pool.
+	Stake state.Balance `json:"stake"`
+	// Distance to the queried geolocation, in kilometers.
+	Distance float64 `json:"distance,omitempty"`
+	// WriteCost is cost of the blobber's part of the allocation for the
+	// whole duration.
+	WriteCost state.Balance `json:"write_cost"`
+	// MinLockDemand of the blobber's part of the allocation.
+	MinLockDemand state.Balance `json:"min_lock_demand"`
+}
+
+// blobbersQueryResponse is list of candidates ranked from cheapest to most
+// expensive; the MinLockDemand and the WriteCost are totals of cheapest
+// candidates required by the allocation (data + parity), they are zero if
+// there are not enough candidates
+type blobbersQueryResponse struct {
+	BlobberSize   int64               `json:"blobber_size"`
+	Required      int                 `json:"required"`
+	MinLockDemand state.Balance       `json:"min_lock_demand"`
+	WriteCost     state.Balance       `json:"write_cost"`
+	Candidates    []*blobberCandidate `json:"candidates"`
+}
+
+// queryBlobbers returns blobbers can hold an allocation with given
+// parameters, ranked by cost
+func (sc *StorageSmartContract) queryBlobbers(bq *blobbersQuery,
+	now common.Timestamp, balances cstate.StateContextI) (
+	resp *blobbersQueryResponse, err error) {
+
+	var conf *scConfig
+	if conf, err = sc.getConfig(balances, false); err != nil {
+		return nil, errors.New(cantGetConfigErrMsg + ": " + err.Error())
+	}
+
+	bq.setDefaults(conf)
+
+	var all *StorageNodes
+	if all, err = sc.getBlobbersList(balances); err != nil {
+		return nil, errors.New("can't get blobbers list: " + err.Error())
+	}
+
+	var (
+		sa    = bq.storageAllocation(now, conf)
+		size  = sa.DataShards + sa.ParityShards
+		bSize = (sa.Size + int64(size-1)) / int64(size)
+		pools = make(map[string]*stakePool)
+	)
+
+	var list = sa.filterBlobbers(all.Nodes.copy(), now, bSize,
+		filterHealthyBlobbers(now),
+		sc.filterBlobbersByFreeSpace(now, bSize, balances),
+		func(b *StorageNode) (kick bool) {
+			if bq.Geolocation == nil {
+				return false
+			}
+			return bq.Geolocation.distance(b.Geolocation) > bq.Radius
+		},
+		func(b *StorageNode) (kick bool) {
+			var sp, err = sc.getStakePool(b.ID, balances)
+			if err != nil {
+				return true
+			}
+			pools[b.ID] = sp
+			return sp.stake() < bq.MinStake
+		})
+
+	var (
+		gbSize = sizeInGB(bSize)
+		rdtu   = sa.restDurationInTimeUnits(now)
+	)
+
+	resp = &blobbersQueryResponse{
+		BlobberSize: bSize,
+		Required:    size,
+		Candidates:  make([]*blobberCandidate, 0, len(list)),
+	}
+	for _, b := range list {
+		var bc = &blobberCandidate{
+			Blobber:       b,
+			Stake:         pools[b.ID].stake(),
+			WriteCost:     state.Balance(float64(b.Terms.WritePrice) * gbSize * rdtu),
+			MinLockDemand: b.Terms.minLockDemand(gbSize, rdtu),
+		}
+		if bq.Geolocation != nil {
+			bc.Distance = bq.Geolocation.distance(b.Geolocation)
+		}
+		resp.Candidates = append(resp.Candidates, bc)
+	}
+
+	// cheapest first, more staked first for the same price
+	sort.SliceStable(resp.Candidates, func(i, j int) bool {
+		var ci, cj = resp.Candidates[i], resp.Candidates[j]
+		if ci.WriteCost != cj.WriteCost {
+			return ci.WriteCost < cj.WriteCost
+		}
+		if ci.Blobber.Terms.ReadPrice != cj.Blobber.Terms.ReadPrice {
+			return ci.Blobber.Terms.ReadPrice < cj.Blobber.Terms.ReadPrice
+		}
+		return ci.Stake > cj.Stake
+	})
+
+	if len(resp.Candidates) >= size {
+		for _, bc := range resp.Candidates[:size] {
+			resp.MinLockDemand += bc.MinLockDemand
+			resp.WriteCost += bc.WriteCost
+		}
+	}
+
+	if bq.Limit > 0 && len(resp.Candidates) > bq.Limit {
+		resp.Candidates = resp.Candidates[:bq.Limit]
+	}
+	return
+}
+
+// QueryBlobbersHandler returns blobbers can hold an allocation with given in
+// 'query' URL parameter JSON encoded parameters, ranked by cost, with min
+// lock demand of the allocation; it used to preview cost of an allocation
+// before the new_allocation_request.
+func (sc *StorageSmartContract) QueryBlobbersHandler(ctx context.Context,
+	params url.Values, balances cstate.StateContextI) (
+	resp interface{}, err error) {
+
+	var bq blobbersQuery
+	if err = bq.decode([]byte(params.Get("query"))); err != nil {
+		return nil, common.NewErrBadRequest("can't decode 'query' URL " +
+			"query parameter: " + err.Error())
+	}
+	if err = bq.validate(); err != nil {
+		return nil, common.NewErrBadRequest("invalid query: " + err.Error())
+	}
+
+	var now = common.Timestamp(time.Now().Unix())
+	if resp, err = sc.queryBlobbers(&bq, now, balances); err != nil {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true,
+			"can't query blobbers")
+	}
+	return
+}
@@ -0,0 +1,151 @@
+package storagesc
+
+import (
+	"testing"
+	"time"
+
+	"0chain.net/core/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageNodeGeolocation_distance(t *testing.T) {
+	var (
+		london = StorageNodeGeolocation{Latitude: 51.5074, Longitude: -0.1278}
+		paris  = StorageNodeGeolocation{Latitude: 48.8566, Longitude: 2.3522}
+	)
+	assert.InDelta(t, 343.5, london.distance(paris), 1.0)
+	assert.InDelta(t, 343.5, paris.distance(london), 1.0)
+	assert.Zero(t, paris.distance(paris))
+}
+
+func TestStorageSmartContract_queryBlobbers(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		tp       = int64(100)
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	var (
+		cheap, avg, costly = avgTerms, avgTerms, avgTerms
+		blobs              []*Client
+	)
+	cheap.WritePrice = 2 * x10
+	costly.WritePrice = 10 * x10
+	for _, terms := range []Terms{costly, avg, cheap} {
+		blobs = append(blobs, addBlobber(t, ssc, 2*GB, tp, terms, 50*x10,
+			balances))
+	}
+
+	// place the blobbers: costly in Paris, others in London
+	var all *StorageNodes
+	all, err = ssc.getBlobbersList(balances)
+	require.NoError(t, err)
+	for _, b := range all.Nodes {
+		if b.ID == blobs[0].id {
+			b.Geolocation = StorageNodeGeolocation{48.8566, 2.3522}
+			continue
+		}
+		b.Geolocation = StorageNodeGeolocation{51.5074, -0.1278}
+	}
+	_, err = balances.InsertTrieNode(ALL_BLOBBERS_KEY, all)
+	require.NoError(t, err)
+
+	var query = func(bq blobbersQuery) (resp *blobbersQueryResponse) {
+		require.NoError(t, bq.validate())
+		resp, err = ssc.queryBlobbers(&bq, common.Timestamp(tp), balances)
+		require.NoError(t, err)
+		return
+	}
+
+	// ranked from cheapest
+	var resp = query(blobbersQuery{
+		Size:         2 * GB,
+		Duration:     30 * time.Minute,
+		DataShards:   2,
+		ParityShards: 0,
+	})
+	assert.EqualValues(t, 1*GB, resp.BlobberSize)
+	assert.Equal(t, 2, resp.Required)
+	require.Len(t, resp.Candidates, 3)
+	for i, id := range []string{blobs[2].id, blobs[1].id, blobs[0].id} {
+		assert.Equal(t, id, resp.Candidates[i].Blobber.ID)
+	}
+
+	var (
+		rdtu = float64(30*time.Minute) / float64(48*time.Hour) // test time unit
+		mld  = cheap.minLockDemand(1, rdtu) + avg.minLockDemand(1, rdtu)
+	)
+	assert.Equal(t, mld, resp.MinLockDemand)
+	assert.Equal(t, cheap.minLockDemand(1, rdtu),
+		resp.Candidates[0].MinLockDemand)
+	assert.NotZero(t, resp.WriteCost)
+
+	// write price range
+	resp = query(blobbersQuery{
+		Size:            2 * GB,
+		Duration:        30 * time.Minute,
+		WritePriceRange: PriceRange{Min: 3 * x10, Max: 20 * x10},
+	})
+	require.Len(t, resp.Candidates, 2)
+	assert.Equal(t, blobs[1].id, resp.Candidates[0].Blobber.ID)
+
+	// geolocation
+	resp = query(blobbersQuery{
+		Size:        2 * GB,
+		Duration:    30 * time.Minute,
+		DataShards:  2,
+		Geolocation: &StorageNodeGeolocation{48.85, 2.35},
+		Radius:      100,
+	})
+	require.Len(t, resp.Candidates, 1)
+	assert.Equal(t, blobs[0].id, resp.Candidates[0].Blobber.ID)
+	assert.True(t, resp.Candidates[0].Distance < 1)
+	assert.Zero(t, resp.MinLockDemand) // not enough blobbers
+
+	// min stake (the costly blobber has the biggest stake)
+	resp = query(blobbersQuery{
+		Size:     1 * GB,
+		Duration: 30 * time.Minute,
+		MinStake: resp.Candidates[0].Stake,
+	})
+	require.Len(t, resp.Candidates, 1)
+	assert.Equal(t, blobs[0].id, resp.Candidates[0].Blobber.ID)
+
+	// max challenge completion time
+	resp = query(blobbersQuery{
+		Size:                       1 * GB,
+		Duration:                   30 * time.Minute,
+		MaxChallengeCompletionTime: 100 * time.Second,
+	})
+	assert.Len(t, resp.Candidates, 0)
+
+	// duration is longer than max offer duration
+	resp = query(blobbersQuery{
+		Size:     1 * GB,
+		Duration: 2 * time.Hour,
+	})
+	assert.Len(t, resp.Candidates, 0)
+
+	// limit
+	resp = query(blobbersQuery{
+		Size:     1 * GB,
+		Duration: 30 * time.Minute,
+		Limit:    1,
+	})
+	require.Len(t, resp.Candidates, 1)
+	assert.Equal(t, blobs[2].id, resp.Candidates[0].Blobber.ID)
+
+	// invalid queries
+	assert.Error(t, (&blobbersQuery{Duration: time.Hour}).validate())
+	assert.Error(t, (&blobbersQuery{Size: GB}).validate())
+	assert.Error(t, (&blobbersQuery{
+		Size:        GB,
+		Duration:    time.Hour,
+		Geolocation: &StorageNodeGeolocation{},
+	}).validate())
+}
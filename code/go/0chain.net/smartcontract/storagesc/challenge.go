@@ -410,6 +410,9 @@ func (sc *StorageSmartContract) verifyChallenge(t *transaction.Transaction,
 			(success > failure && success+failure < threshold)
 		cct   = toSeconds(details.Terms.ChallengeCompletionTime)
 		fresh = challReq.Created+cct >= t.CreationDate
+
+		// challenge response latency for the blobber reputation
+		latency = time.Duration(t.CreationDate-challReq.Created) * time.Second
 	)
 
 	// verification, or partial verification
@@ -444,12 +447,26 @@ func (sc *StorageSmartContract) verifyChallenge(t *transaction.Transaction,
 			return "", common.NewError("challenge_reward_error", err.Error())
 		}
 
+		err = sc.updateBlobberReputation(t.ClientID, true, latency, 0,
+			t.CreationDate, balances)
+		if err != nil {
+			return "", common.NewError("challenge_reward_error", err.Error())
+		}
+
 		// save allocation object
 		_, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
 		if err != nil {
 			return "", common.NewError("challenge_reward_error", err.Error())
 		}
 
+		err = sc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+			Type:      allocEventChallengePass,
+			BlobberID: t.ClientID,
+		}, balances)
+		if err != nil {
+			return "", common.NewError("challenge_reward_error", err.Error())
+		}
+
 		if success < threshold {
 			return "challenge passed partially by blobber", nil
 		}
@@ -481,18 +498,33 @@ func (sc *StorageSmartContract) verifyChallenge(t *transaction.Transaction,
 		sc.challengeResolved(balances, false)
 		Logger.Info("Challenge failed", zap.Any("challenge", challResp.ID))
 
+		var penalty = details.Penalty // to get slashed tokens
 		err = sc.blobberPenalty(t, alloc, prev, blobberChall, details,
 			validators, balances)
 		if err != nil {
 			return "", common.NewError("challenge_penalty_error", err.Error())
 		}
 
+		err = sc.updateBlobberReputation(t.ClientID, false, latency,
+			details.Penalty-penalty, t.CreationDate, balances)
+		if err != nil {
+			return "", common.NewError("challenge_penalty_error", err.Error())
+		}
+
 		// save allocation object
 		_, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
 		if err != nil {
 			return "", common.NewError("challenge_reward_error", err.Error())
 		}
 
+		err = sc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+			Type:      allocEventChallengeFail,
+			BlobberID: t.ClientID,
+		}, balances)
+		if err != nil {
+			return "", common.NewError("challenge_penalty_error", err.Error())
+		}
+
 		if pass && !fresh {
 			return "late challenge (failed)", nil
 		}
@@ -571,37 +603,88 @@ func (sc *StorageSmartContract) generateChallenges(t *transaction.Transaction,
 	}
 	numChallenges := int64(math.Min(rated,
 		float64(conf.MaxChallengesPerGeneration)))
-	hashString := encryption.Hash(t.Hash + b.PrevHash)
-	var randomSeed uint64
-	randomSeed, err = strconv.ParseUint(hashString[0:16], 16, 64)
-	if err != nil {
+	// seed of the challenges derived from the round random seed
+	var cgs *challengeGenerations
+	if cgs, err = sc.getChallengeGenerations(balances); err != nil {
+		return common.NewErrorf("generate_challenges",
+			"can't get challenges generations: %v", err)
+	}
+	var cg = cgs.next(b, t.CreationDate, numChallenges)
+
+	var challenges []*StorageChallenge
+	if challenges, err = sc.newChallenges(cg, conf, balances); err != nil {
+		return
+	}
+
+	for _, chall := range challenges {
+		// statistics
+		var (
+			tp    = time.Now()
+			alloc *StorageAllocation
+		)
+		alloc, err = sc.getAllocation(chall.AllocationID, balances)
+		if err != nil {
+			return common.NewErrorf("adding_challenge_error",
+				"unexpected error getting allocation: %v", err)
+		}
+		var challengeString string
+		challengeString, err = sc.saveChallenge(alloc, chall, balances)
+		if err != nil {
+			Logger.Error("Error in adding challenge", zap.Error(err),
+				zap.Any("challengeString", challengeString))
+			continue
+		}
+		cg.Challenges = append(cg.Challenges, chall.ID)
+		if tm := sc.SmartContractExecutionStats["challenge_request"]; tm != nil {
+			if timer, ok := tm.(metrics.Timer); ok {
+				timer.Update(time.Since(tp))
+			}
+		}
+	}
+
+	// the generation with the challenges generated
+	if err = cgs.save(balances); err != nil {
+		return common.NewErrorf("generate_challenges",
+			"can't save challenges generations: %v", err)
+	}
+	return nil
+}
+
+// newChallenges selects allocations, blobbers and validators for challenges
+// of given generation; it doesn't change the state
+func (sc *StorageSmartContract) newChallenges(cg *challengeGeneration,
+	conf *scConfig, balances c_state.StateContextI) (
+	challenges []*StorageChallenge, err error) {
+
+	var randomSeed int64
+	if randomSeed, err = cg.randomSeed(); err != nil {
 		Logger.Error("Error in creating seed for creating challenges",
 			zap.Error(err))
-		return err
+		return
 	}
-	r := rand.New(rand.NewSource(int64(randomSeed)))
+	r := rand.New(rand.NewSource(randomSeed))
 
 	// select allocations for the challenges
 
 	var validators *ValidatorNodes
 	if validators, err = sc.getValidatorsList(balances); err != nil {
-		return common.NewErrorf("adding_challenge_error",
+		return nil, common.NewErrorf("adding_challenge_error",
 			"error getting the validators list: %v", err)
 	}
 
 	if len(validators.Nodes) == 0 {
-		return common.NewError("no_validators",
+		return nil, common.NewError("no_validators",
 			"not enough validators for the challenge")
 	}
 
 	var all *Allocations
 	if all, err = sc.getAllAllocationsList(balances); err != nil {
-		return common.NewErrorf("adding_challenge_error",
+		return nil, common.NewErrorf("adding_challenge_error",
 			"error getting the allocation list: %v", err)
 	}
 
 	if len(all.List) == 0 {
-		return common.NewError("adding_challenge_error",
+		return nil, common.NewError("adding_challenge_error",
 			"no allocations at this time")
 	}
 
@@ -618,7 +701,7 @@ func (sc *StorageSmartContract) generateChallenges(t *transaction.Transaction,
 			return nil, common.NewErrorf("invalid_allocation",
 				"client state has invalid allocations")
 		}
-		if alloc.Expiration < t.CreationDate {
+		if alloc.Expiration < cg.CreationDate {
 			return nil, nil
 		}
 		if alloc.Stats == nil {
@@ -636,22 +719,28 @@ func (sc *StorageSmartContract) generateChallenges(t *transaction.Transaction,
 
 	var alloc *StorageAllocation
 
-	for i := int64(0); i < numChallenges; i++ {
+	for i := int64(0); i < cg.NumChallenges; i++ {
 
 		// looking for allocation with NumWrites > 0
 
 		alloc, err = selectAlloc(r.Intn(len(all.List)))
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if alloc == nil {
 			continue // try another one
 		}
 
+		// allocations of a storage class challenged rarely
+		var rate = conf.challengeRate(alloc.StorageClass)
+		if rate < 1.0 && r.Float64() >= rate {
+			continue // skip the challenge
+		}
+
 		// found
 
-		challengeID := encryption.Hash(hashString + strconv.FormatInt(i, 10))
+		challengeID := encryption.Hash(cg.Seed + strconv.FormatInt(i, 10))
 		var challengeSeed uint64
 		challengeSeed, err = strconv.ParseUint(challengeID[0:16], 16, 64)
 		if err != nil {
@@ -659,25 +748,17 @@ func (sc *StorageSmartContract) generateChallenges(t *transaction.Transaction,
 				zap.Any("challengeID", challengeID))
 			continue
 		}
-		// statistics
-		var (
-			tp              = time.Now()
-			challengeString string
-		)
-		challengeString, err = sc.addChallenge(alloc, validators, challengeID,
-			t.CreationDate, r, int64(challengeSeed), balances)
+		var chall *StorageChallenge
+		chall, err = newStorageChallenge(alloc, validators, challengeID,
+			cg.CreationDate, r, int64(challengeSeed))
 		if err != nil {
-			Logger.Error("Error in adding challenge", zap.Error(err),
-				zap.Any("challengeString", challengeString))
+			Logger.Error("Error in creating challenge", zap.Error(err),
+				zap.Any("challengeID", challengeID))
 			continue
 		}
-		if tm := sc.SmartContractExecutionStats["challenge_request"]; tm != nil {
-			if timer, ok := tm.(metrics.Timer); ok {
-				timer.Update(time.Since(tp))
-			}
-		}
+		challenges = append(challenges, chall)
 	}
-	return nil
+	return challenges, nil
 }
 
 func (sc *StorageSmartContract) addChallenge(alloc *StorageAllocation,
@@ -685,6 +766,22 @@ func (sc *StorageSmartContract) addChallenge(alloc *StorageAllocation,
 	creationDate common.Timestamp, r *rand.Rand, challengeSeed int64,
 	balances c_state.StateContextI) (resp string, err error) {
 
+	var storageChallenge *StorageChallenge
+	storageChallenge, err = newStorageChallenge(alloc, validators, challengeID,
+		creationDate, r, challengeSeed)
+	if err != nil {
+		return
+	}
+	return sc.saveChallenge(alloc, storageChallenge, balances)
+}
+
+// newStorageChallenge selects blobber of the allocation and validators for
+// a challenge using given random source
+func newStorageChallenge(alloc *StorageAllocation,
+	validators *ValidatorNodes, challengeID string,
+	creationDate common.Timestamp, r *rand.Rand, challengeSeed int64) (
+	storageChallenge *StorageChallenge, err error) {
+
 	sort.SliceStable(alloc.Blobbers, func(i, j int) bool {
 		return alloc.Blobbers[i].ID < alloc.Blobbers[j].ID
 	})
@@ -701,7 +798,7 @@ func (sc *StorageSmartContract) addChallenge(alloc *StorageAllocation,
 			Logger.Error("Selected blobber not found in allocation state",
 				zap.Any("selected_blobber", selectedBlobberObj),
 				zap.Any("blobber_map", alloc.BlobberMap))
-			return "", common.NewError("invalid_parameters",
+			return nil, common.NewError("invalid_parameters",
 				"Blobber is not part of the allocation. Could not find blobber")
 		}
 		blobberAllocation = alloc.BlobberMap[selectedBlobberObj.ID]
@@ -711,7 +808,7 @@ func (sc *StorageSmartContract) addChallenge(alloc *StorageAllocation,
 	}
 
 	if blobberAllocation.AllocationRoot == "" {
-		return "", common.NewErrorf("no_blobber_writes", "no blobber writes, "+
+		return nil, common.NewErrorf("no_blobber_writes", "no blobber writes, "+
 			"challenge generation not possible, allocation %s, blobber: %s",
 			alloc.ID, blobberAllocation.BlobberID)
 	}
@@ -728,7 +825,7 @@ func (sc *StorageSmartContract) addChallenge(alloc *StorageAllocation,
 		}
 	}
 
-	var storageChallenge StorageChallenge
+	storageChallenge = new(StorageChallenge)
 	storageChallenge.ID = challengeID
 	storageChallenge.Validators = selectedValidators
 	storageChallenge.Blobber = selectedBlobberObj
@@ -736,6 +833,21 @@ func (sc *StorageSmartContract) addChallenge(alloc *StorageAllocation,
 	storageChallenge.AllocationID = alloc.ID
 
 	storageChallenge.AllocationRoot = blobberAllocation.AllocationRoot
+	storageChallenge.Created = creationDate
+	return
+}
+
+// saveChallenge adds the challenge to related blobber challenges and
+// updates challenges statistic
+func (sc *StorageSmartContract) saveChallenge(alloc *StorageAllocation,
+	storageChallenge *StorageChallenge, balances c_state.StateContextI) (
+	resp string, err error) {
+
+	blobberAllocation, ok := alloc.BlobberMap[storageChallenge.Blobber.ID]
+	if !ok {
+		return "", common.NewError("invalid_parameters",
+			"Blobber is not part of the allocation. Could not find blobber")
+	}
 
 	blobberChallengeObj := &BlobberChallenge{}
 	blobberChallengeObj.BlobberID = storageChallenge.Blobber.ID
@@ -749,8 +861,7 @@ func (sc *StorageSmartContract) addChallenge(alloc *StorageAllocation,
 		}
 	}
 
-	storageChallenge.Created = creationDate
-	addedChallege := blobberChallengeObj.addChallenge(&storageChallenge)
+	addedChallege := blobberChallengeObj.addChallenge(storageChallenge)
 	if !addedChallege {
 		challengeBytes, err := json.Marshal(storageChallenge)
 		return string(challengeBytes), err
@@ -0,0 +1,228 @@
+package storagesc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"0chain.net/chaincore/block"
+	c_state "0chain.net/chaincore/chain/state"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+	"0chain.net/smartcontract"
+)
+
+// maxChallengeGenerations is number of last challenges generations kept to
+// recompute and prove challenges of recent rounds
+const maxChallengeGenerations = 100
+
+// challengesSeed returns seed of challenges generation derived from the round
+// random seed, which is aggregated VRF output of the round and can't be
+// biased by a block producer; the index is number of the generation within
+// the round, since challenges can be generated more than once in a block
+func challengesSeed(round, roundRandomSeed, index int64) string {
+	return encryption.Hash(strconv.FormatInt(round, 10) + ":" +
+		strconv.FormatInt(roundRandomSeed, 10) + ":" +
+		strconv.FormatInt(index, 10))
+}
+
+// challengeGeneration is input of a challenges generation; it's enough to
+// recompute the challenges using the same state; IDs of the challenges
+// generated are kept to compare recomputed challenges with
+type challengeGeneration struct {
+	Round           int64            `json:"round"`
+	RoundRandomSeed int64            `json:"round_random_seed"`
+	Index           int64            `json:"index"`
+	Seed            string           `json:"seed"`
+	NumChallenges   int64            `json:"num_challenges"`
+	CreationDate    common.Timestamp `json:"creation_date"`
+	Challenges      []string         `json:"challenges"`
+}
+
+// matches returns true if given challenges are the challenges generated
+func (cg *challengeGeneration) matches(challenges []*StorageChallenge) bool {
+	if len(challenges) != len(cg.Challenges) {
+		return false
+	}
+	for i, chall := range challenges {
+		if chall.ID != cg.Challenges[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// randomSeed of math/rand source used to select allocations, blobbers and
+// validators for the challenges
+func (cg *challengeGeneration) randomSeed() (seed int64, err error) {
+	var useed uint64
+	if useed, err = strconv.ParseUint(cg.Seed[0:16], 16, 64); err != nil {
+		return
+	}
+	return int64(useed), nil
+}
+
+// verify the generation seed derived from its round random seed
+func (cg *challengeGeneration) verify() bool {
+	return cg.Seed == challengesSeed(cg.Round, cg.RoundRandomSeed, cg.Index)
+}
+
+// challengeGenerations is bounded log of last challenges generations
+type challengeGenerations struct {
+	Generations []*challengeGeneration `json:"generations"`
+}
+
+// Encode to []byte
+func (cgs *challengeGenerations) Encode() (b []byte) {
+	var err error
+	if b, err = json.Marshal(cgs); err != nil {
+		panic(err) // must never happens
+	}
+	return
+}
+
+// Decode from []byte
+func (cgs *challengeGenerations) Decode(input []byte) error {
+	return json.Unmarshal(input, cgs)
+}
+
+// next generation of given block
+func (cgs *challengeGenerations) next(b *block.Block,
+	now common.Timestamp, numChallenges int64) (cg *challengeGeneration) {
+
+	cg = new(challengeGeneration)
+	cg.Round = b.Round
+	cg.RoundRandomSeed = b.GetRoundRandomSeed()
+	if l := len(cgs.Generations); l > 0 && cgs.Generations[l-1].Round == b.Round {
+		cg.Index = cgs.Generations[l-1].Index + 1
+	}
+	cg.Seed = challengesSeed(cg.Round, cg.RoundRandomSeed, cg.Index)
+	cg.NumChallenges = numChallenges
+	cg.CreationDate = now
+
+	cgs.Generations = append(cgs.Generations, cg)
+	if over := len(cgs.Generations) - maxChallengeGenerations; over > 0 {
+		cgs.Generations = append(cgs.Generations[:0],
+			cgs.Generations[over:]...)
+	}
+	return
+}
+
+// of given round
+func (cgs *challengeGenerations) round(round int64) (
+	list []*challengeGeneration) {
+
+	for _, cg := range cgs.Generations {
+		if cg.Round == round {
+			list = append(list, cg)
+		}
+	}
+	return
+}
+
+func (cgs *challengeGenerations) save(balances c_state.StateContextI) (
+	err error) {
+
+	_, err = balances.InsertTrieNode(CHALLENGE_GENERATIONS_KEY, cgs)
+	return
+}
+
+func (sc *StorageSmartContract) getChallengeGenerations(
+	balances c_state.StateContextI) (cgs *challengeGenerations, err error) {
+
+	var val util.Serializable
+	cgs = new(challengeGenerations)
+	val, err = balances.GetTrieNode(CHALLENGE_GENERATIONS_KEY)
+	if err == util.ErrValueNotPresent {
+		return cgs, nil // empty
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = cgs.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return
+}
+
+//
+// stat
+//
+
+// generationChallenges is recomputed challenges of a generation
+type generationChallenges struct {
+	*challengeGeneration
+	// Verified is true if the generation seed derived from the round
+	// random seed of the finalized block of the round; the state of the
+	// round should be requested to verify it.
+	Verified bool `json:"verified"`
+	// Matched is true if the recomputed challenges are the challenges
+	// generated; the recomputed challenges can differ, since the state
+	// requested is the state after the round, not the state the challenges
+	// generated on.
+	Matched    bool                `json:"matched"`
+	Recomputed []*StorageChallenge `json:"recomputed"`
+}
+
+// roundChallenges is response of the round challenges handler
+type roundChallenges struct {
+	Round       int64                   `json:"round"`
+	Generations []*generationChallenges `json:"generations"`
+}
+
+// getRoundChallengesHandler returns IDs of challenges generated in a given
+// round and recomputes them; the challenges are recomputed using the
+// requested state, which is the state of the round for the REST API 'round'
+// parameter, otherwise the latest finalized state, and they are compared
+// with the generated ones
+func (sc *StorageSmartContract) getRoundChallengesHandler(
+	ctx context.Context, params url.Values,
+	balances c_state.StateContextI) (resp interface{}, err error) {
+
+	var round int64
+	if round, err = strconv.ParseInt(params.Get("round"), 10, 64); err != nil {
+		return nil, common.NewErrBadRequest("missing or invalid 'round' URL query parameter")
+	}
+
+	var cgs *challengeGenerations
+	if cgs, err = sc.getChallengeGenerations(balances); err != nil {
+		return nil, common.NewErrInternal("can't get challenges generations",
+			err.Error())
+	}
+
+	var list = cgs.round(round)
+	if len(list) == 0 {
+		return nil, common.NewErrNoResource("no challenges generations of " +
+			"the round, or the round is too old")
+	}
+
+	var conf *scConfig
+	if conf, err = sc.getConfig(balances, false); err != nil {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true,
+			cantGetConfigErrMsg)
+	}
+
+	// the round random seed of the generations can be verified against the
+	// block of the round only
+	var (
+		b  = balances.GetBlock()
+		rc = roundChallenges{Round: round}
+	)
+	for _, cg := range list {
+		var gc = &generationChallenges{challengeGeneration: cg}
+		gc.Verified = cg.verify() && b != nil && b.Round == round &&
+			b.GetRoundRandomSeed() == cg.RoundRandomSeed
+		gc.Recomputed, err = sc.newChallenges(cg, conf, balances)
+		if err != nil {
+			return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true,
+				"can't recompute challenges")
+		}
+		gc.Matched = cg.matches(gc.Recomputed)
+		rc.Generations = append(rc.Generations, gc)
+	}
+
+	return &rc, nil
+}
@@ -0,0 +1,184 @@
+package storagesc
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_challengeGenerations_next(t *testing.T) {
+	var (
+		cgs challengeGenerations
+		b   = new(block.Block)
+	)
+	b.Round = 10
+	b.SetRoundRandomSeed(12345)
+
+	var cg = cgs.next(b, 100, 5)
+	assert.EqualValues(t, 0, cg.Index)
+	assert.Equal(t, challengesSeed(10, 12345, 0), cg.Seed)
+	assert.True(t, cg.verify())
+
+	// the same round
+	cg = cgs.next(b, 100, 5)
+	assert.EqualValues(t, 1, cg.Index)
+	assert.NotEqual(t, cgs.Generations[0].Seed, cg.Seed)
+
+	// next round
+	b = new(block.Block)
+	b.Round = 11
+	b.SetRoundRandomSeed(12345)
+	cg = cgs.next(b, 101, 5)
+	assert.EqualValues(t, 0, cg.Index)
+	assert.Len(t, cgs.round(10), 2)
+	assert.Len(t, cgs.round(11), 1)
+
+	// forged seed
+	cg.RoundRandomSeed++
+	assert.False(t, cg.verify())
+
+	// bounded
+	for i := 0; i < maxChallengeGenerations; i++ {
+		cgs.next(b, 101, 5)
+	}
+	assert.Len(t, cgs.Generations, maxChallengeGenerations)
+	assert.Len(t, cgs.round(10), 0)
+}
+
+func TestStorageSmartContract_getRoundChallengesHandler(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		tp, exp  = int64(100), int64(toSeconds(time.Hour))
+		ctx      = context.Background()
+		params   = make(url.Values)
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	var allocID, blobs = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		addValidator(t, ssc, tp, balances)
+	}
+
+	// write 100 MB to a blobber
+	var b1 *Client
+	for _, b := range blobs {
+		if b.id == alloc.BlobberDetails[0].BlobberID {
+			b1 = b
+			break
+		}
+	}
+	require.NotNil(t, b1)
+
+	const allocRoot = "alloc-root-1"
+	tp += 100
+	var cc = &BlobberCloseConnection{
+		AllocationRoot: allocRoot,
+		WriteMarker: &WriteMarker{
+			AllocationRoot: allocRoot,
+			AllocationID:   allocID,
+			Size:           100 * 1024 * 1024,
+			BlobberID:      b1.id,
+			Timestamp:      common.Timestamp(tp),
+			ClientID:       client.id,
+		},
+	}
+	cc.WriteMarker.Signature, err = client.scheme.Sign(
+		encryption.Hash(cc.WriteMarker.GetHashData()))
+	require.NoError(t, err)
+
+	var tx = newTransaction(b1.id, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.commitBlobberConnection(tx, mustEncode(t, &cc), balances)
+	require.NoError(t, err)
+
+	// generate challenges
+	var blk = new(block.Block)
+	blk.Round = 50
+	blk.SetRoundRandomSeed(987654321)
+	balances.block = blk
+
+	tp += 100
+	tx = newTransaction(client.id, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	require.NoError(t, ssc.generateChallenges(tx, blk, nil, balances))
+
+	var bc *BlobberChallenge
+	bc, err = ssc.getBlobberChallenge(b1.id, balances)
+	require.NoError(t, err)
+	require.NotZero(t, len(bc.Challenges))
+
+	// recompute
+	_, err = ssc.getRoundChallengesHandler(ctx, params, balances)
+	require.Error(t, err)
+
+	params.Set("round", "49")
+	_, err = ssc.getRoundChallengesHandler(ctx, params, balances)
+	require.Error(t, err)
+
+	params.Set("round", strconv.FormatInt(blk.Round, 10))
+	var resp interface{}
+	resp, err = ssc.getRoundChallengesHandler(ctx, params, balances)
+	require.NoError(t, err)
+
+	var rc = resp.(*roundChallenges)
+	require.Len(t, rc.Generations, 1)
+	var gc = rc.Generations[0]
+	assert.True(t, gc.Verified)
+	assert.Equal(t, challengesSeed(50, 987654321, 0), gc.Seed)
+	assert.True(t, gc.Matched)
+	require.Len(t, gc.Challenges, len(bc.Challenges))
+	require.Len(t, gc.Recomputed, len(bc.Challenges))
+	for i, chall := range gc.Recomputed {
+		assert.Equal(t, bc.Challenges[i].ID, gc.Challenges[i])
+		assert.Equal(t, bc.Challenges[i].ID, chall.ID)
+		assert.Equal(t, bc.Challenges[i].Blobber.ID, chall.Blobber.ID)
+		assert.Equal(t, bc.Challenges[i].RandomNumber, chall.RandomNumber)
+		require.Len(t, chall.Validators, len(bc.Challenges[i].Validators))
+		for j, v := range chall.Validators {
+			assert.Equal(t, bc.Challenges[i].Validators[j].ID, v.ID)
+		}
+	}
+
+	// another round random seed of the round
+	blk.SetRoundRandomSeed(123)
+	resp, err = ssc.getRoundChallengesHandler(ctx, params, balances)
+	require.NoError(t, err)
+	assert.False(t, resp.(*roundChallenges).Generations[0].Verified)
+
+	// state of another round, can't be verified
+	blk.Round = 51
+	blk.SetRoundRandomSeed(987654321)
+	resp, err = ssc.getRoundChallengesHandler(ctx, params, balances)
+	require.NoError(t, err)
+	assert.False(t, resp.(*roundChallenges).Generations[0].Verified)
+
+	// the state is changed since, the generated challenges are kept
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	alloc.Stats.NumWrites = 0
+	mustSave(t, alloc.GetKey(ssc.ID), alloc, balances)
+	resp, err = ssc.getRoundChallengesHandler(ctx, params, balances)
+	require.NoError(t, err)
+	gc = resp.(*roundChallenges).Generations[0]
+	assert.False(t, gc.Matched)
+	assert.Len(t, gc.Recomputed, 0)
+	assert.Len(t, gc.Challenges, len(bc.Challenges))
+}
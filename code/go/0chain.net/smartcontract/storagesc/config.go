@@ -157,6 +157,19 @@ type scConfig struct {
 
 	BlockReward *blockReward `json:"block_reward"`
 
+	// AutoRenewWindow is time before expiration of an allocation with
+	// auto-renewal enabled when the allocation is renewed; zero disables
+	// auto-renewals.
+	AutoRenewWindow time.Duration `json:"auto_renew_window"`
+	// MaxAutoRenewals is max number of allocations renewed at once.
+	MaxAutoRenewals int `json:"max_auto_renewals"`
+	// MaxAutoRenewFailures is number of failed renewals of an allocation
+	// in a row its auto-renewal is disabled after; zero disables the limit.
+	MaxAutoRenewFailures int `json:"max_auto_renew_failures"`
+
+	// StorageClasses is rules of blobbers storage classes by class name.
+	StorageClasses map[string]*storageClassConfig `json:"storage_classes,omitempty"`
+
 	// Allow direct access to MPT
 	ExposeMpt bool `json:"expose_mpt"`
 }
@@ -307,6 +320,26 @@ func (sc *scConfig) validate() (err error) {
 		return fmt.Errorf("negative block_reward.bobber_usage_weight: %v",
 			sc.BlockReward.BlobberUsageWeight)
 	}
+	if sc.AutoRenewWindow < 0 {
+		return fmt.Errorf("negative auto_renew_window: %v",
+			sc.AutoRenewWindow)
+	}
+	if sc.MaxAutoRenewals < 0 {
+		return fmt.Errorf("negative max_auto_renewals: %v",
+			sc.MaxAutoRenewals)
+	}
+	if sc.MaxAutoRenewFailures < 0 {
+		return fmt.Errorf("negative max_auto_renew_failures: %v",
+			sc.MaxAutoRenewFailures)
+	}
+	for class, scc := range sc.StorageClasses {
+		if scc == nil {
+			return fmt.Errorf("missing storage_classes.%s", class)
+		}
+		if err = scc.validate(); err != nil {
+			return fmt.Errorf("invalid storage_classes.%s: %v", class, err)
+		}
+	}
 	return
 }
 
@@ -450,6 +483,30 @@ func getConfiguredConfig() (conf *scConfig, err error) {
 	)
 	conf.ExposeMpt = scc.GetBool(pfx + "expose_mpt")
 
+	// auto-renewals
+	conf.AutoRenewWindow = scc.GetDuration(pfx + "auto_renew_window")
+	conf.MaxAutoRenewals = scc.GetInt(pfx + "max_auto_renewals")
+	conf.MaxAutoRenewFailures = scc.GetInt(pfx + "max_auto_renew_failures")
+
+	// storage classes
+	for class := range scc.GetStringMap(pfx + "storage_classes") {
+		var (
+			cls = pfx + "storage_classes." + class + "."
+			cc  = new(storageClassConfig)
+		)
+		cc.ChallengeRate = scc.GetFloat64(cls + "challenge_rate")
+		cc.MaxReadPrice = state.Balance(
+			scc.GetFloat64(cls+"max_read_price") * 1e10)
+		cc.MaxWritePrice = state.Balance(
+			scc.GetFloat64(cls+"max_write_price") * 1e10)
+		cc.MaxChallengeCompletionTime = scc.GetDuration(
+			cls + "max_challenge_completion_time")
+		if conf.StorageClasses == nil {
+			conf.StorageClasses = make(map[string]*storageClassConfig)
+		}
+		conf.StorageClasses[class] = cc
+	}
+
 	err = conf.validate()
 	return
 }
@@ -518,12 +575,15 @@ func (ssc *StorageSmartContract) getConfigHandler(ctx context.Context,
 	return conf, nil // actual value
 }
 
-// updateConfig is SC function used by SC owner
-// to update storage SC configurations
+// updateConfig is SC function used by SC owner or by the governance SC
+// to update storage SC configurations; the owner replaces all the
+// configurations, while the governance SC applies a diff of an accepted
+// proposal keeping fields missing in it as is; a null storage class of
+// the diff removes the class
 func (ssc *StorageSmartContract) updateConfig(t *transaction.Transaction,
 	input []byte, balances chainState.StateContextI) (resp string, err error) {
 
-	if t.ClientID != owner {
+	if !smartcontract.IsConfigOwner(t.ClientID, owner) {
 		return "", common.NewError("update_config",
 			"unauthorized access - only the owner can update the variables")
 	}
@@ -534,8 +594,14 @@ func (ssc *StorageSmartContract) updateConfig(t *transaction.Transaction,
 			"can't get config: "+err.Error())
 	}
 
-	var update scConfig
-	if err = update.Decode(input); err != nil {
+	var update = new(scConfig)
+	if t.ClientID == smartcontract.GovernanceAddress {
+		*update = *conf
+		err = mergeConfigUpdate(input, update)
+	} else {
+		err = update.Decode(input)
+	}
+	if err != nil {
 		return "", common.NewError("update_config", err.Error())
 	}
 
@@ -545,7 +611,7 @@ func (ssc *StorageSmartContract) updateConfig(t *transaction.Transaction,
 
 	update.Minted = conf.Minted
 
-	_, err = balances.InsertTrieNode(scConfigKey(ssc.ID), &update)
+	_, err = balances.InsertTrieNode(scConfigKey(ssc.ID), update)
 	if err != nil {
 		return "", common.NewError("update_config", err.Error())
 	}
@@ -553,6 +619,46 @@ func (ssc *StorageSmartContract) updateConfig(t *transaction.Transaction,
 	return string(update.Encode()), nil
 }
 
+// ValidateConfigUpdate validates given update_config input applied to
+// current configurations.
+func ValidateConfigUpdate(input []byte, balances chainState.StateContextI) (
+	err error) {
+
+	var conf *scConfig
+	switch val, err := balances.GetTrieNode(scConfigKey(ADDRESS)); err {
+	case nil:
+		conf = new(scConfig)
+		if err = conf.Decode(val.Encode()); err != nil {
+			return fmt.Errorf("%w: %s", common.ErrDecoding, err)
+		}
+	case util.ErrValueNotPresent:
+		if conf, err = getConfiguredConfig(); err != nil {
+			return fmt.Errorf("can't get config: %v", err)
+		}
+	default:
+		return fmt.Errorf("can't get config: %v", err)
+	}
+
+	if err = mergeConfigUpdate(input, conf); err != nil {
+		return fmt.Errorf("decoding configurations: %v", err)
+	}
+	return conf.validate()
+}
+
+// mergeConfigUpdate decodes configurations diff on top of given
+// configurations; null storage classes of the diff are removed
+func mergeConfigUpdate(input []byte, conf *scConfig) (err error) {
+	if err = smartcontract.DecodeConfigUpdate(input, conf); err != nil {
+		return
+	}
+	for class, scc := range conf.StorageClasses {
+		if scc == nil {
+			delete(conf.StorageClasses, class)
+		}
+	}
+	return
+}
+
 // getWritePoolConfig
 func (ssc *StorageSmartContract) getWritePoolConfig(
 	balances chainState.StateContextI, setup bool) (
@@ -1,10 +1,88 @@
 package storagesc
 
 import (
-	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
+
+	"0chain.net/smartcontract"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func TestStorageSmartContract_updateConfig(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		conf     = setConfig(t, balances)
+		err      error
+	)
+
+	conf.Minted = 10e10
+	conf.FreeAllocationSettings.Duration = time.Hour
+	conf.BlockReward = new(blockReward)
+	mustSave(t, scConfigKey(ADDRESS), conf, balances)
+
+	var update = func(clientID, input string) (err error) {
+		var tx = newTransaction(clientID, ADDRESS, 0, 0)
+		balances.setTransaction(t, tx)
+		_, err = ssc.updateConfig(tx, []byte(input), balances)
+		return
+	}
+
+	// the governance SC keeps missing fields, including nested ones, as is
+	require.NoError(t, update(smartcontract.GovernanceAddress,
+		`{"max_delegates":100,"readpool":{"min_lock":20},"minted":0,`+
+			`"storage_classes":{"hot":{"challenge_rate":1},`+
+			`"cold":{"challenge_rate":0.1}}}`))
+	conf, err = ssc.getConfig(balances, false)
+	require.NoError(t, err)
+	assert.Equal(t, 100, conf.MaxDelegates)
+	assert.EqualValues(t, 20, conf.ReadPool.MinLock)
+	assert.Equal(t, 5*time.Second, conf.ReadPool.MinLockPeriod)
+	assert.EqualValues(t, 100e10, conf.MaxMint)
+	assert.EqualValues(t, 10e10, conf.Minted)
+	assert.Len(t, conf.StorageClasses, 2)
+
+	err = update(smartcontract.GovernanceAddress, `{"max_delegates":0}`)
+	require.Error(t, err)
+	err = update(smartcontract.GovernanceAddress, `{"max_delegate":50}`)
+	require.Error(t, err)
+
+	// a null storage class removes the class
+	require.NoError(t, update(smartcontract.GovernanceAddress,
+		`{"storage_classes":{"cold":null}}`))
+	conf, err = ssc.getConfig(balances, false)
+	require.NoError(t, err)
+	require.Len(t, conf.StorageClasses, 1)
+	assert.NotNil(t, conf.StorageClasses["hot"])
+
+	// the owner replaces all the configurations
+	var replace = *conf
+	replace.MaxDelegates = 50
+	replace.StorageClasses = nil
+	require.NoError(t, update(owner, string(replace.Encode())))
+	conf, err = ssc.getConfig(balances, false)
+	require.NoError(t, err)
+	assert.Equal(t, 50, conf.MaxDelegates)
+	assert.Empty(t, conf.StorageClasses)
+	assert.EqualValues(t, 10e10, conf.Minted)
+
+	err = update(owner, `{"max_delegates":100}`)
+	require.Error(t, err)
+
+	// the validation used by the governance SC is strict
+	require.NoError(t, ValidateConfigUpdate([]byte(`{"max_delegates":60}`),
+		balances))
+	err = ValidateConfigUpdate([]byte(`{"max_delegate":50}`), balances)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "max_delegate"`)
+	require.Error(t, ValidateConfigUpdate([]byte(`{"max_delegates":0}`),
+		balances))
+	require.NoError(t, ValidateConfigUpdate(
+		[]byte(`{"storage_classes":{"hot":null}}`), balances))
+}
+
 func TestGetBlockPayments(t *testing.T) {
 	type want struct {
 		reward blockReward
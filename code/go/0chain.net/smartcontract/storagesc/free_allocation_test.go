@@ -312,6 +312,17 @@ func TestFreeAllocationRequest(t *testing.T) {
 		balances.On(
 			"InsertTrieNode", allocation.GetKey(ssc.ID), mock.Anything,
 		).Return("", nil).Once()
+		balances.On(
+			"GetTrieNode", allocationEventsKey(ssc.ID, txn.Hash),
+		).Return(nil, util.ErrValueNotPresent).Once()
+		balances.On(
+			"InsertTrieNode", allocationEventsKey(ssc.ID, txn.Hash),
+			mock.Anything,
+		).Return("", nil).Once()
+		balances.On(
+			"InsertTrieNode", allocationEventsPageKey(ssc.ID, txn.Hash, 0),
+			mock.Anything,
+		).Return("", nil).Once()
 
 		balances.On(
 			"InsertTrieNode",
@@ -628,6 +639,17 @@ func TestUpdateFreeStorageRequest(t *testing.T) {
 		balances.On(
 			"InsertTrieNode", sa.GetKey(ssc.ID), mock.Anything,
 		).Return("", nil).Once()
+		balances.On(
+			"GetTrieNode", allocationEventsKey(ssc.ID, p.allocationId),
+		).Return(nil, util.ErrValueNotPresent).Once()
+		balances.On(
+			"InsertTrieNode", allocationEventsKey(ssc.ID, p.allocationId),
+			mock.Anything,
+		).Return("", nil).Once()
+		balances.On(
+			"InsertTrieNode", allocationEventsPageKey(ssc.ID, p.allocationId, 0),
+			mock.Anything,
+		).Return("", nil).Once()
 
 		balances.On(
 			"InsertTrieNode", ALL_BLOBBERS_KEY, mock.Anything,
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 
 	"0chain.net/core/logging"
@@ -135,6 +136,60 @@ func (ssc *StorageSmartContract) AllocationStatsHandler(ctx context.Context, par
 	return allocationObj, nil
 }
 
+// AllocationEventsHandler returns page of events of an allocation; the offset
+// is sequence number of last event already seen by the client.
+func (ssc *StorageSmartContract) AllocationEventsHandler(ctx context.Context,
+	params url.Values, balances cstate.StateContextI) (
+	resp interface{}, err error) {
+
+	var allocID = params.Get("allocation")
+	if allocID == "" {
+		return nil, common.NewErrBadRequest("missing 'allocation' URL query parameter")
+	}
+
+	var (
+		offset int64
+		limit  = defaultAllocationEventsLimit
+	)
+	if v := params.Get("offset"); v != "" {
+		if offset, err = strconv.ParseInt(v, 10, 64); err != nil || offset < 0 {
+			return nil, common.NewErrBadRequest("invalid 'offset' URL query parameter")
+		}
+	}
+	if v := params.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			return nil, common.NewErrBadRequest("invalid 'limit' URL query parameter")
+		}
+		if limit > maxAllocationEventsLimit {
+			limit = maxAllocationEventsLimit
+		}
+	}
+
+	if _, err = ssc.getAllocation(allocID, balances); err != nil {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetAllocation)
+	}
+
+	var ae *allocationEvents
+	switch ae, err = ssc.getAllocationEvents(allocID, balances); err {
+	case nil:
+	case util.ErrValueNotPresent:
+		ae = newAllocationEvents(allocID) // no events yet
+	default:
+		return nil, common.NewErrInternal("can't get allocation events", err.Error())
+	}
+
+	var events []*AllocationEvent
+	if events, err = ae.page(ssc.ID, offset, limit, balances); err != nil {
+		return nil, common.NewErrInternal("can't get allocation events", err.Error())
+	}
+
+	return &allocationEvents{
+		AllocationID: ae.AllocationID,
+		Total:        ae.Total,
+		Events:       events,
+	}, nil
+}
+
 func (ssc *StorageSmartContract) LatestReadMarkerHandler(ctx context.Context,
 	params url.Values, balances cstate.StateContextI) (
 	resp interface{}, err error) {
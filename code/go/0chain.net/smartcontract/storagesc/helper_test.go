@@ -1,6 +1,7 @@
 package storagesc
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math/rand"
 	"strconv"
@@ -76,7 +77,8 @@ func newClient(balance state.Balance, balances chainState.StateContextI) (
 	client.scheme = scheme
 
 	client.pk = scheme.GetPublicKey()
-	client.id = encryption.Hash(client.pk)
+	var pkb, _ = hex.DecodeString(client.pk)
+	client.id = encryption.Hash(pkb) // as the chain derives it
 
 	balances.(*testBalances).balances[client.id] = balance
 	return
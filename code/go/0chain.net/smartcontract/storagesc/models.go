@@ -24,6 +24,9 @@ var (
 	ALL_VALIDATORS_KEY  = datastore.Key(ADDRESS + encryption.Hash("all_validators"))
 	ALL_ALLOCATIONS_KEY = datastore.Key(ADDRESS + encryption.Hash("all_allocations"))
 	STORAGE_STATS_KEY   = datastore.Key(ADDRESS + encryption.Hash("all_storage"))
+
+	CHALLENGE_GENERATIONS_KEY = datastore.Key(ADDRESS +
+		encryption.Hash("challenge_generations"))
 )
 
 type ClientAllocation struct {
@@ -308,6 +311,9 @@ type StorageNode struct {
 	Used            int64                  `json:"used"`     // allocated capacity
 	LastHealthCheck common.Timestamp       `json:"last_health_check"`
 	PublicKey       string                 `json:"-"`
+	// StorageClass of the blobber, one of configured by the SC; empty for
+	// not classified blobbers.
+	StorageClass string `json:"storage_class,omitempty"`
 	// StakePoolSettings used initially to create and setup stake pool.
 	StakePoolSettings stakePoolSettings `json:"stake_pool_settings"`
 }
@@ -320,6 +326,9 @@ func (sn *StorageNode) validate(conf *scConfig) (err error) {
 	if sn.Capacity <= conf.MinBlobberCapacity {
 		return errors.New("insufficient blobber capacity")
 	}
+	if err = sn.validateStorageClass(conf); err != nil {
+		return
+	}
 
 	if strings.Contains(sn.BaseURL, "localhost") &&
 		node.Self.Host != "localhost" {
@@ -393,6 +402,12 @@ type BlobberAllocation struct {
 	AllocationRoot  string                  `json:"allocation_root"`
 	LastWriteMarker *WriteMarker            `json:"write_marker"`
 	Stats           *StorageAllocationStats `json:"stats"`
+	// WriteMarkersLast is sequence number of the latest committed write
+	// marker and WriteMarkersNum is number of the last committed write
+	// markers kept in the history; the history is stored in pages apart
+	// from the allocation and used to roll it back to a previous root.
+	WriteMarkersLast int64 `json:"write_markers_last,omitempty"`
+	WriteMarkersNum  int64 `json:"write_markers_num,omitempty"`
 	// Terms of the BlobberAllocation represents weighted average terms
 	// for the allocation. The MinLockDemand can be increased only,
 	// to prevent some attacks. If a user extends an allocation then
@@ -564,6 +579,14 @@ type StorageAllocation struct {
 	ReadPriceRange             PriceRange    `json:"read_price_range"`
 	WritePriceRange            PriceRange    `json:"write_price_range"`
 	MaxChallengeCompletionTime time.Duration `json:"max_challenge_completion_time"`
+	// MinReputation is min success ratio of challenges of blobbers
+	// of the allocation; zero means any blobbers.
+	MinReputation float64 `json:"min_reputation,omitempty"`
+	// SortByReputation is true if the allocation prefers blobbers with
+	// better reputation instead of random ones.
+	SortByReputation bool `json:"sort_by_reputation,omitempty"`
+	// StorageClass of blobbers of the allocation; empty means any blobbers.
+	StorageClass string `json:"storage_class,omitempty"`
 
 	//AllocationPools allocationPools `json:"allocation_pools"`
 	WritePoolOwners []string `json:"write_pool_owners"`
@@ -596,6 +619,15 @@ type StorageAllocation struct {
 	TimeUnit time.Duration `json:"time_unit"`
 
 	Curators []string `json:"curators"`
+	// CoOwners share the allocation with its owner with given permissions.
+	CoOwners []*AllocationCoOwner `json:"co_owners,omitempty"`
+	// TransferOffer is pending offer of the owner to transfer the allocation.
+	TransferOffer *AllocationTransferOffer `json:"transfer_offer,omitempty"`
+	// AutoRenewal settings of the allocation, nil means disabled.
+	AutoRenewal *AllocationAutoRenewal `json:"auto_renewal,omitempty"`
+
+	// Repairs is list of last blobbers replaced in the allocation.
+	Repairs []*BlobberRepair `json:"repairs,omitempty"`
 }
 
 // The restMinLockDemand returns number of tokens required as min_lock_demand;
@@ -693,6 +725,12 @@ func (sa *StorageAllocation) validate(now common.Timestamp,
 		return errors.New("missing owner id")
 	}
 
+	if sa.StorageClass != "" {
+		if _, err = conf.storageClass(sa.StorageClass); err != nil {
+			return
+		}
+	}
+
 	return // nil
 }
 
@@ -775,23 +813,6 @@ func (sa *StorageAllocation) diversifyBlobbers(list []*StorageNode, size int) (d
 		return
 	}
 
-	// thanks to @cdipaolo
-	distance := func(geoloc1, geoloc2 StorageNodeGeolocation) float64 {
-		hsin := func(theta float64) float64 {
-			return math.Pow(math.Sin(theta/2), 2)
-		}
-
-		var la1, lo1, la2, lo2 float64
-		la1 = geoloc1.Latitude * math.Pi / 180
-		lo1 = geoloc1.Longitude * math.Pi / 180
-		la2 = geoloc2.Latitude * math.Pi / 180
-		lo2 = geoloc2.Longitude * math.Pi / 180
-
-		h := hsin(la2-la1) + math.Cos(la1)*math.Cos(la2)*hsin(lo2-lo1)
-
-		return math.Asin(math.Sqrt(h))
-	}
-
 	var maxD float64 // distance
 	var maxDIndex int
 
@@ -811,7 +832,7 @@ func (sa *StorageAllocation) diversifyBlobbers(list []*StorageNode, size int) (d
 		// calculate distance for the combination
 		combPairs := combinations(comb, 2)
 		for _, combPair := range combPairs {
-			d += distance(list[combPair[0]].Geolocation, list[combPair[1]].Geolocation)
+			d += geoDistance(list[combPair[0]].Geolocation, list[combPair[1]].Geolocation)
 		}
 
 		// update the max distance value
@@ -828,6 +849,23 @@ func (sa *StorageAllocation) diversifyBlobbers(list []*StorageNode, size int) (d
 	return
 }
 
+// thanks to @cdipaolo
+func geoDistance(geoloc1, geoloc2 StorageNodeGeolocation) float64 {
+	hsin := func(theta float64) float64 {
+		return math.Pow(math.Sin(theta/2), 2)
+	}
+
+	var la1, lo1, la2, lo2 float64
+	la1 = geoloc1.Latitude * math.Pi / 180
+	lo1 = geoloc1.Longitude * math.Pi / 180
+	la2 = geoloc2.Latitude * math.Pi / 180
+	lo2 = geoloc2.Longitude * math.Pi / 180
+
+	h := hsin(la2-la1) + math.Cos(la1)*math.Cos(la2)*hsin(lo2-lo1)
+
+	return math.Asin(math.Sqrt(h))
+}
+
 // Until returns allocation expiration.
 func (sa *StorageAllocation) Until() common.Timestamp {
 	return sa.Expiration + toSeconds(sa.ChallengeCompletionTime)
@@ -938,8 +976,8 @@ func (sa *StorageAllocation) challengePoolChanges(odr, ndr common.Timestamp,
 }
 
 func (sa *StorageAllocation) IsValidFinalizer(id string) bool {
-	if sa.Owner == id {
-		return true // finalizing by owner
+	if sa.hasPermission(id, permManagePools) {
+		return true // finalizing by owner or co-owner
 	}
 	for _, d := range sa.BlobberDetails {
 		if d.BlobberID == id {
@@ -1183,8 +1221,8 @@ func (rm *ReadMarker) VerifySignature(clientPublicKey string) bool {
 func (rm *ReadMarker) verifyAuthTicket(alloc *StorageAllocation,
 	now common.Timestamp) (err error) {
 
-	// owner downloads, pays itself, no ticket needed
-	if rm.PayerID == alloc.Owner {
+	// owner (or co-owner) downloads, pays itself, no ticket needed
+	if alloc.hasPermission(rm.PayerID, permRead) {
 		return
 	}
 	// 3rd party payment
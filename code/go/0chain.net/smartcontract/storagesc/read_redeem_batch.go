@@ -0,0 +1,148 @@
+package storagesc
+
+import (
+	"encoding/json"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+)
+
+// maxReadRedeemBatch is max number of read markers can be redeemed
+// in one transaction
+const maxReadRedeemBatch = 100
+
+// readRedeemBatchRequest is list of read markers of any allocations
+// and clients to redeem at once
+type readRedeemBatchRequest struct {
+	ReadMarkers []*ReadMarker `json:"read_markers"`
+}
+
+func (rrbr *readRedeemBatchRequest) decode(b []byte) error {
+	return json.Unmarshal(b, rrbr)
+}
+
+// readRedeemResult is result of redeeming of a read marker of a batch
+type readRedeemResult struct {
+	// Index of the read marker in the request.
+	Index        int           `json:"index"`
+	AllocationID string        `json:"allocation_id,omitempty"`
+	BlobberID    string        `json:"blobber_id,omitempty"`
+	ClientID     string        `json:"client_id,omitempty"`
+	ReadCounter  int64         `json:"read_counter,omitempty"`
+	Redeemed     bool          `json:"redeemed"`
+	Value        state.Balance `json:"value,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// readRedeemBatchResponse is output of the read_redeem_batch
+type readRedeemBatchResponse struct {
+	Redeemed int                 `json:"redeemed"`
+	Failed   int                 `json:"failed"`
+	Results  []*readRedeemResult `json:"results"`
+}
+
+func (rrbr *readRedeemBatchResponse) encode() string {
+	var b, err = json.Marshal(rrbr)
+	if err != nil {
+		panic(err) // must never happens
+	}
+	return string(b)
+}
+
+// checkReadPool of given prepared read marker; it checks the read pool has
+// enough tokens for the blobber before moving any of them, since
+// a partial move can't be rolled back without failing entire batch
+func (sc *StorageSmartContract) checkReadPool(t *transaction.Transaction,
+	rr *readRedeem, balances cstate.StateContextI) (err error) {
+
+	var rp *readPool
+	if rp, err = sc.getReadPool(rr.userID, balances); err != nil {
+		return common.NewErrorf("commit_blobber_read",
+			"can't get related read pool: %v", err)
+	}
+
+	var (
+		rm      = rr.commitRead.ReadMarker
+		balance = rp.blobberBalance(rm.AllocationID, rm.BlobberID,
+			t.CreationDate)
+	)
+	if balance == 0 {
+		return common.NewErrorf("commit_blobber_read",
+			"no tokens in read pool for allocation: %s, blobber: %s",
+			rm.AllocationID, rm.BlobberID)
+	}
+	if balance < rr.value {
+		return common.NewErrorf("commit_blobber_read",
+			"not enough tokens in read pool for allocation: %s, blobber: %s",
+			rm.AllocationID, rm.BlobberID)
+	}
+	return
+}
+
+// readRedeemBatch redeems many read markers in one transaction; an invalid
+// read marker doesn't fail the transaction, instead its error reported in
+// the output; the transaction fails if no read marker has been redeemed
+func (sc *StorageSmartContract) readRedeemBatch(t *transaction.Transaction,
+	input []byte, balances cstate.StateContextI) (resp string, err error) {
+
+	var req readRedeemBatchRequest
+	if err = req.decode(input); err != nil {
+		return "", common.NewError("read_redeem_batch_failed",
+			"invalid request: "+err.Error())
+	}
+
+	if len(req.ReadMarkers) == 0 {
+		return "", common.NewError("read_redeem_batch_failed",
+			"invalid request: empty list of read markers")
+	}
+
+	if len(req.ReadMarkers) > maxReadRedeemBatch {
+		return "", common.NewErrorf("read_redeem_batch_failed",
+			"invalid request: too many read markers: %d > %d",
+			len(req.ReadMarkers), maxReadRedeemBatch)
+	}
+
+	var out readRedeemBatchResponse
+	for i, rm := range req.ReadMarkers {
+		var res = &readRedeemResult{Index: i}
+		out.Results = append(out.Results, res)
+
+		if rm != nil {
+			res.AllocationID = rm.AllocationID
+			res.BlobberID = rm.BlobberID
+			res.ClientID = rm.ClientID
+			res.ReadCounter = rm.ReadCounter
+		}
+
+		// rejected read markers don't change the state
+		var rr *readRedeem
+		rr, err = sc.prepareReadRedeem(t, &ReadConnection{ReadMarker: rm},
+			balances)
+		if err == nil {
+			err = sc.checkReadPool(t, rr, balances)
+		}
+		if err != nil {
+			res.Error = err.Error()
+			out.Failed++
+			continue
+		}
+
+		// failed redeeming of a verified read marker fails entire batch
+		if _, err = sc.redeemRead(t, rr, balances); err != nil {
+			return "", common.NewErrorf("read_redeem_batch_failed",
+				"redeeming read marker %d: %v", i, err)
+		}
+		res.Redeemed, res.Value = true, rr.value
+		out.Redeemed++
+	}
+
+	if out.Redeemed == 0 {
+		return "", common.NewErrorf("read_redeem_batch_failed",
+			"no read markers redeemed, first error: %s",
+			out.Results[0].Error)
+	}
+
+	return out.encode(), nil
+}
@@ -0,0 +1,151 @@
+package storagesc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageSmartContract_readRedeemBatch(t *testing.T) {
+	var (
+		ssc            = newTestStorageSC()
+		balances       = newTestBalances(t, false)
+		client         = newClient(100*x10, balances)
+		tp, exp  int64 = 0, int64(toSeconds(time.Hour))
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	tp += 100
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+
+	var readMarker = func(blobberID string, counter int64) *ReadMarker {
+		var rm = &ReadMarker{
+			ClientID:        client.id,
+			ClientPublicKey: client.pk,
+			BlobberID:       blobberID,
+			AllocationID:    allocID,
+			OwnerID:         client.id,
+			Timestamp:       common.Timestamp(tp),
+			ReadCounter:     counter,
+			PayerID:         client.id,
+		}
+		rm.Signature, err = client.scheme.Sign(
+			encryption.Hash(rm.GetHashData()))
+		require.NoError(t, err)
+		return rm
+	}
+
+	var (
+		b1 = alloc.BlobberDetails[0].BlobberID
+		b2 = alloc.BlobberDetails[1].BlobberID
+	)
+
+	// no read pool
+	tp += 100
+	var tx = newTransaction(b1, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.readRedeemBatch(tx, mustEncode(t, &readRedeemBatchRequest{
+		ReadMarkers: []*ReadMarker{readMarker(b1, (1*GB)/(64*KB))},
+	}), balances)
+	requireErrMsg(t, err, "read_redeem_batch_failed: no read markers "+
+		"redeemed, first error: commit_blobber_read: can't get related "+
+		"read pool: value not present")
+
+	// empty
+	_, err = ssc.readRedeemBatch(tx, mustEncode(t, &readRedeemBatchRequest{}),
+		balances)
+	requireErrMsg(t, err, "read_redeem_batch_failed: invalid request: "+
+		"empty list of read markers")
+
+	// create and fill read pool
+	tp += 100
+	tx = newTransaction(client.id, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.newReadPool(tx, nil, balances)
+	require.NoError(t, err)
+
+	tp += 100
+	var readPoolFund = int64(len(alloc.BlobberDetails)) * 2 * 1e10
+	tx = newTransaction(client.id, ssc.ID, readPoolFund, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.readPoolLock(tx, mustEncode(t, &lockRequest{
+		Duration:     20 * time.Minute,
+		AllocationID: allocID,
+	}), balances)
+	require.NoError(t, err)
+
+	tp += 100
+	var (
+		invalid = readMarker(b2, 10)
+		req     = readRedeemBatchRequest{
+			ReadMarkers: []*ReadMarker{
+				readMarker(b1, (1*GB)/(64*KB)),
+				readMarker(b2, (1*GB)/(64*KB)),
+				nil,
+				invalid,
+				readMarker(b1, (1*GB)/(64*KB)-1), // outdated
+				readMarker(b2, (5*GB)/(64*KB)),   // not enough tokens
+			},
+		}
+	)
+	invalid.Signature = "invalid"
+
+	tp += 100
+	tx = newTransaction(b1, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	var resp string
+	resp, err = ssc.readRedeemBatch(tx, mustEncode(t, &req), balances)
+	require.NoError(t, err)
+
+	var out readRedeemBatchResponse
+	require.NoError(t, json.Unmarshal([]byte(resp), &out))
+	assert.Equal(t, 2, out.Redeemed)
+	assert.Equal(t, 4, out.Failed)
+	require.Len(t, out.Results, 6)
+	for i, res := range out.Results {
+		assert.Equal(t, i, res.Index)
+		if i < 2 {
+			assert.True(t, res.Redeemed)
+			assert.EqualValues(t, 1e10, res.Value)
+			assert.Zero(t, res.Error)
+			continue
+		}
+		assert.False(t, res.Redeemed)
+		assert.NotZero(t, res.Error)
+	}
+
+	// check out latest read markers and balances
+	for _, id := range []string{b1, b2} {
+		var rc = &ReadConnection{ReadMarker: &ReadMarker{
+			BlobberID: id,
+			ClientID:  client.id,
+		}}
+		var val, err = balances.GetTrieNode(rc.GetKey(ssc.ID))
+		require.NoError(t, err)
+		require.NoError(t, rc.Decode(val.Encode()))
+		assert.EqualValues(t, (1*GB)/(64*KB), rc.ReadMarker.ReadCounter)
+
+		var sp *stakePool
+		sp, err = ssc.getStakePool(id, balances)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1e10,
+			sp.Rewards.Blobber+sp.Rewards.Validator+sp.Rewards.Charge)
+	}
+
+	var rp *readPool
+	rp, err = ssc.getReadPool(client.id, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, readPoolFund-2e10, rp.allocTotal(allocID, tp))
+}
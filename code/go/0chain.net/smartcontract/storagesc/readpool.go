@@ -35,6 +35,19 @@ func (rp *readPool) blobberCut(allocID, blobberID string, now common.Timestamp,
 	return rp.Pools.blobberCut(allocID, blobberID, now)
 }
 
+// blobberBalance is total of tokens of the read pool can be moved to given
+// blobber of given allocation
+func (rp *readPool) blobberBalance(allocID, blobberID string,
+	now common.Timestamp) (value state.Balance) {
+
+	for _, ap := range rp.blobberCut(allocID, blobberID, now) {
+		if bp, ok := ap.Blobbers.get(blobberID); ok {
+			value += bp.Balance
+		}
+	}
+	return
+}
+
 func (rp *readPool) removeEmpty(allocID string, ap []*allocationPool) {
 	rp.Pools.removeEmpty(allocID, ap)
 }
@@ -444,6 +457,16 @@ func (ssc *StorageSmartContract) readPoolLock(t *transaction.Transaction,
 		return "", common.NewError("read_pool_lock_failed", err.Error())
 	}
 
+	err = ssc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+		Type:      allocEventReadPoolLock,
+		BlobberID: lr.BlobberID,
+		PoolID:    ap.ID,
+		Amount:    state.Balance(t.Value),
+	}, balances)
+	if err != nil {
+		return "", common.NewError("read_pool_lock_failed", err.Error())
+	}
+
 	return
 }
 
@@ -483,6 +506,12 @@ func (ssc *StorageSmartContract) readPoolUnlock(t *transaction.Transaction,
 		return "", common.NewError("read_pool_unlock_failed", err.Error())
 	}
 
+	var unlocked = &AllocationEvent{
+		Type:   allocEventReadPoolUnlock,
+		PoolID: ap.ID,
+		Amount: ap.Balance,
+	}
+
 	transfer, resp, err = ap.EmptyPool(ssc.ID, t.ClientID,
 		common.ToTime(t.CreationDate))
 	if err != nil {
@@ -498,6 +527,11 @@ func (ssc *StorageSmartContract) readPoolUnlock(t *transaction.Transaction,
 		return "", common.NewError("read_pool_unlock_failed", err.Error())
 	}
 
+	err = ssc.addAllocationEvent(t, ap.AllocationID, unlocked, balances)
+	if err != nil {
+		return "", common.NewError("read_pool_unlock_failed", err.Error())
+	}
+
 	return
 }
 
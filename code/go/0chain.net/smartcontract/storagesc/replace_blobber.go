@@ -0,0 +1,378 @@
+package storagesc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+)
+
+// replaceBlobberRequest used to replace an unhealthy blobber of an
+// allocation with a new one chosen by SC
+type replaceBlobberRequest struct {
+	AllocationID string `json:"allocation_id"`
+	BlobberID    string `json:"blobber_id"` // blobber to replace
+}
+
+func (rbr *replaceBlobberRequest) decode(b []byte) (err error) {
+	if err = json.Unmarshal(b, rbr); err != nil {
+		return
+	}
+	if rbr.AllocationID == "" {
+		return errors.New("missing allocation_id in request")
+	}
+	if rbr.BlobberID == "" {
+		return errors.New("missing blobber_id in request")
+	}
+	return
+}
+
+// BlobberRepair represents replacement of a blobber in an allocation. The new
+// blobber should restore data of the replaced one using erasure coded shards
+// of other blobbers of the allocation up to the AllocationRoot.
+type BlobberRepair struct {
+	Tx             string           `json:"tx"`
+	OldBlobberID   string           `json:"old_blobber_id"`
+	NewBlobberID   string           `json:"new_blobber_id"`
+	AllocationRoot string           `json:"allocation_root"`
+	UsedSize       int64            `json:"used_size"` // data to restore
+	Timestamp      common.Timestamp `json:"timestamp"`
+}
+
+// maxBlobberRepairs is number of last blobber replacements kept in an
+// allocation
+const maxBlobberRepairs = 10
+
+// addRepair to the bounded history of blobber replacements
+func (sa *StorageAllocation) addRepair(repair *BlobberRepair) {
+	sa.Repairs = append(sa.Repairs, repair)
+	if over := len(sa.Repairs) - maxBlobberRepairs; over > 0 {
+		sa.Repairs = append(sa.Repairs[:0], sa.Repairs[over:]...)
+	}
+}
+
+// isBlobberReplaceable returns true if the blobber doesn't send health checks,
+// doesn't provide its service anymore or has failed challenges of the
+// allocation
+func isBlobberReplaceable(b *StorageNode, d *BlobberAllocation,
+	now common.Timestamp) bool {
+
+	if b.LastHealthCheck <= now-blobberHealthTime || b.Capacity == 0 {
+		return true
+	}
+	return d.Stats != nil && d.Stats.FailedChallenges > 0
+}
+
+// farthestBlobber returns blobber of the list with max total distance to the
+// given blobbers
+func farthestBlobber(list, from []*StorageNode) (far *StorageNode) {
+	var maxD = -1.0
+	for _, b := range list {
+		var d float64
+		for _, f := range from {
+			d += geoDistance(b.Geolocation, f.Geolocation)
+		}
+		if d > maxD {
+			far, maxD = b, d
+		}
+	}
+	return
+}
+
+// selectReplacement chooses a blobber to replace one of the allocation
+// blobbers; the kept is list of blobbers remaining in the allocation
+func (sc *StorageSmartContract) selectReplacement(alloc *StorageAllocation,
+	all *StorageNodes, kept []*StorageNode, bSize int64, now common.Timestamp,
+	seed int64, conf *scConfig, balances chainstate.StateContextI) (
+	*StorageNode, error) {
+
+	var list = make([]*StorageNode, 0, len(all.Nodes))
+	for _, b := range all.Nodes {
+		if _, ok := alloc.BlobberMap[b.ID]; ok {
+			continue // already used by the allocation
+		}
+		list = append(list, b)
+	}
+
+	list = alloc.filterBlobbers(list, now, bSize, filterHealthyBlobbers(now),
+		sc.filterBlobbersByFreeSpace(now, bSize, balances),
+		sc.filterBlobbersByReputation(alloc.MinReputation, balances),
+		filterBlobbersByStorageClass(alloc.StorageClass, conf))
+
+	if len(list) == 0 {
+		return nil, errors.New("no blobbers to replace with")
+	}
+
+	if alloc.DiverseBlobbers {
+		return farthestBlobber(list, kept), nil
+	}
+
+	if alloc.SortByReputation {
+		if err := sc.sortBlobbersByReputation(list, balances); err != nil {
+			return nil, err
+		}
+		return list[0], nil
+	}
+
+	return randomizeNodes(list, nil, 1, seed)[0], nil
+}
+
+// moveBlobberShares moves write pool and challenge pool tokens of the replaced
+// blobber to the new one; tokens of the challenge pool the replaced blobber
+// doesn't earn yet moved back to write pool, since the new blobber will
+// receive them on its commits restoring the data
+func (sc *StorageSmartContract) moveBlobberShares(alloc *StorageAllocation,
+	from, to *BlobberAllocation, balances chainstate.StateContextI) (err error) {
+
+	var wps *allocationWritePools
+	if wps, err = alloc.getAllocationPools(sc, balances); err != nil {
+		return fmt.Errorf("can't get write pools: %v", err)
+	}
+
+	for _, ap := range wps.allocationPools {
+		var bp, ok = ap.Blobbers.get(from.BlobberID)
+		if !ok {
+			continue // no tokens for the blobber
+		}
+		ap.Blobbers.remove(from.BlobberID)
+		ap.Blobbers.add(&blobberPool{
+			BlobberID: to.BlobberID,
+			Balance:   bp.Balance,
+		})
+	}
+
+	if from.ChallengePoolIntegralValue > 0 {
+		var cp *challengePool
+		if cp, err = sc.getChallengePool(alloc.ID, balances); err != nil {
+			return fmt.Errorf("can't get challenge pool: %v", err)
+		}
+		var wp *writePool
+		if wp, err = wps.getOwnerWP(); err != nil {
+			return fmt.Errorf("can't get owner's write pool: %v", err)
+		}
+		var move = minBalance(from.ChallengePoolIntegralValue, cp.Balance)
+		err = cp.moveToWritePool(alloc, to.BlobberID, alloc.Until(), wp, move)
+		if err != nil {
+			return fmt.Errorf("can't move tokens to write pool: %v", err)
+		}
+		from.ChallengePoolIntegralValue -= move
+		from.Returned += move
+		alloc.MovedBack += move
+		if err = cp.save(sc.ID, alloc.ID, balances); err != nil {
+			return fmt.Errorf("can't save challenge pool: %v", err)
+		}
+	}
+
+	if err = wps.saveWritePools(sc.ID, balances); err != nil {
+		return fmt.Errorf("can't save write pools: %v", err)
+	}
+
+	return
+}
+
+// moveBlobberOffer moves stake pool offer of the allocation from the replaced
+// blobber to the new one
+func (sc *StorageSmartContract) moveBlobberOffer(alloc *StorageAllocation,
+	from, to *BlobberAllocation, balances chainstate.StateContextI) (err error) {
+
+	var fsp, tsp *stakePool
+	if fsp, err = sc.getStakePool(from.BlobberID, balances); err != nil {
+		return fmt.Errorf("can't get stake pool of %s: %v", from.BlobberID,
+			err)
+	}
+	if tsp, err = sc.getStakePool(to.BlobberID, balances); err != nil {
+		return fmt.Errorf("can't get stake pool of %s: %v", to.BlobberID, err)
+	}
+	if err = fsp.removeOffer(alloc.ID); err != nil {
+		return fmt.Errorf("can't remove stake pool offer of %s: %v",
+			from.BlobberID, err)
+	}
+	tsp.addOffer(alloc, to)
+	if err = fsp.save(sc.ID, from.BlobberID, balances); err != nil {
+		return fmt.Errorf("can't save stake pool of %s: %v", from.BlobberID,
+			err)
+	}
+	if err = tsp.save(sc.ID, to.BlobberID, balances); err != nil {
+		return fmt.Errorf("can't save stake pool of %s: %v", to.BlobberID, err)
+	}
+	return
+}
+
+// replaceBlobber swaps an unhealthy blobber of an allocation for a new one;
+// the replaced blobber keeps all tokens it already earned, but loses its
+// offer, write pool tokens and challenge pool tokens not earned yet; all
+// these moved to the new blobber
+func (sc *StorageSmartContract) replaceBlobber(t *transaction.Transaction,
+	input []byte, balances chainstate.StateContextI) (resp string, err error) {
+
+	var req replaceBlobberRequest
+	if err = req.decode(input); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(req.AllocationID, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	if alloc.Owner != t.ClientID && !alloc.isCurator(t.ClientID) {
+		return "", common.NewError("replace_blobber_failed",
+			"only owner or curator can replace a blobber of an allocation")
+	}
+
+	if alloc.Finalized {
+		return "", common.NewError("replace_blobber_failed",
+			"allocation is finalized")
+	}
+
+	if alloc.Expiration < t.CreationDate {
+		return "", common.NewError("replace_blobber_failed",
+			"can't replace a blobber of expired allocation")
+	}
+
+	var (
+		di   = -1 // index of the blobber in allocation details
+		kept = make([]*StorageNode, 0, len(alloc.Blobbers))
+	)
+	for i, d := range alloc.BlobberDetails {
+		if d.BlobberID == req.BlobberID {
+			di = i
+		}
+	}
+	if di < 0 {
+		return "", common.NewError("replace_blobber_failed",
+			"blobber doesn't belong to the allocation: "+req.BlobberID)
+	}
+	for _, b := range alloc.Blobbers {
+		if b.ID != req.BlobberID {
+			kept = append(kept, b)
+		}
+	}
+
+	var (
+		from    = alloc.BlobberDetails[di]
+		blobber *StorageNode
+	)
+	if blobber, err = sc.getBlobber(from.BlobberID, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get blobber: "+err.Error())
+	}
+
+	if !isBlobberReplaceable(blobber, from, t.CreationDate) {
+		return "", common.NewError("replace_blobber_failed",
+			"blobber is healthy and has no failed challenges")
+	}
+
+	var all *StorageNodes
+	if all, err = sc.getBlobbersList(balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get all blobbers list: "+err.Error())
+	}
+
+	var seed int64
+	if seed, err = strconv.ParseInt(t.Hash[0:8], 16, 64); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"failed to create seed for randomizeNodes")
+	}
+
+	var conf *scConfig
+	if conf, err = sc.getConfig(balances, true); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get SC configurations: "+err.Error())
+	}
+
+	var nb *StorageNode
+	nb, err = sc.selectReplacement(alloc, all, kept, from.Size,
+		t.CreationDate, seed, conf, balances)
+	if err != nil {
+		return "", common.NewError("replace_blobber_failed", err.Error())
+	}
+
+	var to = &BlobberAllocation{
+		BlobberID:    nb.ID,
+		AllocationID: alloc.ID,
+		Size:         from.Size,
+		Stats:        &StorageAllocationStats{},
+		Terms:        nb.Terms,
+	}
+	to.MinLockDemand = nb.Terms.minLockDemand(sizeInGB(to.Size),
+		alloc.restDurationInTimeUnits(t.CreationDate))
+
+	// the new blobber restores the data committing write markers and
+	// increasing used size of the allocation again
+	var repair = &BlobberRepair{
+		Tx:             t.Hash,
+		OldBlobberID:   from.BlobberID,
+		NewBlobberID:   nb.ID,
+		AllocationRoot: from.AllocationRoot,
+		Timestamp:      t.CreationDate,
+	}
+	if from.Stats != nil {
+		repair.UsedSize = from.Stats.UsedSize
+		alloc.UsedSize -= from.Stats.UsedSize
+		if alloc.Stats != nil {
+			alloc.Stats.UsedSize -= from.Stats.UsedSize
+		}
+	}
+
+	if err = sc.moveBlobberShares(alloc, from, to, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed", err.Error())
+	}
+
+	alloc.BlobberDetails[di] = to
+	delete(alloc.BlobberMap, from.BlobberID)
+	alloc.BlobberMap[to.BlobberID] = to
+	alloc.Blobbers = append(kept, nb)
+	sort.SliceStable(alloc.Blobbers, func(i, j int) bool {
+		return alloc.Blobbers[i].ID < alloc.Blobbers[j].ID
+	})
+	alloc.addRepair(repair)
+	alloc.Tx = t.Hash
+
+	// the new blobber can have greater challenge completion time; since it
+	// changes the allocation 'until', then offers of all blobbers should
+	// be updated
+	if nb.Terms.ChallengeCompletionTime > alloc.ChallengeCompletionTime {
+		alloc.ChallengeCompletionTime = nb.Terms.ChallengeCompletionTime
+		for _, d := range alloc.BlobberDetails {
+			if d == to {
+				continue // will be added below
+			}
+			if err = sc.updateSakePoolOffer(d, alloc, balances); err != nil {
+				return "", common.NewError("replace_blobber_failed",
+					err.Error())
+			}
+		}
+	}
+
+	if err = sc.moveBlobberOffer(alloc, from, to, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed", err.Error())
+	}
+
+	blobber.Used -= from.Size
+	nb.Used += to.Size
+	err = sc.saveUpdatedAllocation(all, alloc, []*StorageNode{blobber, nb},
+		balances)
+	if err != nil {
+		return "", common.NewErrorf("replace_blobber_failed",
+			"saving allocation: %v", err)
+	}
+
+	err = sc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+		Type:          allocEventBlobberAdded,
+		BlobberID:     to.BlobberID,
+		PrevBlobberID: from.BlobberID,
+	}, balances)
+	if err != nil {
+		return "", common.NewError("replace_blobber_failed", err.Error())
+	}
+
+	return string(alloc.Encode()), nil
+}
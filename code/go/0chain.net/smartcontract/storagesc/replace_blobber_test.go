@@ -0,0 +1,159 @@
+package storagesc
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	chainState "0chain.net/chaincore/chain/state"
+	"0chain.net/core/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (rbr *replaceBlobberRequest) callReplaceBlobber(t testing.TB,
+	clientID string, now int64, ssc *StorageSmartContract,
+	balances chainState.StateContextI) (resp string, err error) {
+
+	var (
+		input = mustEncode(t, rbr)
+		tx    = newTransaction(clientID, ADDRESS, 0, now)
+	)
+	balances.(*testBalances).setTransaction(t, tx)
+	return ssc.replaceBlobber(tx, input, balances)
+}
+
+func Test_isBlobberReplaceable(t *testing.T) {
+	const now = common.Timestamp(blobberHealthTime * 2)
+	var (
+		b = &StorageNode{Capacity: 10 * GB, LastHealthCheck: now}
+		d = &BlobberAllocation{Stats: &StorageAllocationStats{}}
+	)
+	assert.False(t, isBlobberReplaceable(b, d, now))
+	d.Stats.FailedChallenges = 1
+	assert.True(t, isBlobberReplaceable(b, d, now))
+	d.Stats.FailedChallenges = 0
+	b.LastHealthCheck = now - blobberHealthTime
+	assert.True(t, isBlobberReplaceable(b, d, now))
+	b.LastHealthCheck, b.Capacity = now, 0
+	assert.True(t, isBlobberReplaceable(b, d, now))
+}
+
+func TestStorageAllocation_addRepair(t *testing.T) {
+	var sa StorageAllocation
+	for i := 0; i < maxBlobberRepairs+5; i++ {
+		sa.addRepair(&BlobberRepair{Tx: strconv.Itoa(i)})
+	}
+	require.Len(t, sa.Repairs, maxBlobberRepairs)
+	assert.Equal(t, "5", sa.Repairs[0].Tx)
+	assert.Equal(t, strconv.Itoa(maxBlobberRepairs+4),
+		sa.Repairs[maxBlobberRepairs-1].Tx)
+}
+
+func TestStorageSmartContract_replaceBlobber(t *testing.T) {
+	const tp, exp = 100, int64(100 + time.Hour/time.Second)
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		req      replaceBlobberRequest
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+
+	var (
+		victim = alloc.BlobberDetails[0].BlobberID
+		used   = make(map[string]bool)
+	)
+	for _, d := range alloc.BlobberDetails {
+		used[d.BlobberID] = true
+	}
+
+	req.AllocationID, req.BlobberID = allocID, victim
+
+	// not an owner
+	var stranger = newClient(0, balances)
+	_, err = req.callReplaceBlobber(t, stranger.id, tp+10, ssc, balances)
+	requireErrMsg(t, err, "replace_blobber_failed: only owner or curator "+
+		"can replace a blobber of an allocation")
+
+	// healthy blobber
+	_, err = req.callReplaceBlobber(t, client.id, tp+10, ssc, balances)
+	requireErrMsg(t, err, "replace_blobber_failed: blobber is healthy and "+
+		"has no failed challenges")
+
+	// failed challenges
+	alloc.BlobberDetails[0].Stats.FailedChallenges = 1
+	mustSave(t, alloc.GetKey(ssc.ID), alloc, balances)
+
+	var wp *writePool
+	wp, err = ssc.getWritePool(client.id, balances)
+	require.NoError(t, err)
+	var ap, ok = wp.Pools.get(allocID)
+	require.True(t, ok)
+	var vbp *blobberPool
+	vbp, ok = ap.Blobbers.get(victim)
+	require.True(t, ok)
+	var share = vbp.Balance
+
+	var resp string
+	resp, err = req.callReplaceBlobber(t, client.id, tp+10, ssc, balances)
+	require.NoError(t, err)
+
+	var deco StorageAllocation
+	require.NoError(t, deco.Decode([]byte(resp)))
+
+	var replaced = deco.BlobberDetails[0].BlobberID
+	assert.NotEqual(t, victim, replaced)
+	assert.False(t, used[replaced])
+	assert.Len(t, deco.Blobbers, len(alloc.Blobbers))
+	for _, b := range deco.Blobbers {
+		assert.NotEqual(t, victim, b.ID)
+	}
+	require.Len(t, deco.Repairs, 1)
+	assert.Equal(t, victim, deco.Repairs[0].OldBlobberID)
+	assert.Equal(t, replaced, deco.Repairs[0].NewBlobberID)
+
+	// stake pool offers
+	var sp *stakePool
+	sp, err = ssc.getStakePool(victim, balances)
+	require.NoError(t, err)
+	assert.Nil(t, sp.findOffer(allocID))
+	sp, err = ssc.getStakePool(replaced, balances)
+	require.NoError(t, err)
+	assert.NotNil(t, sp.findOffer(allocID))
+
+	// capacity used
+	var b *StorageNode
+	b, err = ssc.getBlobber(victim, balances)
+	require.NoError(t, err)
+	assert.Zero(t, b.Used)
+	b, err = ssc.getBlobber(replaced, balances)
+	require.NoError(t, err)
+	assert.Equal(t, deco.BlobberDetails[0].Size, b.Used)
+
+	// write pool share
+	wp, err = ssc.getWritePool(client.id, balances)
+	require.NoError(t, err)
+	ap, ok = wp.Pools.get(allocID)
+	require.True(t, ok)
+	_, ok = ap.Blobbers.get(victim)
+	assert.False(t, ok)
+	vbp, ok = ap.Blobbers.get(replaced)
+	require.True(t, ok)
+	assert.Equal(t, share, vbp.Balance)
+
+	// the replaced blobber doesn't belong to the allocation anymore
+	_, err = req.callReplaceBlobber(t, client.id, tp+20, ssc, balances)
+	requireErrMsg(t, err, "replace_blobber_failed: blobber doesn't belong "+
+		"to the allocation: "+victim)
+}
@@ -0,0 +1,216 @@
+package storagesc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+	"0chain.net/smartcontract"
+)
+
+// reputationWindow is number of last challenges the rolling success ratio
+// and the rolling latency of a blobber based on
+const reputationWindow = 100
+
+func blobberReputationKey(scKey, blobberID string) datastore.Key {
+	return datastore.Key(scKey + ":blobberreputation:" + blobberID)
+}
+
+// blobberReputation is aggregated challenges history of a blobber
+type blobberReputation struct {
+	BlobberID  string `json:"blobber_id"`
+	Challenges int64  `json:"challenges"` // total challenges resolved
+	Passed     int64  `json:"passed"`
+	Failed     int64  `json:"failed"`
+	// SuccessRatio is rolling ratio of passed challenges over last
+	// reputationWindow challenges, in [0; 1] range.
+	SuccessRatio float64 `json:"success_ratio"`
+	// AvgLatency is rolling average time between challenge creation and
+	// the challenge response over last reputationWindow challenges.
+	AvgLatency time.Duration `json:"avg_latency"`
+	// PenaltySlashed is total number of tokens slashed from the blobber's
+	// stake pool for failed challenges.
+	PenaltySlashed state.Balance `json:"penalty_slashed"`
+	// LastChallenge is time of last challenge response.
+	LastChallenge common.Timestamp `json:"last_challenge"`
+}
+
+func newBlobberReputation(blobberID string) (br *blobberReputation) {
+	br = new(blobberReputation)
+	br.BlobberID = blobberID
+	return
+}
+
+// Encode to []byte
+func (br *blobberReputation) Encode() (b []byte) {
+	var err error
+	if b, err = json.Marshal(br); err != nil {
+		panic(err) // must never happens
+	}
+	return
+}
+
+// Decode from []byte
+func (br *blobberReputation) Decode(input []byte) error {
+	return json.Unmarshal(input, br)
+}
+
+// update the reputation by resolved challenge
+func (br *blobberReputation) update(pass bool, latency time.Duration,
+	penalty state.Balance, now common.Timestamp) {
+
+	br.Challenges++
+	var result float64
+	if pass {
+		br.Passed++
+		result = 1.0
+	} else {
+		br.Failed++
+	}
+
+	var n = br.Challenges
+	if n > reputationWindow {
+		n = reputationWindow
+	}
+	br.SuccessRatio += (result - br.SuccessRatio) / float64(n)
+	br.AvgLatency += (latency - br.AvgLatency) / time.Duration(n)
+	br.PenaltySlashed += penalty
+	br.LastChallenge = now
+}
+
+// better reputation, used to sort blobbers
+func (br *blobberReputation) better(other *blobberReputation) bool {
+	if br.SuccessRatio != other.SuccessRatio {
+		return br.SuccessRatio > other.SuccessRatio
+	}
+	return br.AvgLatency < other.AvgLatency
+}
+
+func (br *blobberReputation) save(sscKey string,
+	balances chainstate.StateContextI) (err error) {
+
+	_, err = balances.InsertTrieNode(blobberReputationKey(sscKey,
+		br.BlobberID), br)
+	return
+}
+
+// getBlobberReputation of given blobber
+func (sc *StorageSmartContract) getBlobberReputation(blobberID string,
+	balances chainstate.StateContextI) (br *blobberReputation, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(blobberReputationKey(sc.ID, blobberID))
+	if err != nil {
+		return
+	}
+	br = newBlobberReputation(blobberID)
+	if err = br.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return
+}
+
+// getOrCreateBlobberReputation returns empty reputation for a blobber
+// without challenges history
+func (sc *StorageSmartContract) getOrCreateBlobberReputation(blobberID string,
+	balances chainstate.StateContextI) (br *blobberReputation, err error) {
+
+	br, err = sc.getBlobberReputation(blobberID, balances)
+	if err == util.ErrValueNotPresent {
+		return newBlobberReputation(blobberID), nil
+	}
+	return
+}
+
+// updateBlobberReputation by resolved challenge
+func (sc *StorageSmartContract) updateBlobberReputation(blobberID string,
+	pass bool, latency time.Duration, penalty state.Balance,
+	now common.Timestamp, balances chainstate.StateContextI) (err error) {
+
+	var br *blobberReputation
+	if br, err = sc.getOrCreateBlobberReputation(blobberID, balances); err != nil {
+		return fmt.Errorf("can't get blobber reputation: %v", err)
+	}
+	br.update(pass, latency, penalty, now)
+	if err = br.save(sc.ID, balances); err != nil {
+		return fmt.Errorf("can't save blobber reputation: %v", err)
+	}
+	return
+}
+
+// filterBlobbersByReputation kicks off blobbers with success ratio less
+// than given one; blobbers without challenges history kicked off too
+func (sc *StorageSmartContract) filterBlobbersByReputation(
+	minReputation float64, balances chainstate.StateContextI) (
+	filter filterBlobberFunc) {
+
+	return filterBlobberFunc(func(b *StorageNode) (kick bool) {
+		if minReputation <= 0 {
+			return false // filter disabled
+		}
+		var br, err = sc.getBlobberReputation(b.ID, balances)
+		if err != nil {
+			return true // kick off
+		}
+		return br.SuccessRatio < minReputation
+	})
+}
+
+// sortBlobbersByReputation sorts given list from best to worst reputation;
+// blobbers without challenges history are the worst
+func (sc *StorageSmartContract) sortBlobbersByReputation(list []*StorageNode,
+	balances chainstate.StateContextI) (err error) {
+
+	var reps = make(map[string]*blobberReputation, len(list))
+	for _, b := range list {
+		var br *blobberReputation
+		br, err = sc.getOrCreateBlobberReputation(b.ID, balances)
+		if err != nil {
+			return fmt.Errorf("can't get blobber %s reputation: %v", b.ID,
+				err)
+		}
+		reps[b.ID] = br
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return reps[list[i].ID].better(reps[list[j].ID])
+	})
+	return
+}
+
+//
+// stat
+//
+
+// getBlobberReputationHandler returns reputation of given blobber
+func (sc *StorageSmartContract) getBlobberReputationHandler(
+	ctx context.Context, params url.Values,
+	balances chainstate.StateContextI) (resp interface{}, err error) {
+
+	var blobberID = params.Get("blobber_id")
+	if blobberID == "" {
+		return nil, common.NewErrBadRequest("missing 'blobber_id' URL query parameter")
+	}
+
+	if _, err = sc.getBlobber(blobberID, balances); err != nil {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true,
+			cantGetBlobberMsg)
+	}
+
+	var br *blobberReputation
+	br, err = sc.getOrCreateBlobberReputation(blobberID, balances)
+	if err != nil {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true,
+			"can't get blobber reputation")
+	}
+
+	return br, nil
+}
@@ -0,0 +1,86 @@
+package storagesc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_blobberReputation_update(t *testing.T) {
+	var br = newBlobberReputation("blobber")
+
+	br.update(true, 10*time.Second, 0, 10)
+	assert.EqualValues(t, 1, br.Challenges)
+	assert.EqualValues(t, 1, br.Passed)
+	assert.Equal(t, 1.0, br.SuccessRatio)
+	assert.Equal(t, 10*time.Second, br.AvgLatency)
+
+	br.update(false, 30*time.Second, 5, 20)
+	assert.EqualValues(t, 2, br.Challenges)
+	assert.EqualValues(t, 1, br.Failed)
+	assert.Equal(t, 0.5, br.SuccessRatio)
+	assert.Equal(t, 20*time.Second, br.AvgLatency)
+	assert.EqualValues(t, 5, br.PenaltySlashed)
+	assert.EqualValues(t, 20, br.LastChallenge)
+
+	// rolling window: old results have less weight
+	for i := 0; i < 10*reputationWindow; i++ {
+		br.update(true, time.Second, 0, 30)
+	}
+	assert.True(t, br.SuccessRatio > 0.99)
+	assert.True(t, br.AvgLatency < 2*time.Second)
+	br.update(false, time.Second, 0, 40)
+	assert.InDelta(t, 0.99, br.SuccessRatio, 0.001)
+}
+
+func TestStorageSmartContract_blobberReputation(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		list     = []*StorageNode{{ID: "b1"}, {ID: "b2"}, {ID: "b3"}}
+		err      error
+	)
+
+	require.NoError(t, ssc.updateBlobberReputation("b1", false, time.Second,
+		10, 10, balances))
+	require.NoError(t, ssc.updateBlobberReputation("b2", true, time.Second,
+		0, 10, balances))
+
+	var br *blobberReputation
+	br, err = ssc.getBlobberReputation("b1", balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, br.Failed)
+	assert.EqualValues(t, 10, br.PenaltySlashed)
+
+	// filter
+	var filter = ssc.filterBlobbersByReputation(0.5, balances)
+	assert.True(t, filter(list[0]))
+	assert.False(t, filter(list[1]))
+	assert.True(t, filter(list[2])) // no history
+	filter = ssc.filterBlobbersByReputation(0, balances)
+	assert.False(t, filter(list[2]))
+
+	// sort
+	require.NoError(t, ssc.sortBlobbersByReputation(list, balances))
+	assert.Equal(t, "b2", list[0].ID)
+
+	// handler
+	var params = make(url.Values)
+	_, err = ssc.getBlobberReputationHandler(context.Background(), params,
+		balances)
+	requireErrMsg(t, err, "invalid_request: missing 'blobber_id' URL "+
+		"query parameter")
+
+	var b2 = &StorageNode{ID: "b2"}
+	mustSave(t, b2.GetKey(ssc.ID), b2, balances)
+	params.Set("blobber_id", "b2")
+	var resp interface{}
+	resp, err = ssc.getBlobberReputationHandler(context.Background(), params,
+		balances)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, resp.(*blobberReputation).SuccessRatio)
+}
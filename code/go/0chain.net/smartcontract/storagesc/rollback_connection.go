@@ -0,0 +1,314 @@
+package storagesc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+)
+
+const (
+	// maxWriteMarkersHistory is number of last committed write markers kept
+	// for a blobber of an allocation; an allocation can't be rolled back
+	// further than the history
+	maxWriteMarkersHistory = 10
+	// writeMarkersPageSize is number of write markers of the history stored
+	// in one MPT node
+	writeMarkersPageSize = 5
+)
+
+func writeMarkersPageKey(sscKey, allocID, blobberID string,
+	page int64) datastore.Key {
+
+	return datastore.Key(sscKey + ":writemarkers:" + allocID + ":" +
+		blobberID + ":" + strconv.FormatInt(page, 10))
+}
+
+// writeMarkerPage returns page and index in the page of write marker with
+// given sequence number, starting from 1
+func writeMarkerPage(seq int64) (page int64, i int) {
+	return (seq - 1) / writeMarkersPageSize,
+		int((seq - 1) % writeMarkersPageSize)
+}
+
+// writeMarkersPage is page of write markers history of a blobber of an
+// allocation, the history is stored apart from the allocation
+type writeMarkersPage struct {
+	WriteMarkers []*WriteMarker `json:"write_markers"`
+}
+
+// Encode to []byte
+func (wmp *writeMarkersPage) Encode() (b []byte) {
+	var err error
+	if b, err = json.Marshal(wmp); err != nil {
+		panic(err) // must never happens
+	}
+	return
+}
+
+// Decode from []byte
+func (wmp *writeMarkersPage) Decode(input []byte) error {
+	return json.Unmarshal(input, wmp)
+}
+
+func (d *BlobberAllocation) getWriteMarkersPage(sscKey string, page int64,
+	balances cstate.StateContextI) (wmp *writeMarkersPage, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(writeMarkersPageKey(sscKey,
+		d.AllocationID, d.BlobberID, page))
+	if err != nil {
+		return
+	}
+	wmp = new(writeMarkersPage)
+	if err = wmp.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return
+}
+
+// addWriteMarker to the bounded history of committed write markers
+func (d *BlobberAllocation) addWriteMarker(sscKey string, wm *WriteMarker,
+	balances cstate.StateContextI) (err error) {
+
+	d.WriteMarkersLast++
+	var (
+		page, i = writeMarkerPage(d.WriteMarkersLast)
+		wmp     = new(writeMarkersPage)
+	)
+	if i > 0 {
+		if wmp, err = d.getWriteMarkersPage(sscKey, page, balances); err != nil {
+			return fmt.Errorf("can't get write markers history: %v", err)
+		}
+		if len(wmp.WriteMarkers) < i {
+			return errors.New("corrupted write markers history")
+		}
+	}
+	// markers after the index, if any, are reverted ones
+	wmp.WriteMarkers = append(wmp.WriteMarkers[:i], wm)
+	_, err = balances.InsertTrieNode(writeMarkersPageKey(sscKey,
+		d.AllocationID, d.BlobberID, page), wmp)
+	if err != nil {
+		return fmt.Errorf("can't save write markers history: %v", err)
+	}
+
+	if d.WriteMarkersNum < maxWriteMarkersHistory {
+		d.WriteMarkersNum++
+		return
+	}
+	// remove page of the oldest marker if it was the last marker of the page
+	var oldest = d.WriteMarkersLast - maxWriteMarkersHistory
+	if page, i = writeMarkerPage(oldest); i == writeMarkersPageSize-1 {
+		_, err = balances.DeleteTrieNode(writeMarkersPageKey(sscKey,
+			d.AllocationID, d.BlobberID, page))
+		if err != nil && err != util.ErrValueNotPresent {
+			return fmt.Errorf("can't remove write markers history: %v", err)
+		}
+	}
+	return nil
+}
+
+// writeMarkers returns history of last committed write markers, the latest
+// is the last
+func (d *BlobberAllocation) writeMarkers(sscKey string,
+	balances cstate.StateContextI) (wms []*WriteMarker, err error) {
+
+	var pages = make(map[int64]*writeMarkersPage)
+	wms = make([]*WriteMarker, 0, d.WriteMarkersNum)
+	for seq := d.WriteMarkersLast - d.WriteMarkersNum + 1; seq <= d.WriteMarkersLast; seq++ {
+		var (
+			page, i = writeMarkerPage(seq)
+			wmp, ok = pages[page]
+		)
+		if !ok {
+			if wmp, err = d.getWriteMarkersPage(sscKey, page, balances); err != nil {
+				return nil, fmt.Errorf("can't get write markers history: %v", err)
+			}
+			pages[page] = wmp
+		}
+		if i >= len(wmp.WriteMarkers) {
+			return nil, errors.New("corrupted write markers history")
+		}
+		wms = append(wms, wmp.WriteMarkers[i])
+	}
+	return
+}
+
+// revertWriteMarkers removes given number of the latest write markers from
+// the history and pages containing only removed markers
+func (d *BlobberAllocation) revertWriteMarkers(sscKey string, n int64,
+	balances cstate.StateContextI) (err error) {
+
+	var last = d.WriteMarkersLast
+	d.WriteMarkersLast -= n
+	d.WriteMarkersNum -= n
+
+	var from, i = writeMarkerPage(d.WriteMarkersLast + 1)
+	if i > 0 {
+		from++ // the page keeps not reverted markers
+	}
+	var to, _ = writeMarkerPage(last)
+	for page := from; page <= to; page++ {
+		_, err = balances.DeleteTrieNode(writeMarkersPageKey(sscKey,
+			d.AllocationID, d.BlobberID, page))
+		if err != nil && err != util.ErrValueNotPresent {
+			return fmt.Errorf("can't remove write markers history: %v", err)
+		}
+	}
+	return nil
+}
+
+// rollbackTo returns index of write marker of the history with given
+// allocation root; markers after the index are subject to be reverted;
+// index -1 means revert all markers of the history, that's possible only
+// if the history starts from the first write marker of the blobber
+func (d *BlobberAllocation) rollbackTo(wms []*WriteMarker, root string) (
+	i int, err error) {
+
+	if root == d.AllocationRoot {
+		return 0, errors.New("the allocation root is already latest")
+	}
+	for i = len(wms) - 1; i >= 0; i-- {
+		if wms[i].AllocationRoot == root {
+			return
+		}
+	}
+	if root == "" && len(wms) > 0 && wms[0].PreviousAllocationRoot == "" {
+		return -1, nil
+	}
+	return 0, errors.New("allocation root not found in write markers history")
+}
+
+// rollbackConnectionRequest is request of the allocation owner to roll data
+// of a blobber of the allocation back to a previous allocation root
+type rollbackConnectionRequest struct {
+	AllocationID   string `json:"allocation_id"`
+	BlobberID      string `json:"blobber_id"`
+	AllocationRoot string `json:"allocation_root"`
+}
+
+func (rcr *rollbackConnectionRequest) decode(b []byte) error {
+	return json.Unmarshal(b, rcr)
+}
+
+// rollbackBlobberConnection reverts last write markers committed by a blobber
+// for an allocation; it's used by allocation owner when a blobber commits
+// bad data; used size and stats reverted, tokens of the reverted writes not
+// used by challenges yet go back to write pool (or moved to challenge pool
+// for reverted deletions)
+func (sc *StorageSmartContract) rollbackBlobberConnection(
+	t *transaction.Transaction, input []byte,
+	balances cstate.StateContextI) (resp string, err error) {
+
+	var req rollbackConnectionRequest
+	if err = req.decode(input); err != nil {
+		return "", common.NewError("rollback_connection_failed",
+			"malformed input: "+err.Error())
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(req.AllocationID, balances); err != nil {
+		return "", common.NewError("rollback_connection_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	if alloc.Owner != t.ClientID {
+		return "", common.NewError("rollback_connection_failed",
+			"only owner of the allocation can roll it back")
+	}
+
+	if alloc.Finalized {
+		return "", common.NewError("rollback_connection_failed",
+			"allocation is finalized")
+	}
+
+	if alloc.Expiration < t.CreationDate {
+		return "", common.NewError("rollback_connection_failed",
+			"allocation expired")
+	}
+
+	var details, ok = alloc.BlobberMap[req.BlobberID]
+	if !ok {
+		return "", common.NewError("rollback_connection_failed",
+			"blobber is not part of the allocation")
+	}
+
+	var wms []*WriteMarker
+	if wms, err = details.writeMarkers(sc.ID, balances); err != nil {
+		return "", common.NewError("rollback_connection_failed", err.Error())
+	}
+
+	var i int
+	if i, err = details.rollbackTo(wms, req.AllocationRoot); err != nil {
+		return "", common.NewError("rollback_connection_failed", err.Error())
+	}
+
+	var (
+		reverted = wms[i+1:]
+		size     int64
+	)
+	for _, wm := range reverted {
+		size += wm.Size
+	}
+
+	if details.Stats.UsedSize-size < 0 ||
+		details.Stats.UsedSize-size > details.Size {
+
+		return "", common.NewError("rollback_connection_failed",
+			"invalid used size after the rollback")
+	}
+
+	details.Stats.UsedSize -= size
+	details.Stats.NumWrites -= int64(len(reverted))
+	alloc.Stats.UsedSize -= size
+	alloc.Stats.NumWrites -= int64(len(reverted))
+
+	details.AllocationRoot = req.AllocationRoot
+	err = details.revertWriteMarkers(sc.ID, int64(len(reverted)), balances)
+	if err != nil {
+		return "", common.NewError("rollback_connection_failed", err.Error())
+	}
+	if i >= 0 {
+		details.LastWriteMarker = wms[i]
+	} else {
+		details.LastWriteMarker = nil
+	}
+
+	// reverted upload is deletion, and vice versa
+	err = sc.commitMoveTokens(alloc, -size, details, t.CreationDate,
+		t.CreationDate, balances)
+	if err != nil {
+		return "", common.NewErrorf("rollback_connection_failed",
+			"moving tokens: %v", err)
+	}
+
+	// save allocation object
+	_, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
+	if err != nil {
+		return "", common.NewErrorf("rollback_connection_failed",
+			"saving allocation object: %v", err)
+	}
+
+	err = sc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+		Type:      allocEventRollback,
+		BlobberID: details.BlobberID,
+		Size:      -size,
+	}, balances)
+	if err != nil {
+		return "", common.NewError("rollback_connection_failed", err.Error())
+	}
+
+	sc.revertWrites(balances, int64(len(reverted)), size)
+
+	var b []byte
+	if b, err = json.Marshal(details); err != nil {
+		return "", common.NewError("rollback_connection_failed", err.Error())
+	}
+	return string(b), nil
+}
@@ -0,0 +1,206 @@
+package storagesc
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobberAllocation_addWriteMarker(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		d        = BlobberAllocation{AllocationID: "alloc", BlobberID: "b1"}
+		n        = maxWriteMarkersHistory + 5
+	)
+	for i := 0; i < n; i++ {
+		require.NoError(t, d.addWriteMarker(ssc.ID, &WriteMarker{
+			AllocationRoot: strconv.Itoa(i + 1),
+			PreviousAllocationRoot: func() string {
+				if i == 0 {
+					return ""
+				}
+				return strconv.Itoa(i)
+			}(),
+		}, balances))
+	}
+	d.AllocationRoot = strconv.Itoa(n)
+
+	var wms, err = d.writeMarkers(ssc.ID, balances)
+	require.NoError(t, err)
+	require.Len(t, wms, maxWriteMarkersHistory)
+	assert.Equal(t, "6", wms[0].AllocationRoot)
+	assert.Equal(t, strconv.Itoa(n), wms[len(wms)-1].AllocationRoot)
+
+	// pages of removed markers are removed
+	_, err = d.getWriteMarkersPage(ssc.ID, 0, balances)
+	require.Equal(t, util.ErrValueNotPresent, err)
+
+	var i int
+	i, err = d.rollbackTo(wms, "7")
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+
+	_, err = d.rollbackTo(wms, "3") // too old
+	require.Error(t, err)
+	_, err = d.rollbackTo(wms, "") // too old
+	require.Error(t, err)
+	_, err = d.rollbackTo(wms, d.AllocationRoot)
+	require.Error(t, err)
+
+	// revert to "7" and commit again
+	require.NoError(t, d.revertWriteMarkers(ssc.ID,
+		int64(len(wms)-i-1), balances))
+	_, err = d.getWriteMarkersPage(ssc.ID, 2, balances)
+	require.Equal(t, util.ErrValueNotPresent, err)
+	require.NoError(t, d.addWriteMarker(ssc.ID, &WriteMarker{
+		AllocationRoot:         "x",
+		PreviousAllocationRoot: "7",
+	}, balances))
+	wms, err = d.writeMarkers(ssc.ID, balances)
+	require.NoError(t, err)
+	require.Len(t, wms, 3)
+	assert.Equal(t, "6", wms[0].AllocationRoot)
+	assert.Equal(t, "7", wms[1].AllocationRoot)
+	assert.Equal(t, "x", wms[2].AllocationRoot)
+}
+
+func TestStorageSmartContract_rollbackBlobberConnection(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		tp, exp  = int64(100), int64(toSeconds(time.Hour))
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+
+	var (
+		blobberID = alloc.BlobberDetails[0].BlobberID
+		prevRoot  string
+	)
+
+	var commit = func(root string, size int64) {
+		tp += 100
+		var cc = &BlobberCloseConnection{
+			AllocationRoot:     root,
+			PrevAllocationRoot: prevRoot,
+			WriteMarker: &WriteMarker{
+				AllocationRoot:         root,
+				PreviousAllocationRoot: prevRoot,
+				AllocationID:           allocID,
+				Size:                   size,
+				BlobberID:              blobberID,
+				Timestamp:              common.Timestamp(tp),
+				ClientID:               client.id,
+			},
+		}
+		cc.WriteMarker.Signature, err = client.scheme.Sign(
+			encryption.Hash(cc.WriteMarker.GetHashData()))
+		require.NoError(t, err)
+
+		var tx = newTransaction(blobberID, ssc.ID, 0, tp)
+		balances.setTransaction(t, tx)
+		_, err = ssc.commitBlobberConnection(tx, mustEncode(t, cc), balances)
+		require.NoError(t, err)
+		prevRoot = root
+	}
+
+	var rollback = func(clientID, root string) (err error) {
+		tp += 100
+		var tx = newTransaction(clientID, ssc.ID, 0, tp)
+		balances.setTransaction(t, tx)
+		_, err = ssc.rollbackBlobberConnection(tx,
+			mustEncode(t, &rollbackConnectionRequest{
+				AllocationID:   allocID,
+				BlobberID:      blobberID,
+				AllocationRoot: root,
+			}), balances)
+		return
+	}
+
+	commit("root-1", 10*1024*1024)
+	commit("root-2", 20*1024*1024)
+	commit("root-3", 30*1024*1024)
+
+	var cp *challengePool
+	cp, err = ssc.getChallengePool(allocID, balances)
+	require.NoError(t, err)
+	var cpBalance = cp.Balance
+
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	var (
+		details   = alloc.BlobberMap[blobberID]
+		movedBack = alloc.MovedBack
+	)
+	var wms []*WriteMarker
+	wms, err = details.writeMarkers(ssc.ID, balances)
+	require.NoError(t, err)
+	require.Len(t, wms, 3)
+	assert.EqualValues(t, 60*1024*1024, details.Stats.UsedSize)
+
+	// not owner
+	requireErrMsg(t, rollback(blobberID, "root-1"),
+		"rollback_connection_failed: only owner of the allocation can "+
+			"roll it back")
+	// unknown root
+	requireErrMsg(t, rollback(client.id, "root-x"),
+		"rollback_connection_failed: allocation root not found in write "+
+			"markers history")
+
+	require.NoError(t, rollback(client.id, "root-1"))
+
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	details = alloc.BlobberMap[blobberID]
+	assert.Equal(t, "root-1", details.AllocationRoot)
+	assert.Equal(t, "root-1", details.LastWriteMarker.AllocationRoot)
+	wms, err = details.writeMarkers(ssc.ID, balances)
+	require.NoError(t, err)
+	require.Len(t, wms, 1)
+	assert.EqualValues(t, 10*1024*1024, details.Stats.UsedSize)
+	assert.EqualValues(t, 1, details.Stats.NumWrites)
+	assert.EqualValues(t, 10*1024*1024, alloc.Stats.UsedSize)
+	assert.EqualValues(t, 1, alloc.Stats.NumWrites)
+	require.True(t, alloc.MovedBack > movedBack)
+
+	// tokens of the reverted writes moved back to write pool
+	cp, err = ssc.getChallengePool(allocID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, cpBalance-(alloc.MovedBack-movedBack), cp.Balance)
+
+	// new commits continue from the root
+	prevRoot = "root-1"
+	commit("root-4", 5*1024*1024)
+
+	// revert all
+	require.NoError(t, rollback(client.id, ""))
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	details = alloc.BlobberMap[blobberID]
+	assert.Zero(t, details.AllocationRoot)
+	assert.Nil(t, details.LastWriteMarker)
+	wms, err = details.writeMarkers(ssc.ID, balances)
+	require.NoError(t, err)
+	assert.Len(t, wms, 0)
+	assert.Zero(t, details.Stats.UsedSize)
+
+	var last = lastAllocationEvent(t, ssc, allocID, balances)
+	assert.Equal(t, allocEventRollback, last.Type)
+	assert.EqualValues(t, -15*1024*1024, last.Size)
+}
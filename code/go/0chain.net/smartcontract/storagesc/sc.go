@@ -11,7 +11,9 @@ import (
 	sci "0chain.net/chaincore/smartcontractinterface"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
+	. "0chain.net/core/logging"
 	metrics "github.com/rcrowley/go-metrics"
+	"go.uber.org/zap"
 )
 
 const (
@@ -53,11 +55,14 @@ func (ssc *StorageSmartContract) setSC(sc *sci.SmartContract, bcContext sci.BCCo
 	// reading / writing
 	ssc.SmartContract.RestHandlers["/latestreadmarker"] = ssc.LatestReadMarkerHandler
 	ssc.SmartContractExecutionStats["read_redeem"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "read_redeem"), nil)
+	ssc.SmartContractExecutionStats["read_redeem_batch"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "read_redeem_batch"), nil)
 	ssc.SmartContractExecutionStats["commit_connection"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "commit_connection"), nil)
+	ssc.SmartContractExecutionStats["rollback_connection"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "rollback_connection"), nil)
 	// allocation
 	ssc.SmartContract.RestHandlers["/allocation"] = ssc.AllocationStatsHandler
 	ssc.SmartContract.RestHandlers["/allocations"] = ssc.GetAllocationsHandler
 	ssc.SmartContract.RestHandlers["/allocation_min_lock"] = ssc.GetAllocationMinLockHandler
+	ssc.SmartContract.RestHandlers["/allocation_events"] = ssc.AllocationEventsHandler
 	ssc.SmartContractExecutionStats["new_allocation_request"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "new_allocation_request"), nil)
 	ssc.SmartContractExecutionStats["update_allocation_request"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_allocation_request"), nil)
 	ssc.SmartContractExecutionStats["finalize_allocation"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "finalize_allocation"), nil)
@@ -66,9 +71,18 @@ func (ssc *StorageSmartContract) setSC(sc *sci.SmartContract, bcContext sci.BCCo
 	ssc.SmartContractExecutionStats["free_update_allocation"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_free_storage"), nil)
 	ssc.SmartContractExecutionStats["add_curator"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "add_curator"), nil)
 	ssc.SmartContractExecutionStats["curator_transfer_allocation"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "curator_transfer_allocation"), nil)
+	ssc.SmartContractExecutionStats["replace_blobber"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "replace_blobber"), nil)
+	ssc.SmartContractExecutionStats["add_co_owner"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "add_co_owner"), nil)
+	ssc.SmartContractExecutionStats["remove_co_owner"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "remove_co_owner"), nil)
+	ssc.SmartContractExecutionStats["offer_allocation_transfer"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "offer_allocation_transfer"), nil)
+	ssc.SmartContractExecutionStats["accept_allocation_transfer"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "accept_allocation_transfer"), nil)
+	ssc.SmartContractExecutionStats["set_auto_renewal"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "set_auto_renewal"), nil)
+	ssc.SmartContractExecutionStats["renewal_pool_lock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "renewal_pool_lock"), nil)
+	ssc.SmartContractExecutionStats["renewal_pool_unlock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "renewal_pool_unlock"), nil)
 	// challenge
 	ssc.SmartContract.RestHandlers["/openchallenges"] = ssc.OpenChallengeHandler
 	ssc.SmartContract.RestHandlers["/getchallenge"] = ssc.GetChallengeHandler
+	ssc.SmartContract.RestHandlers["/getRoundChallenges"] = ssc.getRoundChallengesHandler
 	ssc.SmartContractExecutionStats["challenge_request"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "challenge_request"), nil)
 	ssc.SmartContractExecutionStats["challenge_response"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "challenge_response"), nil)
 	ssc.SmartContractExecutionStats["generate_challenges"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "generate_challenges"), nil)
@@ -81,6 +95,8 @@ func (ssc *StorageSmartContract) setSC(sc *sci.SmartContract, bcContext sci.BCCo
 	// blobber
 	ssc.SmartContract.RestHandlers["/getblobbers"] = ssc.GetBlobbersHandler
 	ssc.SmartContract.RestHandlers["/getBlobber"] = ssc.GetBlobberHandler
+	ssc.SmartContract.RestHandlers["/getBlobberReputation"] = ssc.getBlobberReputationHandler
+	ssc.SmartContract.RestHandlers["/queryBlobbers"] = ssc.QueryBlobbersHandler
 	ssc.SmartContractExecutionStats["add_blobber"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "add_blobber (add/update/remove SC function)"), nil)
 	ssc.SmartContractExecutionStats["update_blobber_settings"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_blobber_settings"), nil)
 	ssc.SmartContractExecutionStats["pay_blobber_block_rewards"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "pay_blobber_block_rewards"), nil)
@@ -187,6 +203,19 @@ func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
 			}
 		}
 
+	case "read_redeem_batch":
+		if resp, err = sc.readRedeemBatch(t, input, balances); err != nil {
+			return
+		}
+		challengesEnabled := config.SmartContractConfig.GetBool(
+			"smart_contracts.storagesc.challenge_enabled")
+		if challengesEnabled {
+			err = sc.generateChallenges(t, balances.GetBlock(), input, balances)
+			if err != nil {
+				return "", err
+			}
+		}
+
 	case "commit_connection":
 		resp, err = sc.commitBlobberConnection(t, input, balances)
 		if err != nil {
@@ -202,6 +231,9 @@ func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
 			}
 		}
 
+	case "rollback_connection":
+		resp, err = sc.rollbackBlobberConnection(t, input, balances)
+
 	// allocations
 
 	case "new_allocation_request":
@@ -226,6 +258,22 @@ func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
 		resp, err = "", sc.addCurator(t, input, balances)
 	case "curator_transfer_allocation":
 		resp, err = sc.curatorTransferAllocation(t, input, balances)
+	case "replace_blobber":
+		resp, err = sc.replaceBlobber(t, input, balances)
+	case "add_co_owner":
+		resp, err = sc.addCoOwner(t, input, balances)
+	case "remove_co_owner":
+		resp, err = sc.removeCoOwner(t, input, balances)
+	case "offer_allocation_transfer":
+		resp, err = sc.offerAllocationTransfer(t, input, balances)
+	case "accept_allocation_transfer":
+		resp, err = sc.acceptAllocationTransfer(t, input, balances)
+	case "set_auto_renewal":
+		resp, err = sc.setAutoRenewal(t, input, balances)
+	case "renewal_pool_lock":
+		resp, err = sc.renewalPoolLock(t, input, balances)
+	case "renewal_pool_unlock":
+		resp, err = sc.renewalPoolUnlock(t, input, balances)
 
 	// blobbers
 
@@ -266,6 +314,10 @@ func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
 		resp, err = sc.stakePoolPayInterests(t, input, balances)
 
 	case "generate_challenges":
+		if err = sc.autoRenewAllocations(t, balances); err != nil {
+			// challenges are generated regardless of the auto-renewal
+			Logger.Error("auto_renew_allocations_failed", zap.Error(err))
+		}
 		challengesEnabled := config.SmartContractConfig.GetBool(
 			"smart_contracts.storagesc.challenge_enabled")
 		if challengesEnabled {
@@ -416,6 +416,16 @@ func (sp *stakePool) extendOffer(alloc *StorageAllocation,
 	return
 }
 
+// removeOffer of an allocation, used when the blobber is replaced in the
+// allocation
+func (sp *stakePool) removeOffer(allocID string) (err error) {
+	if sp.findOffer(allocID) == nil {
+		return errors.New("missing offer pool for " + allocID)
+	}
+	delete(sp.Offers, allocID)
+	return
+}
+
 func maxBalance(a, b state.Balance) state.Balance {
 	if a > b {
 		return a
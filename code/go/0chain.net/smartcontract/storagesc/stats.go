@@ -24,6 +24,25 @@ func (sc *StorageSmartContract) newWrite(statectx c_state.StateContextI, writeSi
 
 }
 
+// revertWrites updates statistics on rolled back write markers
+func (sc *StorageSmartContract) revertWrites(statectx c_state.StateContextI,
+	numWrites, size int64) {
+
+	stats := &StorageStats{}
+	stats.Stats = &StorageAllocationStats{}
+	statsBytes, _ := statectx.GetTrieNode(stats.GetKey(sc.ID))
+	if statsBytes != nil {
+		if err := stats.Decode(statsBytes.Encode()); err != nil {
+			Logger.Error("storage stats decode error")
+			return
+		}
+	}
+
+	stats.Stats.NumWrites -= numWrites
+	stats.Stats.UsedSize -= size
+	statectx.InsertTrieNode(stats.GetKey(sc.ID), stats)
+}
+
 func (sc *StorageSmartContract) newRead(statectx c_state.StateContextI, numReads int64) {
 	stats := &StorageStats{}
 	stats.Stats = &StorageAllocationStats{}
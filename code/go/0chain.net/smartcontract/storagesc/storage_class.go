@@ -0,0 +1,120 @@
+package storagesc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"0chain.net/chaincore/state"
+)
+
+// storageClassConfig represents rules of a storage class, such as hot, cold
+// or archive ('storagesc.storage_classes.<class>' from sc.yaml).
+type storageClassConfig struct {
+	// ChallengeRate is part (value in (0; 1] range) of challenges generated
+	// for allocations of the class; e.g. 0.1 means an archive allocation is
+	// challenged 10 times rarely than a hot one.
+	ChallengeRate float64 `json:"challenge_rate"`
+	// MaxReadPrice allowed for a blobber of the class; zero means the SC
+	// wide max_read_price.
+	MaxReadPrice state.Balance `json:"max_read_price"`
+	// MaxWritePrice allowed for a blobber of the class; zero means the SC
+	// wide max_write_price.
+	MaxWritePrice state.Balance `json:"max_write_price"`
+	// MaxChallengeCompletionTime allowed for a blobber of the class; zero
+	// means the SC wide max_challenge_completion_time. It's used to keep
+	// slow blobbers out of hot class.
+	MaxChallengeCompletionTime time.Duration `json:"max_challenge_completion_time"`
+}
+
+func (scc *storageClassConfig) validate() (err error) {
+	if scc.ChallengeRate <= 0.0 || 1.0 < scc.ChallengeRate {
+		return fmt.Errorf("challenge_rate not in (0; 1] range: %v",
+			scc.ChallengeRate)
+	}
+	if scc.MaxReadPrice < 0 {
+		return fmt.Errorf("negative max_read_price: %v", scc.MaxReadPrice)
+	}
+	if scc.MaxWritePrice < 0 {
+		return fmt.Errorf("negative max_write_price: %v", scc.MaxWritePrice)
+	}
+	if scc.MaxChallengeCompletionTime < 0 {
+		return fmt.Errorf("negative max_challenge_completion_time: %v",
+			scc.MaxChallengeCompletionTime)
+	}
+	return
+}
+
+// validateTerms of a blobber of the class
+func (scc *storageClassConfig) validateTerms(t *Terms) (err error) {
+	if scc.MaxReadPrice > 0 && t.ReadPrice > scc.MaxReadPrice {
+		return errors.New("read_price is greater than max_read_price " +
+			"of the storage class")
+	}
+	if scc.MaxWritePrice > 0 && t.WritePrice > scc.MaxWritePrice {
+		return errors.New("write_price is greater than max_write_price " +
+			"of the storage class")
+	}
+	if scc.MaxChallengeCompletionTime > 0 &&
+		t.ChallengeCompletionTime > scc.MaxChallengeCompletionTime {
+		return errors.New("challenge_completion_time is greater than " +
+			"max_challenge_completion_time of the storage class")
+	}
+	return
+}
+
+// storageClass returns rules of given storage class
+func (conf *scConfig) storageClass(class string) (
+	scc *storageClassConfig, err error) {
+
+	var ok bool
+	if scc, ok = conf.StorageClasses[class]; !ok {
+		return nil, fmt.Errorf("unknown storage class: %q", class)
+	}
+	return
+}
+
+// challengeRate of allocations of given storage class; allocations
+// without a storage class are always challenged
+func (conf *scConfig) challengeRate(class string) float64 {
+	if scc, ok := conf.StorageClasses[class]; ok {
+		return scc.ChallengeRate
+	}
+	return 1.0
+}
+
+// validateStorageClass of the blobber against the storage class rules;
+// blobbers registered without a storage class serve only allocations
+// without a storage class
+func (sn *StorageNode) validateStorageClass(conf *scConfig) (err error) {
+	if sn.StorageClass == "" {
+		return // not classified
+	}
+	var scc *storageClassConfig
+	if scc, err = conf.storageClass(sn.StorageClass); err != nil {
+		return
+	}
+	return scc.validateTerms(&sn.Terms)
+}
+
+// filterBlobbersByStorageClass kicks off blobbers of other storage class or
+// blobbers don't follow the class rules (for example, if rules of the class
+// has been changed after the blobber registration); empty class means any
+// blobbers
+func filterBlobbersByStorageClass(class string, conf *scConfig) (
+	filter filterBlobberFunc) {
+
+	return filterBlobberFunc(func(b *StorageNode) (kick bool) {
+		if class == "" {
+			return false // filter disabled
+		}
+		if b.StorageClass != class {
+			return true
+		}
+		var scc, err = conf.storageClass(class)
+		if err != nil {
+			return true
+		}
+		return scc.validateTerms(&b.Terms) != nil
+	})
+}
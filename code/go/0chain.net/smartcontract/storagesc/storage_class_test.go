@@ -0,0 +1,155 @@
+package storagesc
+
+import (
+	"testing"
+	"time"
+
+	"0chain.net/core/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_filterBlobbersByStorageClass(t *testing.T) {
+	var conf = &scConfig{
+		StorageClasses: map[string]*storageClassConfig{
+			"hot": {
+				ChallengeRate:              1.0,
+				MaxChallengeCompletionTime: 1 * time.Minute,
+			},
+			"archive": {
+				ChallengeRate: 0.1,
+				MaxWritePrice: 1 * x10,
+			},
+		},
+	}
+
+	var (
+		fast    = &StorageNode{StorageClass: "hot"}
+		slow    = &StorageNode{StorageClass: "hot"}
+		cheap   = &StorageNode{StorageClass: "archive"}
+		expense = &StorageNode{StorageClass: "archive"}
+		none    = &StorageNode{}
+	)
+	fast.Terms.ChallengeCompletionTime = 30 * time.Second
+	slow.Terms.ChallengeCompletionTime = 5 * time.Minute
+	cheap.Terms.WritePrice = 1 * x10
+	expense.Terms.WritePrice = 2 * x10
+
+	assert.NoError(t, fast.validateStorageClass(conf))
+	assert.Error(t, slow.validateStorageClass(conf))
+	assert.NoError(t, cheap.validateStorageClass(conf))
+	assert.Error(t, expense.validateStorageClass(conf))
+	assert.NoError(t, none.validateStorageClass(conf))
+	assert.Error(t, (&StorageNode{StorageClass: "cold"}).
+		validateStorageClass(conf))
+
+	var hot = filterBlobbersByStorageClass("hot", conf)
+	assert.False(t, hot(fast))
+	assert.True(t, hot(slow))
+	assert.True(t, hot(cheap))
+	assert.True(t, hot(none))
+
+	var archive = filterBlobbersByStorageClass("archive", conf)
+	assert.False(t, archive(cheap))
+	assert.True(t, archive(expense))
+	assert.True(t, archive(fast))
+
+	var unclassified = filterBlobbersByStorageClass("", conf)
+	assert.False(t, unclassified(slow))
+	assert.False(t, unclassified(none))
+
+	assert.Equal(t, 0.1, conf.challengeRate("archive"))
+	assert.Equal(t, 1.0, conf.challengeRate(""))
+
+	conf.StorageClasses["cold"] = &storageClassConfig{ChallengeRate: 0}
+	assert.Error(t, conf.StorageClasses["cold"].validate())
+}
+
+func TestStorageSmartContract_newAllocationRequest_storageClass(t *testing.T) {
+	const tp, exp = 100, int64(100 + time.Hour/time.Second)
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		conf     = setConfig(t, balances)
+		err      error
+	)
+
+	conf.StorageClasses = map[string]*storageClassConfig{
+		"hot": {
+			ChallengeRate:              1.0,
+			MaxChallengeCompletionTime: 1 * time.Minute,
+		},
+		"archive": {
+			ChallengeRate: 0.1,
+			MaxReadPrice:  1 * x10,
+			MaxWritePrice: 10 * x10,
+		},
+	}
+	mustSave(t, scConfigKey(ADDRESS), conf, balances)
+
+	var archived = make(map[string]bool)
+	for i := 0; i < 30; i++ {
+		var (
+			blob = addBlobber(t, ssc, 2*GB, tp, avgTerms, 50*x10, balances)
+			b    *StorageNode
+		)
+		if i%3 == 0 {
+			continue // not classified
+		}
+		b, err = ssc.getBlobber(blob.id, balances)
+		require.NoError(t, err)
+		b.StorageClass = "archive"
+		_, err = updateBlobber(t, b, 0, tp, ssc, balances)
+		require.NoError(t, err)
+		archived[b.ID] = true
+	}
+	require.Len(t, archived, 20)
+
+	// too slow for the hot class
+	var b *StorageNode
+	for id := range archived {
+		b, err = ssc.getBlobber(id, balances)
+		require.NoError(t, err)
+		break
+	}
+	b.StorageClass = "hot"
+	_, err = updateBlobber(t, b, 0, tp, ssc, balances)
+	require.Error(t, err)
+
+	var nar = new(newAllocationRequest)
+	nar.DataShards = 10
+	nar.ParityShards = 10
+	nar.Expiration = common.Timestamp(exp)
+	nar.Owner = client.id
+	nar.OwnerPublicKey = client.pk
+	nar.ReadPriceRange = PriceRange{1 * x10, 10 * x10}
+	nar.WritePriceRange = PriceRange{2 * x10, 20 * x10}
+	nar.Size = 2 * GB
+	nar.MaxChallengeCompletionTime = 200 * time.Hour
+
+	// unknown
+	nar.StorageClass = "cold"
+	_, err = nar.callNewAllocReq(t, client.id, 15*x10, ssc, tp, balances)
+	require.Error(t, err)
+
+	// no hot blobbers
+	nar.StorageClass = "hot"
+	_, err = nar.callNewAllocReq(t, client.id, 15*x10, ssc, tp, balances)
+	require.Error(t, err)
+
+	nar.StorageClass = "archive"
+	var resp string
+	resp, err = nar.callNewAllocReq(t, client.id, 15*x10, ssc, tp, balances)
+	require.NoError(t, err)
+
+	var alloc StorageAllocation
+	require.NoError(t, alloc.Decode([]byte(resp)))
+	assert.Equal(t, "archive", alloc.StorageClass)
+	require.Len(t, alloc.BlobberDetails, 20)
+	for _, d := range alloc.BlobberDetails {
+		assert.True(t, archived[d.BlobberID])
+	}
+}
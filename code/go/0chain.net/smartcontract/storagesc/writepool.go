@@ -305,6 +305,12 @@ func (ssc *StorageSmartContract) writePoolLock(t *transaction.Transaction,
 			"can't get allocation: "+err.Error())
 	}
 
+	if !alloc.hasPermission(t.ClientID, permManagePools) {
+		return "", common.NewError("write_pool_lock_failed",
+			"only owner or co-owner with manage_pools permission can "+
+				"lock tokens for the allocation")
+	}
+
 	var bps blobberPools
 
 	// lock for allocation -> blobber (particular blobber locking)
@@ -367,6 +373,16 @@ func (ssc *StorageSmartContract) writePoolLock(t *transaction.Transaction,
 		return "", common.NewErrorf("write_pool_lock_failed",
 			"saving allocation: %v", err)
 	}
+
+	err = ssc.addAllocationEvent(t, alloc.ID, &AllocationEvent{
+		Type:      allocEventWritePoolLock,
+		BlobberID: lr.BlobberID,
+		PoolID:    ap.ID,
+		Amount:    state.Balance(t.Value),
+	}, balances)
+	if err != nil {
+		return "", common.NewError("write_pool_lock_failed", err.Error())
+	}
 	return
 }
 
@@ -431,6 +447,12 @@ func (ssc *StorageSmartContract) writePoolUnlock(t *transaction.Transaction,
 		return "", common.NewError("write_pool_unlock_failed", err.Error())
 	}
 
+	var unlocked = &AllocationEvent{
+		Type:   allocEventWritePoolUnlock,
+		PoolID: ap.ID,
+		Amount: ap.Balance,
+	}
+
 	transfer, resp, err = ap.EmptyPool(ssc.ID, t.ClientID,
 		common.ToTime(t.CreationDate))
 	if err != nil {
@@ -446,6 +468,11 @@ func (ssc *StorageSmartContract) writePoolUnlock(t *transaction.Transaction,
 		return "", common.NewError("write_pool_unlock_failed", err.Error())
 	}
 
+	err = ssc.addAllocationEvent(t, ap.AllocationID, unlocked, balances)
+	if err != nil {
+		return "", common.NewError("write_pool_unlock_failed", err.Error())
+	}
+
 	return
 }
 
@@ -23,6 +23,33 @@ import (
 
 var errZeroVesting = errors.New("zero vesting for this destination and period")
 
+// vesting steps
+const (
+	stepLinear    = ""          // vesting continuously
+	stepMonthly   = "monthly"   // vesting every month
+	stepQuarterly = "quarterly" // vesting every three months
+)
+
+// stepMonths returns number of months of given step, zero for linear
+// vesting and -1 for unknown step
+func stepMonths(step string) int {
+	switch step {
+	case stepLinear:
+		return 0
+	case stepMonthly:
+		return 1
+	case stepQuarterly:
+		return 3
+	}
+	return -1
+}
+
+// addMonths adds given number of calendar months to given time (UTC)
+func addMonths(ts common.Timestamp, months int) common.Timestamp {
+	return common.Timestamp(time.Unix(int64(ts), 0).UTC().
+		AddDate(0, months, 0).Unix())
+}
+
 //
 // lock, unlock, trigger, delete a pool
 //
@@ -65,6 +92,43 @@ type destination struct {
 	// can produce zero tokens transfer (resolution is a second). The move
 	// will be updated only if a triggering really moves tokens (non zero).
 	Move common.Timestamp `json:"move"`
+
+	// Cliff is period from the pool start nothing vests during. All tokens
+	// accrued by the end of the cliff vest at once.
+	Cliff time.Duration `json:"cliff,omitempty"`
+	// Step of the vesting: linear (empty), monthly or quarterly. Tokens of
+	// a step schedule vest at once at the end of every step.
+	Step string `json:"step,omitempty"`
+	// Irrevocable destination can't be stopped by the pool owner. Unvested
+	// tokens of a stopped destination become excess tokens of the pool,
+	// vested ones stay claimable. Destinations are revocable by default.
+	Irrevocable bool `json:"irrevocable,omitempty"`
+	// Stopped is time the vesting stopped by the owner at.
+	Stopped common.Timestamp `json:"stopped,omitempty"`
+}
+
+// isScheduled returns true if the destination has a cliff or a step
+// schedule, the linear vesting without a cliff is not scheduled
+func (d *destination) isScheduled() bool {
+	return d.Cliff > 0 || d.Step != stepLinear
+}
+
+// ratio of tokens vested at given time by the schedule of the destination
+func (d *destination) vestedRatio(now, start, end common.Timestamp) float64 {
+	if now >= end {
+		return 1.0
+	}
+	if now < start+toSeconds(d.Cliff) {
+		return 0.0
+	}
+	var at = now
+	if months := stepMonths(d.Step); months > 0 {
+		at = start // last step before the now
+		for k := 1; addMonths(start, k*months) <= now; k++ {
+			at = addMonths(start, k*months)
+		}
+	}
+	return float64(at-start) / float64(end-start)
 }
 
 // tokens left for this destination
@@ -97,9 +161,17 @@ func (d *destination) move(now common.Timestamp, moved state.Balance) {
 // used to obtain pool statistic. The now must not be later than the
 // end. Also, the now must be greater or equal to start time of related
 // vesting pool.
-func (d *destination) unlock(now, end common.Timestamp, dry bool) (
+func (d *destination) unlock(now, start, end common.Timestamp, dry bool) (
 	amount state.Balance) {
 
+	if d.Stopped != 0 || d.isScheduled() {
+		amount = d.unlockScheduled(now, start, end)
+		if !dry {
+			d.move(now, amount)
+		}
+		return
+	}
+
 	var (
 		full   = d.full(end)   // full time range left
 		period = d.period(now) // current vesting period
@@ -123,6 +195,55 @@ func (d *destination) unlock(now, end common.Timestamp, dry bool) (
 	return
 }
 
+// unlockScheduled returns amount of tokens to vest by the schedule for the
+// now, all tokens left of a stopped destination are vested already
+func (d *destination) unlockScheduled(now, start, end common.Timestamp) (
+	amount state.Balance) {
+
+	if d.Stopped != 0 {
+		return d.left()
+	}
+	amount = state.Balance(float64(d.Amount)*d.vestedRatio(now, start, end)) -
+		d.Vested
+	if amount < 0 {
+		amount = 0
+	}
+	return
+}
+
+// projection of the vesting, total vested tokens at every schedule point,
+// tokens of a linear schedule vest linearly between the points
+func (d *destination) projection(start, end common.Timestamp) (
+	points []*vestingPoint) {
+
+	if d.Stopped != 0 {
+		return []*vestingPoint{{Time: d.Stopped, Vested: d.Amount}}
+	}
+
+	var times = []common.Timestamp{start}
+	if d.Cliff > 0 {
+		times = append(times, start+toSeconds(d.Cliff))
+	}
+	if months := stepMonths(d.Step); months > 0 {
+		for k := 1; addMonths(start, k*months) < end; k++ {
+			times = append(times, addMonths(start, k*months))
+		}
+	}
+	times = append(times, end)
+
+	for _, tp := range times {
+		if n := len(points); n > 0 && points[n-1].Time >= tp {
+			continue // duplicate or before the cliff
+		}
+		points = append(points, &vestingPoint{
+			Time: tp,
+			Vested: state.Balance(float64(d.Amount) *
+				d.vestedRatio(tp, start, end)),
+		})
+	}
+	return
+}
+
 //
 // destinations of a pool
 //
@@ -132,9 +253,10 @@ type destinations []*destination
 // start sets start time (the Last and the Move)
 func (ds destinations) start(now common.Timestamp) {
 	for _, d := range ds {
-		d.Last = now // } setup start time
-		d.Move = now // }
-		d.Vested = 0 // clean possible request injection
+		d.Last = now  // } setup start time
+		d.Move = now  // }
+		d.Vested = 0  // clean possible request injection
+		d.Stopped = 0 // }
 	}
 }
 
@@ -181,6 +303,12 @@ func (ar *addRequest) validate(now common.Timestamp, conf *config) (err error) {
 		if d.Amount < 0 {
 			return fmt.Errorf("negative amount for %q: %d", d.ID, d.Amount)
 		}
+		if d.Cliff < 0 || d.Cliff > ar.Duration {
+			return fmt.Errorf("invalid cliff for %q: %s", d.ID, d.Cliff)
+		}
+		if stepMonths(d.Step) < 0 {
+			return fmt.Errorf("invalid step for %q: %q", d.ID, d.Step)
+		}
 	}
 	return
 }
@@ -331,7 +459,7 @@ func (vp *vestingPool) trigger(t *transaction.Transaction,
 	)
 	sb.WriteByte('[')
 	for _, d := range vp.Destinations {
-		var value = d.unlock(now, end, false)
+		var value = d.unlock(now, vp.StartTime, end, false)
 		if value == 0 {
 			continue
 		}
@@ -348,6 +476,7 @@ func (vp *vestingPool) trigger(t *transaction.Transaction,
 	}
 	sb.WriteByte(']')
 
+	vp.removeClaimed()
 	return sb.String(), nil
 }
 
@@ -379,6 +508,18 @@ func (vp *vestingPool) delete(destID string) (err error) {
 	return
 }
 
+// removeClaimed removes stopped destinations claimed all vested tokens
+func (vp *vestingPool) removeClaimed() {
+	var i int
+	for _, d := range vp.Destinations {
+		if d.Stopped != 0 && d.left() == 0 {
+			continue
+		}
+		vp.Destinations[i], i = d, i+1
+	}
+	vp.Destinations = vp.Destinations[:i]
+}
+
 func (vp *vestingPool) find(destID string) (d *destination, err error) {
 	for _, x := range vp.Destinations {
 		if x.ID != destID {
@@ -410,7 +551,7 @@ func (vp *vestingPool) vest(vscID, destID datastore.Key, now common.Timestamp,
 		return
 	}
 
-	var value = d.unlock(now, end, false)
+	var value = d.unlock(now, vp.StartTime, end, false)
 	if value == 0 {
 		return "", errZeroVesting
 	}
@@ -419,9 +560,22 @@ func (vp *vestingPool) vest(vscID, destID datastore.Key, now common.Timestamp,
 		return "", fmt.Errorf("transferring to %s: %v", d.ID, err)
 	}
 
+	vp.removeClaimed()
 	return
 }
 
+// revoke stops vesting for a destination at given time; its unvested
+// tokens become excess tokens the owner can unlock, vested tokens stay
+// claimable by the destination
+func (vp *vestingPool) revoke(d *destination, now common.Timestamp) {
+	if now < vp.StartTime {
+		now = vp.StartTime
+	}
+	d.Amount = d.Vested + d.unlock(now, vp.StartTime, vp.ExpireAt, true)
+	d.Stopped = now
+	vp.removeClaimed()
+}
+
 func (vp *vestingPool) drain(t *transaction.Transaction,
 	balances chainstate.StateContextI) (resp string, err error) {
 
@@ -476,19 +630,31 @@ func (vp *vestingPool) info(now common.Timestamp) (i *info) {
 		now = end
 	}
 
-	var dinfos = make([]*destInfo, 0, len(vp.Destinations))
+	var (
+		dinfos      = make([]*destInfo, 0, len(vp.Destinations))
+		projections = make([]*destProjection, 0, len(vp.Destinations))
+	)
 	for _, d := range vp.Destinations {
-		var value = d.unlock(now, end, true)
+		var value = d.unlock(now, vp.StartTime, end, true)
 		dinfos = append(dinfos, &destInfo{
+			ID:          d.ID,
+			Wanted:      d.Amount,
+			Earned:      value,
+			Vested:      d.Vested,
+			Last:        d.Last,
+			Cliff:       d.Cliff,
+			Step:        d.Step,
+			Irrevocable: d.Irrevocable,
+			Stopped:     d.Stopped,
+		})
+		projections = append(projections, &destProjection{
 			ID:     d.ID,
-			Wanted: d.Amount,
-			Earned: value,
-			Vested: d.Vested,
-			Last:   d.Last,
+			Points: d.projection(vp.StartTime, vp.ExpireAt),
 		})
 	}
 
 	i.Destinations = dinfos
+	i.Projection = projections
 	i.ClientID = vp.ClientID
 	return
 }
@@ -499,6 +665,23 @@ type destInfo struct {
 	Earned state.Balance    `json:"earned"` // can unlock
 	Vested state.Balance    `json:"vested"` // tokens already vested
 	Last   common.Timestamp `json:"last"`   // last time unlocked
+
+	Cliff       time.Duration    `json:"cliff,omitempty"`       // schedule
+	Step        string           `json:"step,omitempty"`        // schedule
+	Irrevocable bool             `json:"irrevocable,omitempty"` // can't be stopped
+	Stopped     common.Timestamp `json:"stopped,omitempty"`     // stopped at
+}
+
+// vestingPoint is total vested tokens of a destination at a time
+type vestingPoint struct {
+	Time   common.Timestamp `json:"time"`
+	Vested state.Balance    `json:"vested"`
+}
+
+// destProjection is projected vesting schedule of a destination
+type destProjection struct {
+	ID     datastore.Key   `json:"id"`
+	Points []*vestingPoint `json:"points"`
 }
 
 type info struct {
@@ -510,6 +693,9 @@ type info struct {
 	ExpireAt     common.Timestamp `json:"expire_at"`    // until
 	Destinations []*destInfo      `json:"destinations"` // receivers
 	ClientID     datastore.Key    `json:"client_id"`    // owner
+
+	// Projection is full projected vesting schedule of the destinations.
+	Projection []*destProjection `json:"projection"`
 }
 
 //
@@ -638,22 +824,29 @@ func (vsc *VestingSmartContract) stop(t *transaction.Transaction,
 		return "", common.NewError("stop_vesting_failed", "expired pool")
 	}
 
-	_, err = vp.vest(t.ToClientID, sr.Destination, t.CreationDate, balances)
-	if err != nil && err != errZeroVesting {
+	var d *destination
+	if d, err = vp.find(sr.Destination); err != nil {
 		return "", common.NewError("stop_vesting_failed", err.Error())
 	}
 
-	if err = vp.delete(sr.Destination); err != nil {
+	if d.Irrevocable {
 		return "", common.NewError("stop_vesting_failed",
-			"deleting destination: "+err.Error())
+			"destination is irrevocable")
 	}
 
+	if d.Stopped != 0 {
+		return "", common.NewError("stop_vesting_failed",
+			"vesting already stopped")
+	}
+
+	vp.revoke(d, t.CreationDate)
+
 	if err = vp.save(balances); err != nil {
 		return "", common.NewError("trigger_vesting_pool_failed",
 			"saving pool: "+err.Error())
 	}
 
-	return sr.Destination + " has deleted from the vesting pool", nil
+	return "vesting for " + sr.Destination + " has stopped", nil
 }
 
 func (vsc *VestingSmartContract) delete(t *transaction.Transaction,
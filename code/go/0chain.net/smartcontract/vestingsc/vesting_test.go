@@ -68,11 +68,73 @@ func Test_addRequest_validate(t *testing.T) {
 		&destination{ID: "two", Amount: 20},
 	}
 
+	ar.Destinations[0].Cliff = 2 * time.Minute
+	assertErrMsg(t, ar.validate(10, conf), `invalid cliff for "one": 2m0s`)
+	ar.Destinations[0].Cliff = 30 * time.Second
+	ar.Destinations[1].Step = "weekly"
+	assertErrMsg(t, ar.validate(10, conf), `invalid step for "two": "weekly"`)
+	ar.Destinations[1].Step = stepQuarterly
+
 	assert.NoError(t, ar.validate(10, conf))
 	ar.StartTime = 0
 	assert.NoError(t, ar.validate(10, conf))
 }
 
+func Test_destination_schedule(t *testing.T) {
+	// linear with a cliff
+	var (
+		d          = &destination{Amount: 100, Cliff: 30 * time.Second}
+		start, end = common.Timestamp(0), common.Timestamp(100)
+	)
+	assert.Zero(t, d.unlock(29, start, end, true))
+	assert.EqualValues(t, 30, d.unlock(30, start, end, true))
+	assert.EqualValues(t, 50, d.unlock(50, start, end, false))
+	assert.EqualValues(t, 10, d.unlock(60, start, end, true))
+	assert.EqualValues(t, 50, d.unlock(end, start, end, true))
+	assert.Equal(t, []*vestingPoint{
+		{Time: 0, Vested: 0},
+		{Time: 30, Vested: 30},
+		{Time: 100, Vested: 100},
+	}, d.projection(start, end))
+
+	// quarterly steps within 2020 (366 days)
+	start = common.Timestamp(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Unix())
+	end = addMonths(start, 12)
+	d = &destination{Amount: 1200, Step: stepQuarterly}
+
+	var at = func(month time.Month, day int) common.Timestamp {
+		return common.Timestamp(time.Date(2020, month, day, 0, 0, 0, 0,
+			time.UTC).Unix())
+	}
+	assert.Zero(t, d.unlock(at(3, 31), start, end, true))
+	assert.EqualValues(t, 1200*91/366, d.unlock(at(4, 1), start, end, true))
+	assert.EqualValues(t, 1200*91/366, d.unlock(at(6, 30), start, end, true))
+	assert.EqualValues(t, 1200*182/366, d.unlock(at(7, 1), start, end, true))
+	assert.EqualValues(t, 1200, d.unlock(end, start, end, true))
+
+	var points = d.projection(start, end)
+	require.Len(t, points, 5)
+	assert.Equal(t, at(10, 1), points[3].Time)
+	assert.EqualValues(t, 1200, points[4].Vested)
+
+	// monthly steps with a quarter cliff
+	d = &destination{Amount: 1200, Step: stepMonthly,
+		Cliff: time.Duration(at(4, 1)-start) * time.Second}
+	assert.Zero(t, d.unlock(at(3, 1), start, end, true))
+	assert.EqualValues(t, 1200*91/366, d.unlock(at(4, 1), start, end, true))
+	points = d.projection(start, end)
+	require.Len(t, points, 11) // start, cliff, 8 months, end
+	assert.Zero(t, points[0].Vested)
+	assert.Equal(t, at(4, 1), points[1].Time)
+	assert.Equal(t, at(5, 1), points[2].Time)
+
+	// stopped
+	d = &destination{Amount: 40, Vested: 10, Stopped: 50}
+	assert.EqualValues(t, 30, d.unlock(60, start, end, true))
+	assert.Equal(t, []*vestingPoint{{Time: 50, Vested: 40}},
+		d.projection(start, end))
+}
+
 func Test_vestingPool(t *testing.T) {
 	const poolID, clientID = "pool_hex", "client_hex"
 	require.NotZero(t, poolKey(ADDRESS, poolID))
@@ -491,6 +553,130 @@ func TestVestingSmartContract_trigger(t *testing.T) {
 	assert.Equal(t, state.Balance(29000), got.Balance)
 }
 
+func TestVestingSmartContract_stopScheduled(t *testing.T) {
+	var (
+		vsc      = newTestVestingSC()
+		balances = newTestBalances()
+		client   = newClient(1200e10, balances)
+		tp       = common.Timestamp(0)
+		err      error
+	)
+
+	configureConfig()
+
+	var resp string
+	resp, err = client.add(t, vsc, &addRequest{
+		Description: "for something",
+		StartTime:   10,
+		Duration:    100 * time.Second,
+		Destinations: destinations{
+			&destination{ID: "one", Amount: 100, Cliff: 20 * time.Second},
+			&destination{ID: "two", Amount: 200, Step: stepMonthly},
+		},
+	}, 100e10, tp, balances)
+	require.NoError(t, err)
+	var set vestingPool
+	require.NoError(t, set.Decode([]byte(resp)))
+
+	// stop in the middle
+	var (
+		tx = newTransaction(client.id, vsc.ID, 0, 60)
+		sr = stopRequest{PoolID: set.ID, Destination: "one"}
+	)
+	balances.txn = tx
+	_, err = vsc.stop(tx, mustEncode(t, &sr), balances)
+	require.NoError(t, err)
+
+	_, err = vsc.stop(tx, mustEncode(t, &sr), balances)
+	assertErrMsg(t, err, "stop_vesting_failed: vesting already stopped")
+
+	var got *vestingPool
+	got, err = vsc.getPool(set.ID, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100e10, got.Balance)
+	var one = got.Destinations[0]
+	assert.EqualValues(t, 50, one.Amount)
+	assert.EqualValues(t, 60, one.Stopped)
+
+	// the info
+	var pi = got.info(70)
+	require.Len(t, pi.Projection, 2)
+	assert.Equal(t, []*vestingPoint{{Time: 60, Vested: 50}},
+		pi.Projection[0].Points)
+	assert.Equal(t, []*vestingPoint{{Time: 10, Vested: 0},
+		{Time: 110, Vested: 200}}, pi.Projection[1].Points)
+	assert.EqualValues(t, 50, pi.Destinations[0].Earned)
+	assert.Zero(t, pi.Destinations[1].Earned)     // first month is not over
+	assert.EqualValues(t, 100e10-50-200, pi.Left) // unvested are excess
+
+	// vested tokens stay claimable
+	tx = newTransaction("one", vsc.ID, 0, 70)
+	balances.txn = tx
+	_, err = vsc.unlock(tx, mustEncode(t, &poolRequest{PoolID: set.ID}),
+		balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 50, balances.balances["one"])
+
+	got, err = vsc.getPool(set.ID, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100e10-50, got.Balance)
+	require.Len(t, got.Destinations, 1) // fully claimed, removed
+	assert.Equal(t, "two", got.Destinations[0].ID)
+
+	// the owner unlocks the unvested tokens
+	tx = newTransaction(client.id, vsc.ID, 0, 70)
+	balances.txn = tx
+	_, err = vsc.unlock(tx, mustEncode(t, &poolRequest{PoolID: set.ID}),
+		balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1200e10-250, balances.balances[client.id])
+}
+
+func TestVestingSmartContract_irrevocable(t *testing.T) {
+	var (
+		vsc      = newTestVestingSC()
+		balances = newTestBalances()
+		client   = newClient(1200e10, balances)
+		tp       = common.Timestamp(0)
+		err      error
+	)
+
+	configureConfig()
+
+	var resp string
+	resp, err = client.add(t, vsc, &addRequest{
+		Description: "for something",
+		StartTime:   10,
+		Duration:    100 * time.Second,
+		Destinations: destinations{
+			&destination{ID: "one", Amount: 100, Irrevocable: true},
+			&destination{ID: "two", Amount: 200},
+		},
+	}, 100e10, tp, balances)
+	require.NoError(t, err)
+	var set vestingPool
+	require.NoError(t, set.Decode([]byte(resp)))
+
+	var (
+		tx = newTransaction(client.id, vsc.ID, 0, 60)
+		sr = stopRequest{PoolID: set.ID, Destination: "one"}
+	)
+	balances.txn = tx
+	_, err = vsc.stop(tx, mustEncode(t, &sr), balances)
+	assertErrMsg(t, err, "stop_vesting_failed: destination is irrevocable")
+
+	var got *vestingPool
+	got, err = vsc.getPool(set.ID, balances)
+	require.NoError(t, err)
+	assert.Zero(t, got.Destinations[0].Stopped)
+	assert.True(t, got.info(60).Destinations[0].Irrevocable)
+
+	// destinations are revocable by default, including the legacy ones
+	sr.Destination = "two"
+	_, err = vsc.stop(tx, mustEncode(t, &sr), balances)
+	require.NoError(t, err)
+}
+
 func TestVestingSmartContract_getPoolInfoHandler(t *testing.T) {
 	var (
 		vsc      = newTestVestingSC()
@@ -0,0 +1,173 @@
+package zrc20sc
+
+import (
+	"encoding/json"
+
+	c_state "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+// allowance is number of tokens of a token pool of the owner the spender
+// allowed to transfer
+type allowance struct {
+	TokenName string        `json:"token_name"`
+	Owner     datastore.Key `json:"owner"`
+	Spender   datastore.Key `json:"spender"`
+	Value     state.Balance `json:"value"`
+}
+
+func (a *allowance) Encode() []byte {
+	buff, _ := json.Marshal(a)
+	return buff
+}
+
+func (a *allowance) Decode(input []byte) error {
+	err := json.Unmarshal(input, a)
+	return err
+}
+
+func (a *allowance) GetHash() string {
+	return util.ToHex(a.GetHashBytes())
+}
+
+func (a *allowance) GetHashBytes() []byte {
+	return encryption.RawHash(a.Encode())
+}
+
+func (a *allowance) getKey(globalKey string) datastore.Key {
+	return datastore.Key(globalKey + encryption.Hash(a.TokenName) +
+		":allowance:" + a.Owner + ":" + a.Spender)
+}
+
+// allowanceRequest is request to approve or increase an allowance, the owner
+// is the client sent the request
+type allowanceRequest struct {
+	TokenName string        `json:"token_name"`
+	Spender   datastore.Key `json:"spender"`
+	Value     state.Balance `json:"value"`
+}
+
+func (ar *allowanceRequest) decode(input []byte) error {
+	err := json.Unmarshal(input, ar)
+	return err
+}
+
+// transferFromRequest is request of a spender to transfer tokens from pool
+// of the owner to pool of the receiver
+type transferFromRequest struct {
+	TokenName string        `json:"token_name"`
+	Owner     datastore.Key `json:"owner"`
+	To        datastore.Key `json:"to"`
+	Value     state.Balance `json:"value"`
+}
+
+func (tfr *transferFromRequest) decode(input []byte) error {
+	err := json.Unmarshal(input, tfr)
+	return err
+}
+
+// getAllowanceNode returns allowance of the spender, or zero allowance if
+// it's not set
+func (zrc *ZRC20SmartContract) getAllowanceNode(tokenName string, owner, spender datastore.Key, balances c_state.StateContextI) (*allowance, error) {
+	a := &allowance{TokenName: tokenName, Owner: owner, Spender: spender}
+	allowanceBytes, err := balances.GetTrieNode(a.getKey(zrc.ID))
+	if err == util.ErrValueNotPresent || (err == nil && allowanceBytes == nil) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = a.Decode(allowanceBytes.Encode())
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (zrc *ZRC20SmartContract) saveAllowance(a *allowance, balances c_state.StateContextI) {
+	if a.Value == 0 {
+		balances.DeleteTrieNode(a.getKey(zrc.ID))
+		return
+	}
+	balances.InsertTrieNode(a.getKey(zrc.ID), a)
+}
+
+// approve sets allowance of a spender to transfer tokens of the client
+func (zrc *ZRC20SmartContract) approve(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI) (string, error) {
+	var request allowanceRequest
+	if err := request.decode(inputData); err != nil || request.Value < 0 || request.Spender == "" {
+		return common.NewError("approve failed", "request not formated correctly").Error(), nil
+	}
+	if _, err := zrc.getTokenNode(request.TokenName, balances); err != nil {
+		return common.NewError("approve failed", "token doesn't exist").Error(), nil
+	}
+	a, err := zrc.getAllowanceNode(request.TokenName, t.ClientID, request.Spender, balances)
+	if err != nil {
+		return common.NewError("approve failed", err.Error()).Error(), nil
+	}
+	a.Value = request.Value
+	zrc.saveAllowance(a, balances)
+	return string(a.Encode()), nil
+}
+
+// increaseAllowance adds tokens to allowance of a spender
+func (zrc *ZRC20SmartContract) increaseAllowance(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI) (string, error) {
+	var request allowanceRequest
+	if err := request.decode(inputData); err != nil || request.Value <= 0 || request.Spender == "" {
+		return common.NewError("increasing allowance failed", "request not formated correctly").Error(), nil
+	}
+	if _, err := zrc.getTokenNode(request.TokenName, balances); err != nil {
+		return common.NewError("increasing allowance failed", "token doesn't exist").Error(), nil
+	}
+	a, err := zrc.getAllowanceNode(request.TokenName, t.ClientID, request.Spender, balances)
+	if err != nil {
+		return common.NewError("increasing allowance failed", err.Error()).Error(), nil
+	}
+	a.Value += request.Value
+	zrc.saveAllowance(a, balances)
+	return string(a.Encode()), nil
+}
+
+// transferFrom transfers tokens of the owner to the receiver by a spender
+// within allowance of the spender; a pool of the receiver is created if
+// it doesn't exist
+func (zrc *ZRC20SmartContract) transferFrom(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI) (string, error) {
+	var request transferFromRequest
+	if err := request.decode(inputData); err != nil || request.Value <= 0 || request.Owner == "" || request.To == "" {
+		return common.NewError("transfer from failed", "request not formated correctly").Error(), nil
+	}
+	token, err := zrc.getTokenNode(request.TokenName, balances)
+	if err != nil {
+		return common.NewError("transfer from failed", "token doesn't exist").Error(), nil
+	}
+	a, err := zrc.getAllowanceNode(request.TokenName, request.Owner, t.ClientID, balances)
+	if err != nil {
+		return common.NewError("transfer from failed", err.Error()).Error(), nil
+	}
+	if request.Value > a.Value {
+		return common.NewError("transfer from failed", "value exceeds allowance").Error(), nil
+	}
+	ownerPool, err := zrc.getPool(request.TokenName, request.Owner, balances)
+	if err != nil {
+		return common.NewError("transfer from failed", "owner pool doesn't exist").Error(), nil
+	}
+	toPool, err := zrc.getPool(request.TokenName, request.To, balances)
+	if err != nil {
+		toPool = &zrc20Pool{tokenInfo: token.tokenInfo}
+		toPool.ID = request.To
+	}
+	_, resp, err := ownerPool.TransferTo(toPool, request.Value, t)
+	if err != nil {
+		return err.Error(), nil
+	}
+	a.Value -= request.Value
+	zrc.saveAllowance(a, balances)
+	balances.InsertTrieNode(ownerPool.getKey(zrc.ID), ownerPool)
+	balances.InsertTrieNode(toPool.getKey(zrc.ID), toPool)
+	return resp, nil
+}
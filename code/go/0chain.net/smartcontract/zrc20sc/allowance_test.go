@@ -0,0 +1,222 @@
+package zrc20sc
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tokenOwner = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712e1"
+	tokenUser  = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712e2"
+	spender    = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712e3"
+	receiver   = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712e4"
+)
+
+func newTestZRC20SC() *ZRC20SmartContract {
+	var zrc = &ZRC20SmartContract{}
+	zrc.setSC(smartcontractinterface.NewSC(ADDRESS), nil)
+	return zrc
+}
+
+func mustEncode(t *testing.T, val interface{}) []byte {
+	var b, err = json.Marshal(val)
+	require.NoError(t, err)
+	return b
+}
+
+func execute(t *testing.T, zrc *ZRC20SmartContract, balances *testBalances,
+	clientID, funcName string, value int64, input interface{}) string {
+
+	var tx = &transaction.Transaction{
+		ClientID:   clientID,
+		ToClientID: ADDRESS,
+		Value:      value,
+	}
+	balances.txn = tx
+	var resp, err = zrc.Execute(tx, funcName, mustEncode(t, input), balances)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestZRC20SmartContract_allowance(t *testing.T) {
+	var (
+		zrc      = newTestZRC20SC()
+		balances = newTestBalances()
+		resp     string
+	)
+	balances.setBalance(tokenUser, 100)
+
+	// create token and dig a pool of the user
+	var tn = &tokenNode{
+		tokenInfo: tokenInfo{
+			ExchangeRate: tokenRatio{ZCN: 1, Other: 10},
+			TokenName:    "test",
+		},
+		TotalSupply:   1000,
+		tokenMetadata: tokenMetadata{Symbol: "TST", Decimals: 2},
+	}
+	execute(t, zrc, balances, tokenOwner, "createToken", 0, tn)
+	var token, err = zrc.getTokenNode("test", balances)
+	require.NoError(t, err)
+	assert.Equal(t, tokenOwner, token.Owner)
+	assert.Equal(t, "TST", token.Symbol)
+
+	execute(t, zrc, balances, tokenUser, "digPool", 10,
+		&zrc20TransferRequest{FromToken: "test"})
+
+	// approve and increase
+	resp = execute(t, zrc, balances, tokenUser, "approve", 0,
+		&allowanceRequest{TokenName: "none", Spender: spender, Value: 10})
+	assert.Contains(t, resp, "token doesn't exist")
+
+	execute(t, zrc, balances, tokenUser, "approve", 0,
+		&allowanceRequest{TokenName: "test", Spender: spender, Value: 30})
+	execute(t, zrc, balances, tokenUser, "increaseAllowance", 0,
+		&allowanceRequest{TokenName: "test", Spender: spender, Value: 20})
+
+	var getAllowance = func() state.Balance {
+		var got, err = zrc.getAllowance(context.Background(), url.Values{
+			"token_name": {"test"},
+			"owner":      {tokenUser},
+			"spender":    {spender},
+		}, balances)
+		require.NoError(t, err)
+		return got.(*allowance).Value
+	}
+	assert.EqualValues(t, 50, getAllowance())
+
+	// transfer from
+	resp = execute(t, zrc, balances, spender, "transferFrom", 0,
+		&transferFromRequest{TokenName: "test", Owner: tokenUser,
+			To: receiver, Value: 51})
+	assert.Contains(t, resp, "value exceeds allowance")
+
+	resp = execute(t, zrc, balances, receiver, "transferFrom", 0,
+		&transferFromRequest{TokenName: "test", Owner: tokenUser,
+			To: receiver, Value: 10})
+	assert.Contains(t, resp, "value exceeds allowance")
+
+	execute(t, zrc, balances, spender, "transferFrom", 0,
+		&transferFromRequest{TokenName: "test", Owner: tokenUser,
+			To: receiver, Value: 40})
+	assert.EqualValues(t, 10, getAllowance())
+
+	var pool *zrc20Pool
+	pool, err = zrc.getPool("test", tokenUser, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 60, pool.Balance)
+	pool, err = zrc.getPool("test", receiver, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 40, pool.Balance)
+
+	// approve zero removes the allowance
+	execute(t, zrc, balances, tokenUser, "approve", 0,
+		&allowanceRequest{TokenName: "test", Spender: spender, Value: 0})
+	assert.Zero(t, getAllowance())
+}
+
+func TestZRC20SmartContract_mintBurn(t *testing.T) {
+	var (
+		zrc      = newTestZRC20SC()
+		balances = newTestBalances()
+		resp     string
+	)
+
+	var tn = &tokenNode{
+		tokenInfo: tokenInfo{
+			ExchangeRate: tokenRatio{ZCN: 1, Other: 1},
+			TokenName:    "test",
+		},
+		TotalSupply:   1000,
+		tokenMetadata: tokenMetadata{Decimals: 19},
+	}
+	resp = execute(t, zrc, balances, tokenOwner, "createToken", 0, tn)
+	assert.Contains(t, resp, "request is not filled out correctly")
+	tn.Decimals = 10
+	execute(t, zrc, balances, tokenOwner, "createToken", 0, tn)
+
+	resp = execute(t, zrc, balances, tokenUser, "mint", 0,
+		&supplyRequest{TokenName: "test", Value: 10})
+	assert.Contains(t, resp, "only owner of the token can mint it")
+
+	execute(t, zrc, balances, tokenOwner, "mint", 0,
+		&supplyRequest{TokenName: "test", Value: 500})
+	resp = execute(t, zrc, balances, tokenOwner, "burn", 0,
+		&supplyRequest{TokenName: "test", Value: 1501})
+	assert.Contains(t, resp, "value exceeds available tokens")
+	execute(t, zrc, balances, tokenOwner, "burn", 0,
+		&supplyRequest{TokenName: "test", Value: 300})
+
+	var token, err = zrc.getTokenNode("test", balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1200, token.TotalSupply)
+	assert.EqualValues(t, 1200, token.Available)
+}
+
+func TestZRC20SmartContract_poolOwner(t *testing.T) {
+	var (
+		zrc      = newTestZRC20SC()
+		balances = newTestBalances()
+		resp     string
+	)
+	balances.setBalance(tokenUser, 100)
+	balances.setBalance(receiver, 100)
+
+	var tn = &tokenNode{
+		tokenInfo: tokenInfo{
+			ExchangeRate: tokenRatio{ZCN: 1, Other: 10},
+			TokenName:    "test",
+		},
+		TotalSupply:   1000,
+		tokenMetadata: tokenMetadata{Symbol: "TST", Decimals: 2},
+	}
+	execute(t, zrc, balances, tokenOwner, "createToken", 0, tn)
+	execute(t, zrc, balances, tokenUser, "digPool", 10,
+		&zrc20TransferRequest{FromToken: "test"})
+	execute(t, zrc, balances, receiver, "digPool", 1,
+		&zrc20TransferRequest{FromToken: "test"})
+
+	var request = func(value state.Balance) *zrc20TransferRequest {
+		var tr = &zrc20TransferRequest{FromToken: "test", ToToken: "test"}
+		tr.FromPool, tr.ToPool, tr.ToClient = tokenUser, receiver, spender
+		tr.Value = value
+		return tr
+	}
+	var poolBalance = func(id string) state.Balance {
+		var pool, err = zrc.getPool("test", id, balances)
+		require.NoError(t, err)
+		return pool.Balance
+	}
+
+	// a pool of other client can't be used without an allowance
+	resp = execute(t, zrc, balances, spender, "transferTo", 0, request(50))
+	assert.Contains(t, resp, "only owner of the pool can transfer from it")
+	resp = execute(t, zrc, balances, spender, "drainPool", 50, request(0))
+	assert.Contains(t, resp, "only owner of the pool can drain it")
+	resp = execute(t, zrc, balances, spender, "emptyPool", 0, request(0))
+	assert.Contains(t, resp, "only owner of the pool can empty it")
+	assert.EqualValues(t, 100, poolBalance(tokenUser))
+	assert.EqualValues(t, 10, poolBalance(receiver))
+	assert.Zero(t, balances.balances[spender])
+
+	// the owner can
+	execute(t, zrc, balances, tokenUser, "transferTo", 0, request(50))
+	assert.EqualValues(t, 50, poolBalance(tokenUser))
+	assert.EqualValues(t, 60, poolBalance(receiver))
+	execute(t, zrc, balances, tokenUser, "drainPool", 20, request(0))
+	assert.EqualValues(t, 30, poolBalance(tokenUser))
+	assert.EqualValues(t, 2, balances.balances[spender])
+	execute(t, zrc, balances, tokenUser, "emptyPool", 0, request(0))
+	assert.EqualValues(t, 5, balances.balances[spender])
+	var _, err = zrc.getPool("test", tokenUser, balances)
+	assert.Error(t, err)
+}
@@ -25,3 +25,11 @@ func (zrc *ZRC20SmartContract) balanceOf(ctx context.Context, params url.Values,
 	}
 	return string(zrcPool.Encode()), nil
 }
+
+func (zrc *ZRC20SmartContract) getAllowance(ctx context.Context, params url.Values, balances c_state.StateContextI) (interface{}, error) {
+	a, err := zrc.getAllowanceNode(params.Get("token_name"), params.Get("owner"), params.Get("spender"), balances)
+	if err != nil {
+		return nil, common.NewErrInternal("can't get allowance", err.Error())
+	}
+	return a, nil
+}
@@ -10,10 +10,35 @@ import (
 	"0chain.net/core/util"
 )
 
+// max length of a token symbol and max decimals of a token
+const (
+	maxSymbolLength = 11
+	maxDecimals     = 18
+)
+
 type tokenNode struct {
 	tokenInfo
 	TotalSupply state.Balance `json:"total_supply"`
 	Available   state.Balance `json:"available"`
+	tokenMetadata
+}
+
+// tokenMetadata describes a token for dApps, the owner is creator of the
+// token and only the owner can mint or burn it
+type tokenMetadata struct {
+	Symbol   string        `json:"symbol,omitempty"`
+	Decimals int           `json:"decimals,omitempty"`
+	Owner    datastore.Key `json:"owner,omitempty"`
+}
+
+func (tm *tokenMetadata) validateMetadata() bool {
+	if len(tm.Symbol) > maxSymbolLength {
+		return false
+	}
+	if tm.Decimals < 0 || tm.Decimals > maxDecimals {
+		return false
+	}
+	return true
 }
 
 func (tn *tokenNode) Encode() []byte {
@@ -39,7 +64,7 @@ func (tn *tokenNode) getKey(globalKey string) datastore.Key {
 }
 
 func (tn *tokenNode) validate() bool {
-	if !tn.validateInfo() {
+	if !tn.validateInfo() || !tn.validateMetadata() {
 		return false
 	}
 	if tn.TotalSupply <= 0 {
@@ -103,3 +128,14 @@ func (zrc *zrc20TransferRequest) decode(input []byte) error {
 	err := json.Unmarshal(input, zrc)
 	return err
 }
+
+// supplyRequest is request to mint or burn tokens
+type supplyRequest struct {
+	TokenName string        `json:"token_name"`
+	Value     state.Balance `json:"value"`
+}
+
+func (sr *supplyRequest) decode(input []byte) error {
+	err := json.Unmarshal(input, sr)
+	return err
+}
@@ -49,6 +49,12 @@ func (zrc *ZRC20SmartContract) setSC(sc *smartcontractinterface.SmartContract, b
 	zrc.SmartContractExecutionStats["transferTo"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", zrc.ID, "transferTo"), nil)
 	zrc.SmartContractExecutionStats["drainPool"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", zrc.ID, "drainPool"), nil)
 	zrc.SmartContractExecutionStats["emptyPool"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", zrc.ID, "emptyPool"), nil)
+	zrc.SmartContractExecutionStats["mint"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", zrc.ID, "mint"), nil)
+	zrc.SmartContractExecutionStats["burn"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", zrc.ID, "burn"), nil)
+	zrc.SmartContractExecutionStats["approve"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", zrc.ID, "approve"), nil)
+	zrc.SmartContractExecutionStats["increaseAllowance"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", zrc.ID, "increaseAllowance"), nil)
+	zrc.SmartContractExecutionStats["transferFrom"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", zrc.ID, "transferFrom"), nil)
+	zrc.SmartContract.RestHandlers["/allowance"] = zrc.getAllowance
 }
 
 func (zrc *ZRC20SmartContract) GetName() string {
@@ -78,10 +84,54 @@ func (zrc *ZRC20SmartContract) createToken(t *transaction.Transaction, inputData
 		return common.NewError("bad request", "token already exists").Error(), nil
 	}
 	newRequest.Available = newRequest.TotalSupply
+	newRequest.Owner = t.ClientID
 	balances.InsertTrieNode(newRequest.getKey(zrc.ID), newRequest)
 	return string(newRequest.Encode()), nil
 }
 
+// mint increases total and available supply of a token, only owner of the
+// token can mint it
+func (zrc *ZRC20SmartContract) mint(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI) (string, error) {
+	var request supplyRequest
+	if err := request.decode(inputData); err != nil || request.Value <= 0 {
+		return common.NewError("minting tokens failed", "request not formated correctly").Error(), nil
+	}
+	token, err := zrc.getTokenNode(request.TokenName, balances)
+	if err != nil {
+		return common.NewError("minting tokens failed", "token doesn't exist").Error(), nil
+	}
+	if token.Owner != t.ClientID {
+		return common.NewError("minting tokens failed", "only owner of the token can mint it").Error(), nil
+	}
+	token.TotalSupply += request.Value
+	token.Available += request.Value
+	balances.InsertTrieNode(token.getKey(zrc.ID), token)
+	return string(token.Encode()), nil
+}
+
+// burn decreases total supply of a token by tokens not digged yet, only
+// owner of the token can burn it
+func (zrc *ZRC20SmartContract) burn(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI) (string, error) {
+	var request supplyRequest
+	if err := request.decode(inputData); err != nil || request.Value <= 0 {
+		return common.NewError("burning tokens failed", "request not formated correctly").Error(), nil
+	}
+	token, err := zrc.getTokenNode(request.TokenName, balances)
+	if err != nil {
+		return common.NewError("burning tokens failed", "token doesn't exist").Error(), nil
+	}
+	if token.Owner != t.ClientID {
+		return common.NewError("burning tokens failed", "only owner of the token can burn it").Error(), nil
+	}
+	if request.Value > token.Available {
+		return common.NewError("burning tokens failed", "value exceeds available tokens").Error(), nil
+	}
+	token.TotalSupply -= request.Value
+	token.Available -= request.Value
+	balances.InsertTrieNode(token.getKey(zrc.ID), token)
+	return string(token.Encode()), nil
+}
+
 func (zrc *ZRC20SmartContract) digPool(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI) (string, error) {
 	var newRequest zrc20TransferRequest
 	zrcPool := &zrc20Pool{}
@@ -138,12 +188,17 @@ func (zrc *ZRC20SmartContract) fillPool(t *transaction.Transaction, inputData []
 	return resp, nil
 }
 
+// transferTo transfers tokens of a pool of the client, other clients'
+// tokens are transferred by transferFrom within their allowances
 func (zrc *ZRC20SmartContract) transferTo(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI) (string, error) {
 	var newRequest zrc20TransferRequest
 	err := newRequest.decode(inputData)
 	if err != nil {
 		return err.Error(), nil
 	}
+	if newRequest.FromPool != t.ClientID {
+		return common.NewError("pool-to-pool transfer failed", "only owner of the pool can transfer from it").Error(), nil
+	}
 	zrcPool, err := zrc.getPool(newRequest.FromToken, newRequest.FromPool, balances)
 	if err != nil {
 		return err.Error(), nil
@@ -156,7 +211,7 @@ func (zrc *ZRC20SmartContract) transferTo(t *transaction.Transaction, inputData
 	if err != nil {
 		return err.Error(), nil
 	}
-	if transfer.Amount > 0 {
+	if transfer != nil && transfer.Amount > 0 {
 		balances.AddTransfer(transfer)
 	}
 	balances.InsertTrieNode(zrcPool.getKey(zrc.ID), zrcPool)
@@ -164,12 +219,16 @@ func (zrc *ZRC20SmartContract) transferTo(t *transaction.Transaction, inputData
 	return resp, nil
 }
 
+// drainPool exchanges tokens of a pool of the client back to ZCN
 func (zrc *ZRC20SmartContract) drainPool(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI) (string, error) {
 	var newRequest zrc20TransferRequest
 	err := newRequest.decode(inputData)
 	if err != nil {
 		return common.NewError("bad request", "token cannot be created, request not formated correctly").Error(), nil
 	}
+	if newRequest.FromPool != t.ClientID {
+		return common.NewError("draining pool failed", "only owner of the pool can drain it").Error(), nil
+	}
 	token, err := zrc.getTokenNode(newRequest.FromToken, balances)
 	if err != nil {
 		return err.Error(), nil
@@ -190,12 +249,17 @@ func (zrc *ZRC20SmartContract) drainPool(t *transaction.Transaction, inputData [
 	return resp, nil
 }
 
+// emptyPool exchanges all tokens of a pool of the client back to ZCN and
+// removes the pool
 func (zrc *ZRC20SmartContract) emptyPool(t *transaction.Transaction, inputData []byte, balances c_state.StateContextI) (string, error) {
 	var newRequest zrc20TransferRequest
 	err := newRequest.decode(inputData)
 	if err != nil {
 		return common.NewError("bad request", "token cannot be created, request not formated correctly").Error(), nil
 	}
+	if newRequest.FromPool != t.ClientID {
+		return common.NewError("emptying pool failed", "only owner of the pool can empty it").Error(), nil
+	}
 	token, err := zrc.getTokenNode(newRequest.FromToken, balances)
 	if err != nil {
 		return err.Error(), nil
@@ -265,6 +329,16 @@ func (zrc *ZRC20SmartContract) Execute(t *transaction.Transaction, funcName stri
 		return zrc.drainPool(t, inputData, balances)
 	case "emptyPool":
 		return zrc.emptyPool(t, inputData, balances)
+	case "mint":
+		return zrc.mint(t, inputData, balances)
+	case "burn":
+		return zrc.burn(t, inputData, balances)
+	case "approve":
+		return zrc.approve(t, inputData, balances)
+	case "increaseAllowance":
+		return zrc.increaseAllowance(t, inputData, balances)
+	case "transferFrom":
+		return zrc.transferFrom(t, inputData, balances)
 	default:
 		return common.NewError("failed execution", "no function with that name").Error(), nil
 	}